@@ -1,6 +1,10 @@
 package main
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,12 +16,27 @@ import (
 
 func main() {
 	infrastructures.LoadConfig()
+	if infrastructures.Config.APIKeyPepper == "" {
+		logrus.Fatal("API_KEY_PEPPER is required")
+	}
+	if infrastructures.Config.QRSigningSecret == "" {
+		logrus.Fatal("QR_SIGNING_SECRET is required")
+	}
 
 	app, err := injector.InitializeApplication()
 	if err != nil {
 		logrus.Fatalf("Failed to initialize application: %v", err)
 	}
 
+	// Hash merchant API keys stored in plaintext before key hashing was introduced
+	hashed, err := app.MerchantAPIKeyService.HashLegacyAPIKeys(context.Background())
+	if err != nil {
+		logrus.Fatalf("Failed to hash legacy API keys: %v", err)
+	}
+	if hashed > 0 {
+		logrus.Infof("Hashed %d legacy API keys", hashed)
+	}
+
 	// Fiber configuration
 	config := fiber.Config{
 		ReadTimeout:  time.Second * 60,
@@ -30,13 +49,39 @@ func main() {
 	// Add CORS middleware
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:  "*",
-		AllowHeaders:  "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, Idempotency-Key",
 		AllowMethods:  "GET, POST, PUT, DELETE, OPTIONS",
-		ExposeHeaders: "Content-Length",
+		ExposeHeaders: "Content-Length, Idempotent-Replayed",
 		MaxAge:        300,
 	}))
 
 	app.RegisterRoutes(router)
+	if err := app.APIKeyMiddleware.CheckRouteScopes(router); err != nil {
+		logrus.Fatal(err)
+	}
+
+	// Background jobs; disable with SCHEDULER_ENABLED=false on replicas that should only serve HTTP
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if infrastructures.Config.SchedulerEnabled {
+		app.SchedulerService.Start(ctx)
+	}
+	app.APIKeyUsageRecorder.Start()
+
+	go func() {
+		<-ctx.Done()
+		logrus.Info("Shutting down")
+		if err := router.ShutdownWithTimeout(30 * time.Second); err != nil {
+			logrus.Errorf("Failed to shut down server: %v", err)
+		}
+	}()
+
+	if err := router.Listen(":8080"); err != nil {
+		logrus.Fatal(err)
+	}
 
-	logrus.Fatal(router.Listen(":8080"))
+	app.SchedulerService.Stop()
+	// Write the usage of the last requests served
+	app.APIKeyUsageRecorder.Stop()
 }
@@ -19,8 +19,19 @@ type Application struct {
 	TransactionHandler       *deliveries.TransactionHandler
 	VoucherHandler           *deliveries.VoucherHandler
 	VoucherRedemptionHandler *deliveries.VoucherRedemptionHandler
+	CheckoutHandler          *deliveries.CheckoutHandler
+	MerchantWebhookHandler   *deliveries.MerchantWebhookHandler
+	MerchantAPIKeyHandler    *deliveries.MerchantAPIKeyHandler
+	TransactionLimitHandler  *deliveries.TransactionLimitHandler
+	KYCHandler               *deliveries.KYCHandler
+	MerchantHandler          *deliveries.MerchantHandler
+	AdminHandler             *deliveries.AdminHandler
 	RateLimitMiddleware      *middlewares.RateLimitMiddleware
 	APIKeyMiddleware         *middlewares.APIKeyMiddleware
+	SchedulerHandler         *deliveries.SchedulerHandler
+	SchedulerService         *services.SchedulerService
+	MerchantAPIKeyService    *services.MerchantAPIKeyService
+	APIKeyUsageRecorder      *services.APIKeyUsageRecorder
 }
 
 // RegisterRoutes registers all application routes using a Fiber router
@@ -42,6 +53,14 @@ func (app *Application) RegisterRoutes(router fiber.Router) {
 	app.TransactionHandler.RegisterRoutes(router)
 	app.VoucherHandler.RegisterRoutes(router)
 	app.VoucherRedemptionHandler.RegisterRoutes(router)
+	app.CheckoutHandler.RegisterRoutes(router)
+	app.MerchantWebhookHandler.RegisterRoutes(router)
+	app.MerchantAPIKeyHandler.RegisterRoutes(router)
+	app.TransactionLimitHandler.RegisterRoutes(router)
+	app.KYCHandler.RegisterRoutes(router)
+	app.MerchantHandler.RegisterRoutes(router)
+	app.AdminHandler.RegisterRoutes(router)
+	app.SchedulerHandler.RegisterRoutes(router)
 }
 
 // Infrastructure providers
@@ -59,14 +78,29 @@ var infrastructureSet = wire.NewSet(
 var serviceSet = wire.NewSet(
 	services.NewConnectService,
 	services.NewAccountService,
+	services.NewLedgerService,
 	services.NewPaymentMethodService,
 	services.NewFlipService,
+	services.NewPaymentProviderRegistry,
 	services.NewTransactionService,
 	services.NewVoucherService,
 	services.NewVoucherRedemptionService,
 	services.NewAuditService,
 	services.NewMerchantAPIKeyService,
 	services.NewPaymentService,
+	services.NewInboundWebhookService,
+	services.NewSchedulerService,
+	services.NewIdempotencyService,
+	services.NewRefundService,
+	services.NewCheckoutService,
+	services.NewMerchantWebhookService,
+	services.NewAPIKeyUsageRecorder,
+	services.NewTransactionLimitService,
+	services.NewKYCService,
+	services.NewMerchantService,
+	services.NewAdminService,
+	services.NewAccountClosureService,
+	services.NewTransferRecipientService,
 )
 
 // Middleware providers
@@ -74,6 +108,7 @@ var middlewareSet = wire.NewSet(
 	middlewares.NewAuthMiddleware,
 	middlewares.NewAPIKeyMiddleware,
 	middlewares.NewRateLimitMiddleware,
+	middlewares.NewIdempotencyMiddleware,
 )
 
 // Handler providers
@@ -83,6 +118,14 @@ var handlerSet = wire.NewSet(
 	deliveries.NewTransactionHandler,
 	deliveries.NewVoucherHandler,
 	deliveries.NewVoucherRedemptionHandler,
+	deliveries.NewCheckoutHandler,
+	deliveries.NewMerchantWebhookHandler,
+	deliveries.NewMerchantAPIKeyHandler,
+	deliveries.NewTransactionLimitHandler,
+	deliveries.NewKYCHandler,
+	deliveries.NewMerchantHandler,
+	deliveries.NewAdminHandler,
+	deliveries.NewSchedulerHandler,
 	wire.Struct(new(Application), "*"), // This tells Wire to build the Application struct
 )
 
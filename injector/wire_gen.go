@@ -24,33 +24,68 @@ func InitializeApplication() (*Application, error) {
 	validator := infrastructures.NewValidator()
 	connectService := services.NewConnectService()
 	accountService := services.NewAccountService(db, validator, connectService)
+	ledgerService := services.NewLedgerService(db)
 	authMiddleware := middlewares.NewAuthMiddleware(connectService, accountService)
-	accountHandler := deliveries.NewAccountHandler(accountService, authMiddleware)
+	idempotencyService := services.NewIdempotencyService(db)
+	idempotencyMiddleware := middlewares.NewIdempotencyMiddleware(idempotencyService)
 	flipClient := infrastructures.NewFlipClient()
 	flipService := services.NewFlipService(flipClient)
 	paymentMethodService := services.NewPaymentMethodService(db, validator)
 	paymentService := services.NewPaymentService(db, validator)
 	auditService := services.NewAuditService(db)
-	transactionService := services.NewTransactionService(db, validator, accountService, flipService, connectService, paymentMethodService, paymentService, auditService)
-	transactionHandler := deliveries.NewTransactionHandler(transactionService, paymentService, paymentMethodService, authMiddleware)
+	paymentProviderRegistry := services.NewPaymentProviderRegistry(flipService)
+	merchantWebhookService := services.NewMerchantWebhookService(db, validator)
+	transactionLimitService := services.NewTransactionLimitService(db, validator, auditService)
+	transactionService := services.NewTransactionService(db, validator, accountService, flipService, connectService, paymentMethodService, paymentService, auditService, ledgerService, paymentProviderRegistry, merchantWebhookService, transactionLimitService)
+	inboundWebhookService := services.NewInboundWebhookService(db, paymentProviderRegistry, paymentService, transactionService)
+	refundService := services.NewRefundService(db, validator, transactionService, ledgerService, auditService, merchantWebhookService)
+	accountClosureService := services.NewAccountClosureService(db, validator, transactionService, ledgerService, auditService)
+	accountHandler := deliveries.NewAccountHandler(accountService, accountClosureService, ledgerService, authMiddleware, idempotencyMiddleware)
+	transferRecipientService := services.NewTransferRecipientService(db, validator, connectService, transactionService)
+	transactionHandler := deliveries.NewTransactionHandler(transactionService, paymentService, paymentMethodService, inboundWebhookService, refundService, transferRecipientService, authMiddleware, idempotencyMiddleware)
 	voucherService := services.NewVoucherService(db, validator)
-	voucherHandler := deliveries.NewVoucherHandler(voucherService, authMiddleware)
-	voucherRedemptionService := services.NewVoucherRedemptionService(db, validator, voucherService, accountService, transactionService)
-	voucherRedemptionHandler := deliveries.NewVoucherRedemptionHandler(voucherRedemptionService, authMiddleware)
+	voucherHandler := deliveries.NewVoucherHandler(voucherService, authMiddleware, idempotencyMiddleware)
+	voucherRedemptionService := services.NewVoucherRedemptionService(db, validator, voucherService, accountService, transactionService, ledgerService)
+	voucherRedemptionHandler := deliveries.NewVoucherRedemptionHandler(voucherRedemptionService, authMiddleware, idempotencyMiddleware)
 	client := infrastructures.NewRedisClient()
 	string2 := _wireStringValue
 	redisRateLimiter := middlewares.NewRedisRateLimiter(client, string2)
 	rateLimitMiddleware := middlewares.NewRateLimitMiddleware(redisRateLimiter)
-	merchantAPIKeyService := services.NewMerchantAPIKeyService(db)
-	apiKeyMiddleware := middlewares.NewAPIKeyMiddleware(merchantAPIKeyService, redisRateLimiter)
+	merchantAPIKeyService := services.NewMerchantAPIKeyService(db, validator)
+	apiKeyUsageRecorder := services.NewAPIKeyUsageRecorder(merchantAPIKeyService)
+	apiKeyMiddleware := middlewares.NewAPIKeyMiddleware(merchantAPIKeyService, apiKeyUsageRecorder, redisRateLimiter, client, string2)
+	merchantService := services.NewMerchantService(db, validator, auditService)
+	checkoutService := services.NewCheckoutService(db, validator, transactionService, ledgerService, auditService, merchantWebhookService, merchantService)
+	checkoutHandler := deliveries.NewCheckoutHandler(checkoutService, authMiddleware, apiKeyMiddleware, idempotencyMiddleware)
+	merchantWebhookHandler := deliveries.NewMerchantWebhookHandler(merchantWebhookService, apiKeyMiddleware, idempotencyMiddleware)
+	merchantAPIKeyHandler := deliveries.NewMerchantAPIKeyHandler(merchantAPIKeyService, authMiddleware, idempotencyMiddleware)
+	transactionLimitHandler := deliveries.NewTransactionLimitHandler(transactionLimitService, authMiddleware, idempotencyMiddleware)
+	kycService := services.NewKYCService(db, validator, auditService)
+	kycHandler := deliveries.NewKYCHandler(kycService, authMiddleware, idempotencyMiddleware)
+	merchantHandler := deliveries.NewMerchantHandler(merchantService, authMiddleware, idempotencyMiddleware)
+	adminService := services.NewAdminService(db, validator, connectService, transactionService, voucherRedemptionService, ledgerService, auditService)
+	adminHandler := deliveries.NewAdminHandler(adminService, authMiddleware, idempotencyMiddleware)
+	schedulerService := services.NewSchedulerService(db, client, string2, transactionService, voucherService, idempotencyService, checkoutService, merchantWebhookService, accountClosureService)
+	schedulerHandler := deliveries.NewSchedulerHandler(schedulerService, authMiddleware)
 	application := &Application{
 		HealthHandler:            healthHandler,
 		AccountHandler:           accountHandler,
 		TransactionHandler:       transactionHandler,
 		VoucherHandler:           voucherHandler,
 		VoucherRedemptionHandler: voucherRedemptionHandler,
+		CheckoutHandler:          checkoutHandler,
+		MerchantWebhookHandler:   merchantWebhookHandler,
+		MerchantAPIKeyHandler:    merchantAPIKeyHandler,
+		TransactionLimitHandler:  transactionLimitHandler,
+		KYCHandler:               kycHandler,
+		MerchantHandler:          merchantHandler,
+		AdminHandler:             adminHandler,
 		RateLimitMiddleware:      rateLimitMiddleware,
 		APIKeyMiddleware:         apiKeyMiddleware,
+		SchedulerHandler:         schedulerHandler,
+		SchedulerService:         schedulerService,
+		MerchantAPIKeyService:    merchantAPIKeyService,
+		APIKeyUsageRecorder:      apiKeyUsageRecorder,
 	}
 	return application, nil
 }
@@ -68,8 +103,19 @@ type Application struct {
 	TransactionHandler       *deliveries.TransactionHandler
 	VoucherHandler           *deliveries.VoucherHandler
 	VoucherRedemptionHandler *deliveries.VoucherRedemptionHandler
+	CheckoutHandler          *deliveries.CheckoutHandler
+	MerchantWebhookHandler   *deliveries.MerchantWebhookHandler
+	MerchantAPIKeyHandler    *deliveries.MerchantAPIKeyHandler
+	TransactionLimitHandler  *deliveries.TransactionLimitHandler
+	KYCHandler               *deliveries.KYCHandler
+	MerchantHandler          *deliveries.MerchantHandler
+	AdminHandler             *deliveries.AdminHandler
 	RateLimitMiddleware      *middlewares.RateLimitMiddleware
 	APIKeyMiddleware         *middlewares.APIKeyMiddleware
+	SchedulerHandler         *deliveries.SchedulerHandler
+	SchedulerService         *services.SchedulerService
+	MerchantAPIKeyService    *services.MerchantAPIKeyService
+	APIKeyUsageRecorder      *services.APIKeyUsageRecorder
 }
 
 // RegisterRoutes registers all application routes using a Fiber router
@@ -88,16 +134,24 @@ func (app *Application) RegisterRoutes(router fiber.Router) {
 	app.TransactionHandler.RegisterRoutes(router)
 	app.VoucherHandler.RegisterRoutes(router)
 	app.VoucherRedemptionHandler.RegisterRoutes(router)
+	app.CheckoutHandler.RegisterRoutes(router)
+	app.MerchantWebhookHandler.RegisterRoutes(router)
+	app.MerchantAPIKeyHandler.RegisterRoutes(router)
+	app.TransactionLimitHandler.RegisterRoutes(router)
+	app.KYCHandler.RegisterRoutes(router)
+	app.MerchantHandler.RegisterRoutes(router)
+	app.AdminHandler.RegisterRoutes(router)
+	app.SchedulerHandler.RegisterRoutes(router)
 }
 
 // Infrastructure providers
 var infrastructureSet = wire.NewSet(infrastructures.NewDatabase, infrastructures.NewRedisClient, infrastructures.NewValidator, infrastructures.NewFlipClient, wire.Value("gsalt"), wire.Bind(new(middlewares.RateLimiter), new(*middlewares.RedisRateLimiter)), middlewares.NewRedisRateLimiter)
 
 // Service providers
-var serviceSet = wire.NewSet(services.NewConnectService, services.NewAccountService, services.NewPaymentMethodService, services.NewFlipService, services.NewTransactionService, services.NewVoucherService, services.NewVoucherRedemptionService, services.NewAuditService, services.NewMerchantAPIKeyService, services.NewPaymentService)
+var serviceSet = wire.NewSet(services.NewConnectService, services.NewAccountService, services.NewLedgerService, services.NewPaymentMethodService, services.NewFlipService, services.NewPaymentProviderRegistry, services.NewTransactionService, services.NewVoucherService, services.NewVoucherRedemptionService, services.NewAuditService, services.NewMerchantAPIKeyService, services.NewPaymentService, services.NewInboundWebhookService, services.NewSchedulerService, services.NewIdempotencyService, services.NewRefundService, services.NewCheckoutService, services.NewMerchantWebhookService, services.NewAPIKeyUsageRecorder, services.NewTransactionLimitService, services.NewKYCService, services.NewMerchantService, services.NewAdminService, services.NewAccountClosureService, services.NewTransferRecipientService)
 
 // Middleware providers
-var middlewareSet = wire.NewSet(middlewares.NewAuthMiddleware, middlewares.NewAPIKeyMiddleware, middlewares.NewRateLimitMiddleware)
+var middlewareSet = wire.NewSet(middlewares.NewAuthMiddleware, middlewares.NewAPIKeyMiddleware, middlewares.NewRateLimitMiddleware, middlewares.NewIdempotencyMiddleware)
 
 // Handler providers
-var handlerSet = wire.NewSet(deliveries.NewHealthHandler, deliveries.NewAccountHandler, deliveries.NewTransactionHandler, deliveries.NewVoucherHandler, deliveries.NewVoucherRedemptionHandler, wire.Struct(new(Application), "*"))
+var handlerSet = wire.NewSet(deliveries.NewHealthHandler, deliveries.NewAccountHandler, deliveries.NewTransactionHandler, deliveries.NewVoucherHandler, deliveries.NewVoucherRedemptionHandler, deliveries.NewCheckoutHandler, deliveries.NewMerchantWebhookHandler, deliveries.NewMerchantAPIKeyHandler, deliveries.NewTransactionLimitHandler, deliveries.NewKYCHandler, deliveries.NewMerchantHandler, deliveries.NewAdminHandler, deliveries.NewSchedulerHandler, wire.Struct(new(Application), "*"))
@@ -2,6 +2,7 @@ package deliveries
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
 	"github.com/safatanc/gsalt-core/internal/app/middlewares"
 	"github.com/safatanc/gsalt-core/internal/app/models"
 	"github.com/safatanc/gsalt-core/internal/app/pkg"
@@ -9,20 +10,29 @@ import (
 )
 
 type AccountHandler struct {
-	accountService *services.AccountService
-	authMiddleware *middlewares.AuthMiddleware
+	accountService        *services.AccountService
+	closureService        *services.AccountClosureService
+	ledgerService         *services.LedgerService
+	authMiddleware        *middlewares.AuthMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
 }
 
-func NewAccountHandler(accountService *services.AccountService, authMiddleware *middlewares.AuthMiddleware) *AccountHandler {
-	return &AccountHandler{accountService: accountService, authMiddleware: authMiddleware}
+func NewAccountHandler(accountService *services.AccountService, closureService *services.AccountClosureService, ledgerService *services.LedgerService, authMiddleware *middlewares.AuthMiddleware, idempotencyMiddleware *middlewares.IdempotencyMiddleware) *AccountHandler {
+	return &AccountHandler{accountService: accountService, closureService: closureService, ledgerService: ledgerService, authMiddleware: authMiddleware, idempotencyMiddleware: idempotencyMiddleware}
 }
 
 func (h *AccountHandler) RegisterRoutes(router fiber.Router) {
 	accountGroup := router.Group("/accounts")
 
-	accountGroup.Post("/", h.authMiddleware.AuthConnect, h.CreateAccount)
+	accountGroup.Post("/", h.authMiddleware.AuthConnect, h.idempotencyMiddleware.Idempotent, h.CreateAccount)
 	accountGroup.Get("/me", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.GetMe)
-	accountGroup.Delete("/me", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.DeleteMe)
+	accountGroup.Delete("/me", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent, h.DeleteMe)
+	// A closing account no longer passes AuthAccount, so these resolve it from the Connect user
+	accountGroup.Get("/me/closure", h.authMiddleware.AuthConnect, h.GetMyClosure)
+	accountGroup.Post("/me/closure/cancel", h.authMiddleware.AuthConnect, h.idempotencyMiddleware.Idempotent, h.CancelMyClosure)
+	accountGroup.Get("/me/ledger", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.GetMyLedger)
+	accountGroup.Get("/me/holds", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.GetMyHolds)
+	accountGroup.Get("/me/ledger/reconcile", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.ReconcileMyLedger)
 	accountGroup.Get("/:id", h.GetAccountByID)
 }
 
@@ -53,13 +63,89 @@ func (h *AccountHandler) GetAccountByID(c *fiber.Ctx) error {
 	return pkg.SuccessResponse(c, account)
 }
 
+// DeleteMe requests closure of the account. The body is optional; a remaining balance must be
+// withdrawn first or donated with balance_disposition DONATE.
 func (h *AccountHandler) DeleteMe(c *fiber.Ctx) error {
 	account := c.Locals("account").(*models.Account)
 
-	err := h.accountService.DeleteAccount(account.ConnectID.String())
+	var req models.AccountClosureRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+		}
+	}
+
+	closure, err := h.closureService.RequestClosure(account.ConnectID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, closure)
+}
+
+// GetMyClosure returns the closure state of the caller's account
+func (h *AccountHandler) GetMyClosure(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	closure, err := h.closureService.GetClosure(connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, closure)
+}
+
+// CancelMyClosure reactivates the caller's account during the cooling-off period
+func (h *AccountHandler) CancelMyClosure(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	account, err := h.closureService.CancelClosure(connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, account)
+}
+
+func (h *AccountHandler) GetMyLedger(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	postings, err := h.ledgerService.GetWalletPostings(account.ConnectID.String(), pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, postings)
+}
+
+func (h *AccountHandler) GetMyHolds(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	holds, err := h.ledgerService.GetHolds(account.ConnectID.String(), pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, holds)
+}
+
+func (h *AccountHandler) ReconcileMyLedger(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	reconciliation, err := h.ledgerService.ReconcileWallet(account.ConnectID.String())
 	if err != nil {
 		return pkg.ErrorResponse(c, err)
 	}
 
-	return pkg.SuccessResponse[any](c, nil)
+	return pkg.SuccessResponse(c, reconciliation)
 }
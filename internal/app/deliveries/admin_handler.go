@@ -0,0 +1,214 @@
+package deliveries
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/middlewares"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/app/services"
+)
+
+type AdminHandler struct {
+	adminService          *services.AdminService
+	authMiddleware        *middlewares.AuthMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
+}
+
+func NewAdminHandler(
+	adminService *services.AdminService,
+	authMiddleware *middlewares.AuthMiddleware,
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware,
+) *AdminHandler {
+	return &AdminHandler{
+		adminService:          adminService,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(router fiber.Router) {
+	accountGroup := router.Group("/admin/accounts", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAdmin, h.idempotencyMiddleware.Idempotent)
+	accountGroup.Get("/", h.GetAccounts)
+	accountGroup.Get("/search", h.SearchAccount)
+	accountGroup.Get("/:id", h.GetAccount)
+	accountGroup.Post("/:id/suspend", h.SuspendAccount)
+	accountGroup.Post("/:id/block", h.BlockAccount)
+	accountGroup.Post("/:id/reactivate", h.ReactivateAccount)
+	accountGroup.Post("/:id/adjustments", h.AdjustBalance)
+	accountGroup.Get("/:id/transactions", h.GetAccountTransactions)
+	accountGroup.Get("/:id/redemptions", h.GetAccountRedemptions)
+	accountGroup.Get("/:id/audit-logs", h.GetAccountAuditLogs)
+}
+
+// GetAccounts lists accounts, optionally filtered by status, account type and KYC status
+func (h *AdminHandler) GetAccounts(c *fiber.Ctx) error {
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	var filter models.AdminAccountFilter
+	if err := c.QueryParser(&filter); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid query parameters"))
+	}
+
+	accounts, err := h.adminService.GetAccounts(&filter, pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, accounts)
+}
+
+// SearchAccount finds an account by Connect ID, email or username
+func (h *AdminHandler) SearchAccount(c *fiber.Ctx) error {
+	detail, err := h.adminService.SearchAccount(c.Query("q"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, detail)
+}
+
+// GetAccount returns an account with its Connect user
+func (h *AdminHandler) GetAccount(c *fiber.Ctx) error {
+	id, err := h.parseAccountID(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	detail, err := h.adminService.GetAccountDetail(id)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, detail)
+}
+
+// SuspendAccount suspends an active account
+func (h *AdminHandler) SuspendAccount(c *fiber.Ctx) error {
+	return h.changeStatus(c, h.adminService.SuspendAccount)
+}
+
+// BlockAccount blocks an active or suspended account
+func (h *AdminHandler) BlockAccount(c *fiber.Ctx) error {
+	return h.changeStatus(c, h.adminService.BlockAccount)
+}
+
+// ReactivateAccount reactivates a suspended or blocked account
+func (h *AdminHandler) ReactivateAccount(c *fiber.Ctx) error {
+	return h.changeStatus(c, h.adminService.ReactivateAccount)
+}
+
+// AdjustBalance credits or debits an account with an ADJUSTMENT transaction
+func (h *AdminHandler) AdjustBalance(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	id, err := h.parseAccountID(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	var req models.BalanceAdjustmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	transaction, err := h.adminService.AdjustBalance(id, &req, connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, transaction)
+}
+
+// GetAccountTransactions lists an account's transactions
+func (h *AdminHandler) GetAccountTransactions(c *fiber.Ctx) error {
+	id, err := h.parseAccountID(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	transactions, err := h.adminService.GetAccountTransactions(id, pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, transactions)
+}
+
+// GetAccountRedemptions lists an account's voucher redemptions
+func (h *AdminHandler) GetAccountRedemptions(c *fiber.Ctx) error {
+	id, err := h.parseAccountID(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	redemptions, err := h.adminService.GetAccountRedemptions(id, pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, redemptions)
+}
+
+// GetAccountAuditLogs lists an account's audit trail
+func (h *AdminHandler) GetAccountAuditLogs(c *fiber.Ctx) error {
+	id, err := h.parseAccountID(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	logs, err := h.adminService.GetAccountAuditLogs(id, pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, logs)
+}
+
+func (h *AdminHandler) changeStatus(c *fiber.Ctx, change func(accountID uuid.UUID, req *models.AccountStatusChangeRequest, adminID uuid.UUID) (*models.Account, error)) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	id, err := h.parseAccountID(c)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	var req models.AccountStatusChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	account, err := change(id, &req, connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, account)
+}
+
+func (h *AdminHandler) parseAccountID(c *fiber.Ctx) (uuid.UUID, error) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, errors.NewBadRequestError("Invalid account ID")
+	}
+	return id, nil
+}
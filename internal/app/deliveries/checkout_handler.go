@@ -0,0 +1,177 @@
+package deliveries
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/middlewares"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/app/services"
+)
+
+type CheckoutHandler struct {
+	checkoutService       *services.CheckoutService
+	authMiddleware        *middlewares.AuthMiddleware
+	apiKeyMiddleware      *middlewares.APIKeyMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
+}
+
+func NewCheckoutHandler(
+	checkoutService *services.CheckoutService,
+	authMiddleware *middlewares.AuthMiddleware,
+	apiKeyMiddleware *middlewares.APIKeyMiddleware,
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware,
+) *CheckoutHandler {
+	return &CheckoutHandler{
+		checkoutService:       checkoutService,
+		authMiddleware:        authMiddleware,
+		apiKeyMiddleware:      apiKeyMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+func (h *CheckoutHandler) RegisterRoutes(router fiber.Router) {
+	// Merchant routes (X-API-Key required)
+	merchantGroup := router.Group("/merchants/checkout-sessions", h.apiKeyMiddleware.RequireAPIKey, h.idempotencyMiddleware.Idempotent)
+	merchantGroup.Post("/", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeWrite, models.APIKeyScopePayment), h.CreateSession)
+	merchantGroup.Get("/", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeRead, models.APIKeyScopePayment), h.GetMerchantSessions)
+	merchantGroup.Get("/:id", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeRead, models.APIKeyScopePayment), h.GetMerchantSession)
+	merchantGroup.Post("/:id/cancel", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeWrite, models.APIKeyScopePayment), h.CancelSession)
+	merchantGroup.Post("/:id/capture", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeWrite, models.APIKeyScopePayment), h.CaptureSession)
+	merchantGroup.Post("/:id/void", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeWrite, models.APIKeyScopePayment), h.VoidSession)
+
+	// Payer routes (Connect token required)
+	payerGroup := router.Group("/checkout-sessions", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent)
+	payerGroup.Get("/:id", h.GetPayerSession)
+	payerGroup.Post("/:id/approve", h.ApproveSession)
+	payerGroup.Post("/:id/decline", h.DeclineSession)
+}
+
+// CreateSession opens a checkout session for the merchant of the API key
+func (h *CheckoutHandler) CreateSession(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	var req models.CheckoutSessionCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	session, err := h.checkoutService.CreateSession(apiKey, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, session)
+}
+
+// GetMerchantSessions lists the merchant's checkout sessions in the API key's environment
+func (h *CheckoutHandler) GetMerchantSessions(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	var status *models.CheckoutSessionStatus
+	if s := c.Query("status"); s != "" {
+		sessionStatus := models.CheckoutSessionStatus(s)
+		status = &sessionStatus
+	}
+
+	sessions, err := h.checkoutService.GetMerchantSessions(apiKey, status, pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, sessions)
+}
+
+// GetMerchantSession returns one of the merchant's checkout sessions
+func (h *CheckoutHandler) GetMerchantSession(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	session, err := h.checkoutService.GetMerchantSession(apiKey, c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, session)
+}
+
+// CancelSession closes an open checkout session
+func (h *CheckoutHandler) CancelSession(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	session, err := h.checkoutService.CancelSession(apiKey, c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, session)
+}
+
+// CaptureSession collects all or part of an authorized checkout session
+func (h *CheckoutHandler) CaptureSession(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	var req models.CheckoutCaptureRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+		}
+	}
+
+	session, err := h.checkoutService.CaptureSession(apiKey, c.Params("id"), &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, session)
+}
+
+// VoidSession releases the hold of an authorized checkout session
+func (h *CheckoutHandler) VoidSession(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	session, err := h.checkoutService.VoidSession(apiKey, c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, session)
+}
+
+// GetPayerSession shows a checkout session to the payer before approval
+func (h *CheckoutHandler) GetPayerSession(c *fiber.Ctx) error {
+	session, err := h.checkoutService.GetPayerSession(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, session)
+}
+
+// ApproveSession pays a checkout session from the payer's balance
+func (h *CheckoutHandler) ApproveSession(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	result, err := h.checkoutService.ApproveSession(c.Params("id"), account.ConnectID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, result)
+}
+
+// DeclineSession cancels a checkout session on behalf of the payer
+func (h *CheckoutHandler) DeclineSession(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	result, err := h.checkoutService.DeclineSession(c.Params("id"), account.ConnectID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, result)
+}
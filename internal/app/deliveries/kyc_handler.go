@@ -0,0 +1,135 @@
+package deliveries
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/middlewares"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/app/services"
+)
+
+type KYCHandler struct {
+	kycService            *services.KYCService
+	authMiddleware        *middlewares.AuthMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
+}
+
+func NewKYCHandler(
+	kycService *services.KYCService,
+	authMiddleware *middlewares.AuthMiddleware,
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware,
+) *KYCHandler {
+	return &KYCHandler{
+		kycService:            kycService,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+func (h *KYCHandler) RegisterRoutes(router fiber.Router) {
+	kycGroup := router.Group("/accounts/me/kyc", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent)
+	kycGroup.Post("/", h.Submit)
+	kycGroup.Get("/", h.GetOverview)
+
+	adminGroup := router.Group("/admin/kyc/submissions", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAdmin, h.idempotencyMiddleware.Idempotent)
+	adminGroup.Get("/", h.GetSubmissions)
+	adminGroup.Get("/:id", h.GetSubmission)
+	adminGroup.Post("/:id/approve", h.ApproveSubmission)
+	adminGroup.Post("/:id/reject", h.RejectSubmission)
+}
+
+// Submit sends the account's identity data for review
+func (h *KYCHandler) Submit(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	var req models.KYCSubmitRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	submission, err := h.kycService.Submit(account.ConnectID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, submission)
+}
+
+// GetOverview returns the account's KYC status, latest submission and status history
+func (h *KYCHandler) GetOverview(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	overview, err := h.kycService.GetOverview(account)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, overview)
+}
+
+// GetSubmissions lists KYC submissions by status, pending by default
+func (h *KYCHandler) GetSubmissions(c *fiber.Ctx) error {
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	status := models.KYCSubmissionStatus(c.Query("status", string(models.KYCSubmissionStatusPending)))
+
+	submissions, err := h.kycService.GetSubmissions(status, pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, submissions)
+}
+
+// GetSubmission returns a KYC submission
+func (h *KYCHandler) GetSubmission(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid KYC submission ID"))
+	}
+
+	submission, err := h.kycService.GetSubmission(id)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, submission)
+}
+
+// ApproveSubmission verifies the account at the submission's level
+func (h *KYCHandler) ApproveSubmission(c *fiber.Ctx) error {
+	return h.review(c, h.kycService.ApproveSubmission)
+}
+
+// RejectSubmission rejects a KYC submission with a reason
+func (h *KYCHandler) RejectSubmission(c *fiber.Ctx) error {
+	return h.review(c, h.kycService.RejectSubmission)
+}
+
+func (h *KYCHandler) review(c *fiber.Ctx, review func(id uuid.UUID, reviewerID uuid.UUID, req *models.KYCReviewRequest) (*models.KYCSubmission, error)) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid KYC submission ID"))
+	}
+
+	var req models.KYCReviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+		}
+	}
+
+	submission, err := review(id, connectUser.ID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, submission)
+}
@@ -0,0 +1,177 @@
+package deliveries
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/middlewares"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/app/services"
+)
+
+type MerchantAPIKeyHandler struct {
+	apiKeyService         *services.MerchantAPIKeyService
+	authMiddleware        *middlewares.AuthMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
+}
+
+func NewMerchantAPIKeyHandler(
+	apiKeyService *services.MerchantAPIKeyService,
+	authMiddleware *middlewares.AuthMiddleware,
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware,
+) *MerchantAPIKeyHandler {
+	return &MerchantAPIKeyHandler{
+		apiKeyService:         apiKeyService,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+func (h *MerchantAPIKeyHandler) RegisterRoutes(router fiber.Router) {
+	// Merchant dashboard routes (Connect token of a verified merchant account required)
+	apiKeyGroup := router.Group("/merchants/api-keys", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.authMiddleware.AuthMerchant, h.idempotencyMiddleware.Idempotent)
+	apiKeyGroup.Post("/", h.CreateAPIKey)
+	apiKeyGroup.Get("/", h.GetAPIKeys)
+	apiKeyGroup.Get("/analytics", h.GetAPIKeyAnalytics)
+	apiKeyGroup.Get("/:id", h.GetAPIKey)
+	apiKeyGroup.Patch("/:id", h.UpdateAPIKey)
+	apiKeyGroup.Delete("/:id", h.RevokeAPIKey)
+	apiKeyGroup.Post("/:id/rotate", h.RotateAPIKey)
+	apiKeyGroup.Get("/:id/usage", h.GetAPIKeyUsage)
+}
+
+// CreateAPIKey creates an API key and returns its value. The value is not shown again.
+func (h *MerchantAPIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	var req models.APIKeyCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	apiKey, err := h.apiKeyService.CreateAPIKey(c.Context(), account.ConnectID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, apiKey)
+}
+
+// GetAPIKeys lists the merchant's API keys, including revoked keys
+func (h *MerchantAPIKeyHandler) GetAPIKeys(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	apiKeys, err := h.apiKeyService.ListAPIKeys(c.Context(), account.ConnectID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, apiKeys)
+}
+
+// GetAPIKeyAnalytics returns the usage summary of each of the merchant's active API keys
+func (h *MerchantAPIKeyHandler) GetAPIKeyAnalytics(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	analytics, err := h.apiKeyService.GetAPIKeyAnalytics(c.Context(), account.ConnectID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, analytics)
+}
+
+// GetAPIKey returns one of the merchant's API keys
+func (h *MerchantAPIKeyHandler) GetAPIKey(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid API key ID"))
+	}
+
+	apiKey, err := h.apiKeyService.GetMerchantAPIKey(c.Context(), id, account.ConnectID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, apiKey)
+}
+
+// UpdateAPIKey changes the name, scopes, rate limit or expiry of an API key
+func (h *MerchantAPIKeyHandler) UpdateAPIKey(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid API key ID"))
+	}
+
+	var req models.APIKeyUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	apiKey, err := h.apiKeyService.UpdateAPIKey(c.Context(), id, account.ConnectID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, apiKey)
+}
+
+// RevokeAPIKey permanently disables an API key
+func (h *MerchantAPIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid API key ID"))
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(c.Context(), id, account.ConnectID); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse[any](c, nil)
+}
+
+// RotateAPIKey replaces the value of an API key and returns the new value
+func (h *MerchantAPIKeyHandler) RotateAPIKey(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid API key ID"))
+	}
+
+	apiKey, err := h.apiKeyService.RotateAPIKey(c.Context(), id, account.ConnectID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, apiKey)
+}
+
+// GetAPIKeyUsage returns the usage summary and recent requests of an API key
+func (h *MerchantAPIKeyHandler) GetAPIKeyUsage(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid API key ID"))
+	}
+
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	usage, err := h.apiKeyService.GetAPIKeyUsage(c.Context(), id, account.ConnectID, pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, usage)
+}
@@ -0,0 +1,168 @@
+package deliveries
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/middlewares"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/app/services"
+)
+
+type MerchantHandler struct {
+	merchantService       *services.MerchantService
+	authMiddleware        *middlewares.AuthMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
+}
+
+func NewMerchantHandler(
+	merchantService *services.MerchantService,
+	authMiddleware *middlewares.AuthMiddleware,
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware,
+) *MerchantHandler {
+	return &MerchantHandler{
+		merchantService:       merchantService,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+func (h *MerchantHandler) RegisterRoutes(router fiber.Router) {
+	applicationGroup := router.Group("/merchants/applications", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent)
+	applicationGroup.Post("/", h.Apply)
+	applicationGroup.Get("/", h.GetMyApplications)
+
+	profileGroup := router.Group("/merchants/profile", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.authMiddleware.AuthMerchant, h.idempotencyMiddleware.Idempotent)
+	profileGroup.Get("/", h.GetProfile)
+	profileGroup.Patch("/", h.UpdateProfile)
+
+	adminGroup := router.Group("/admin/merchant-applications", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAdmin, h.idempotencyMiddleware.Idempotent)
+	adminGroup.Get("/", h.GetApplications)
+	adminGroup.Get("/:id", h.GetApplication)
+	adminGroup.Post("/:id/approve", h.ApproveApplication)
+	adminGroup.Post("/:id/reject", h.RejectApplication)
+}
+
+// Apply sends the account's business profile and settlement bank account for review
+func (h *MerchantHandler) Apply(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	var req models.MerchantApplicationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	application, err := h.merchantService.Apply(account.ConnectID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, application)
+}
+
+// GetMyApplications lists the account's merchant applications
+func (h *MerchantHandler) GetMyApplications(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	applications, err := h.merchantService.GetAccountApplications(account.ConnectID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, applications)
+}
+
+// GetProfile returns the merchant's profile
+func (h *MerchantHandler) GetProfile(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	profile, err := h.merchantService.GetProfile(account.ConnectID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, profile)
+}
+
+// UpdateProfile changes the display and contact details of the merchant's profile
+func (h *MerchantHandler) UpdateProfile(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	var req models.MerchantProfileUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	profile, err := h.merchantService.UpdateProfile(account.ConnectID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, profile)
+}
+
+// GetApplications lists merchant applications by status, pending by default
+func (h *MerchantHandler) GetApplications(c *fiber.Ctx) error {
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	status := models.MerchantApplicationStatus(c.Query("status", string(models.MerchantApplicationStatusPending)))
+
+	applications, err := h.merchantService.GetApplications(status, pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, applications)
+}
+
+// GetApplication returns a merchant application
+func (h *MerchantHandler) GetApplication(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid merchant application ID"))
+	}
+
+	application, err := h.merchantService.GetApplication(id)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, application)
+}
+
+// ApproveApplication makes the applicant a merchant
+func (h *MerchantHandler) ApproveApplication(c *fiber.Ctx) error {
+	return h.review(c, h.merchantService.ApproveApplication)
+}
+
+// RejectApplication rejects a merchant application with a reason
+func (h *MerchantHandler) RejectApplication(c *fiber.Ctx) error {
+	return h.review(c, h.merchantService.RejectApplication)
+}
+
+func (h *MerchantHandler) review(c *fiber.Ctx, review func(id uuid.UUID, reviewerID uuid.UUID, req *models.MerchantApplicationReviewRequest) (*models.MerchantApplication, error)) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid merchant application ID"))
+	}
+
+	var req models.MerchantApplicationReviewRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+		}
+	}
+
+	application, err := review(id, connectUser.ID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, application)
+}
@@ -0,0 +1,176 @@
+package deliveries
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/middlewares"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/app/services"
+)
+
+type MerchantWebhookHandler struct {
+	webhookService        *services.MerchantWebhookService
+	apiKeyMiddleware      *middlewares.APIKeyMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
+}
+
+func NewMerchantWebhookHandler(
+	webhookService *services.MerchantWebhookService,
+	apiKeyMiddleware *middlewares.APIKeyMiddleware,
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware,
+) *MerchantWebhookHandler {
+	return &MerchantWebhookHandler{
+		webhookService:        webhookService,
+		apiKeyMiddleware:      apiKeyMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+func (h *MerchantWebhookHandler) RegisterRoutes(router fiber.Router) {
+	endpointGroup := router.Group("/merchants/webhook-endpoints", h.apiKeyMiddleware.RequireAPIKey, h.idempotencyMiddleware.Idempotent)
+	endpointGroup.Post("/", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeWrite), h.CreateEndpoint)
+	endpointGroup.Get("/", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeRead), h.GetEndpoints)
+	endpointGroup.Get("/:id", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeRead), h.GetEndpoint)
+	endpointGroup.Patch("/:id", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeWrite), h.UpdateEndpoint)
+	endpointGroup.Delete("/:id", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeWrite), h.DeleteEndpoint)
+	endpointGroup.Post("/:id/rotate-secret", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeWrite), h.RotateSecret)
+
+	deliveryGroup := router.Group("/merchants/webhook-deliveries", h.apiKeyMiddleware.RequireAPIKey, h.idempotencyMiddleware.Idempotent)
+	deliveryGroup.Get("/", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeRead), h.GetDeliveries)
+	deliveryGroup.Get("/:id", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeRead), h.GetDelivery)
+	deliveryGroup.Post("/:id/redeliver", h.apiKeyMiddleware.RequireScopes(models.APIKeyScopeWrite), h.Redeliver)
+}
+
+// CreateEndpoint registers a webhook endpoint and returns its signing secret
+func (h *MerchantWebhookHandler) CreateEndpoint(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	var req models.WebhookEndpointCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	endpoint, err := h.webhookService.CreateEndpoint(apiKey.MerchantID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, endpoint)
+}
+
+// GetEndpoints lists the merchant's webhook endpoints
+func (h *MerchantWebhookHandler) GetEndpoints(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	endpoints, err := h.webhookService.GetEndpoints(apiKey.MerchantID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, endpoints)
+}
+
+// GetEndpoint returns one of the merchant's webhook endpoints
+func (h *MerchantWebhookHandler) GetEndpoint(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	endpoint, err := h.webhookService.GetEndpoint(apiKey.MerchantID, c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, endpoint)
+}
+
+// UpdateEndpoint changes a webhook endpoint
+func (h *MerchantWebhookHandler) UpdateEndpoint(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	var req models.WebhookEndpointUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	endpoint, err := h.webhookService.UpdateEndpoint(apiKey.MerchantID, c.Params("id"), &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, endpoint)
+}
+
+// DeleteEndpoint removes a webhook endpoint
+func (h *MerchantWebhookHandler) DeleteEndpoint(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	if err := h.webhookService.DeleteEndpoint(apiKey.MerchantID, c.Params("id")); err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse[any](c, nil)
+}
+
+// RotateSecret replaces the signing secret of a webhook endpoint
+func (h *MerchantWebhookHandler) RotateSecret(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	endpoint, err := h.webhookService.RotateSecret(apiKey.MerchantID, c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, endpoint)
+}
+
+// GetDeliveries lists the merchant's webhook deliveries
+func (h *MerchantWebhookHandler) GetDeliveries(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	var endpointId *string
+	if id := c.Query("endpoint_id"); id != "" {
+		endpointId = &id
+	}
+
+	var status *models.WebhookDeliveryStatus
+	if s := c.Query("status"); s != "" {
+		deliveryStatus := models.WebhookDeliveryStatus(s)
+		status = &deliveryStatus
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(apiKey.MerchantID, endpointId, status, pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, deliveries)
+}
+
+// GetDelivery returns a webhook delivery with its attempt log
+func (h *MerchantWebhookHandler) GetDelivery(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	delivery, err := h.webhookService.GetDelivery(apiKey.MerchantID, c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, delivery)
+}
+
+// Redeliver sends a webhook delivery again right away
+func (h *MerchantWebhookHandler) Redeliver(c *fiber.Ctx) error {
+	apiKey := c.Locals("api_key").(*models.MerchantAPIKey)
+
+	delivery, err := h.webhookService.Redeliver(c.Context(), apiKey.MerchantID, c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, delivery)
+}
@@ -0,0 +1,66 @@
+package deliveries
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/safatanc/gsalt-core/internal/app/middlewares"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/app/services"
+)
+
+type SchedulerHandler struct {
+	schedulerService *services.SchedulerService
+	authMiddleware   *middlewares.AuthMiddleware
+}
+
+func NewSchedulerHandler(schedulerService *services.SchedulerService, authMiddleware *middlewares.AuthMiddleware) *SchedulerHandler {
+	return &SchedulerHandler{
+		schedulerService: schedulerService,
+		authMiddleware:   authMiddleware,
+	}
+}
+
+func (h *SchedulerHandler) RegisterRoutes(router fiber.Router) {
+	jobGroup := router.Group("/admin/jobs", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAdmin)
+
+	jobGroup.Get("/", h.ListJobs)
+	jobGroup.Post("/:name/run", h.TriggerJob)
+	jobGroup.Get("/:name/runs", h.GetJobRuns)
+}
+
+// ListJobs returns all scheduled jobs with their next and last run
+func (h *SchedulerHandler) ListJobs(c *fiber.Ctx) error {
+	jobs, err := h.schedulerService.ListJobs(c.Context())
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, jobs)
+}
+
+// TriggerJob starts a job in the background and returns its run
+func (h *SchedulerHandler) TriggerJob(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	run, err := h.schedulerService.TriggerJob(c.Params("name"), &connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, run)
+}
+
+// GetJobRuns returns the run history of a job
+func (h *SchedulerHandler) GetJobRuns(c *fiber.Ctx) error {
+	pagination := &models.PaginationRequest{
+		Page:  c.QueryInt("page", 1),
+		Limit: c.QueryInt("limit", 10),
+	}
+
+	runs, err := h.schedulerService.GetJobRuns(c.Params("name"), pagination)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, runs)
+}
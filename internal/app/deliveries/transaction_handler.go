@@ -1,7 +1,6 @@
 package deliveries
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,23 +15,35 @@ import (
 )
 
 type TransactionHandler struct {
-	transactionService   *services.TransactionService
-	paymentService       *services.PaymentService
-	paymentMethodService *services.PaymentMethodService
-	authMiddleware       *middlewares.AuthMiddleware
+	transactionService    *services.TransactionService
+	paymentService        *services.PaymentService
+	paymentMethodService  *services.PaymentMethodService
+	inboundWebhookService *services.InboundWebhookService
+	refundService         *services.RefundService
+	recipientService      *services.TransferRecipientService
+	authMiddleware        *middlewares.AuthMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
 }
 
 func NewTransactionHandler(
 	transactionService *services.TransactionService,
 	paymentService *services.PaymentService,
 	paymentMethodService *services.PaymentMethodService,
+	inboundWebhookService *services.InboundWebhookService,
+	refundService *services.RefundService,
+	recipientService *services.TransferRecipientService,
 	authMiddleware *middlewares.AuthMiddleware,
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware,
 ) *TransactionHandler {
 	return &TransactionHandler{
-		transactionService:   transactionService,
-		paymentService:       paymentService,
-		paymentMethodService: paymentMethodService,
-		authMiddleware:       authMiddleware,
+		transactionService:    transactionService,
+		paymentService:        paymentService,
+		paymentMethodService:  paymentMethodService,
+		inboundWebhookService: inboundWebhookService,
+		refundService:         refundService,
+		recipientService:      recipientService,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
 	}
 }
 
@@ -41,10 +52,11 @@ func (h *TransactionHandler) RegisterRoutes(router fiber.Router) {
 
 	// Public routes (no auth required)
 	transactionGroup.Post("/webhook/flip", h.HandleFlipWebhook)
+	transactionGroup.Post("/webhook/flip/disbursement", h.HandleFlipDisbursementWebhook)
 	transactionGroup.Get("/ref/:ref", h.GetTransactionByRef)
 
 	// Protected routes (auth required)
-	auth := transactionGroup.Group("/", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount)
+	auth := transactionGroup.Group("/", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent)
 
 	// Transaction CRUD
 	auth.Post("/", h.CreateTransaction)
@@ -54,6 +66,8 @@ func (h *TransactionHandler) RegisterRoutes(router fiber.Router) {
 	// Transaction operations
 	auth.Post("/topup", h.ProcessTopup)
 	auth.Post("/transfer", h.ProcessTransfer)
+	auth.Post("/transfer/preview", h.PreviewTransferRecipient)
+	auth.Post("/transfer/qr", h.CreateTransferQR)
 	auth.Post("/payment", h.ProcessPayment)
 	auth.Get("/payment-methods", h.GetSupportedPaymentMethods)
 	auth.Post("/:id/confirm", h.ConfirmPayment)
@@ -68,6 +82,13 @@ func (h *TransactionHandler) RegisterRoutes(router fiber.Router) {
 
 	// Move the :id route to the bottom to avoid catching other routes
 	transactionGroup.Get("/:id", h.GetTransaction)
+
+	// Refunds and reversals (support staff)
+	adminGroup := router.Group("/admin/transactions", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAdmin, h.idempotencyMiddleware.Idempotent)
+	adminGroup.Post("/:id/refunds", h.RefundTransaction)
+	adminGroup.Get("/:id/refunds", h.GetRefunds)
+	adminGroup.Post("/:id/refund-payout", h.RecordRefundPayout)
+	adminGroup.Post("/:id/reversal", h.ReverseTransaction)
 }
 
 // CreateTransaction handles transaction creation
@@ -228,27 +249,16 @@ func (h *TransactionHandler) ProcessTopup(c *fiber.Ctx) error {
 	return pkg.SuccessResponse(c, topupResponse)
 }
 
+// ProcessTransfer sends GSALT to an account ID, Connect username or email, or QR payload
 func (h *TransactionHandler) ProcessTransfer(c *fiber.Ctx) error {
 	account := c.Locals("account").(*models.Account)
 
 	var req models.TransferRequest
 	if err := c.BodyParser(&req); err != nil {
-		return pkg.ErrorResponse(c, err)
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
 	}
 
-	// Convert GSALT amount to units (1 GSALT = 100 units)
-	amountGsalt, err := decimal.NewFromString(req.AmountGsalt)
-	if err != nil {
-		return pkg.ErrorResponse(c, err)
-	}
-	amountGsaltUnits := amountGsalt.Mul(decimal.NewFromInt(100)).IntPart()
-
-	transferOut, transferIn, err := h.transactionService.ProcessTransfer(
-		account.ConnectID.String(),
-		req.DestinationAccountID,
-		amountGsaltUnits,
-		req.Description,
-	)
+	transferOut, transferIn, err := h.recipientService.Transfer(account.ConnectID, &req)
 	if err != nil {
 		return pkg.ErrorResponse(c, err)
 	}
@@ -261,6 +271,40 @@ func (h *TransactionHandler) ProcessTransfer(c *fiber.Ctx) error {
 	return pkg.SuccessResponse(c, response)
 }
 
+// PreviewTransferRecipient returns the masked recipient of a transfer before it is confirmed
+func (h *TransactionHandler) PreviewTransferRecipient(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	var req models.TransferRecipient
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	preview, err := h.recipientService.Preview(account.ConnectID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, preview)
+}
+
+// CreateTransferQR issues a signed QR payload that pays the current account
+func (h *TransactionHandler) CreateTransferQR(c *fiber.Ctx) error {
+	account := c.Locals("account").(*models.Account)
+
+	var req models.TransferQRRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	qr, err := h.recipientService.CreateQR(account.ConnectID, &req)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, qr)
+}
+
 // ProcessPayment handles payment transaction
 func (h *TransactionHandler) ProcessPayment(c *fiber.Ctx) error {
 	var req models.PaymentRequest
@@ -348,60 +392,52 @@ func (h *TransactionHandler) RejectPayment(c *fiber.Ctx) error {
 	return pkg.SuccessResponse(c, transaction)
 }
 
-// HandleFlipWebhook handles webhook notifications from Flip
+// HandleFlipWebhook handles accept-payment callbacks from Flip.
+// The callback token is verified and each Flip payment ID is processed at most once.
 func (h *TransactionHandler) HandleFlipWebhook(c *fiber.Ctx) error {
-	var payload models.FlipWebhookPayload
-	if err := c.BodyParser(&payload); err != nil {
-		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid webhook payload"))
-	}
-
-	// Process webhook based on payment status
-	transactionID := payload.BillTitle // Assuming we store transaction ID in bill title
-	txID, err := uuid.Parse(transactionID)
+	event, err := h.inboundWebhookService.HandlePaymentWebhook(c.Context(), models.PaymentProviderFlip, providerWebhookRequest(c))
 	if err != nil {
-		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid transaction ID in bill title"))
+		return pkg.ErrorResponse(c, err)
 	}
 
-	billID := fmt.Sprintf("%d", payload.BillLinkID)
-	now := time.Now()
-
-	var req models.PaymentStatusUpdateRequest
-	req.ProviderPaymentID = &billID
-	req.PaymentTime = &now
-
-	switch payload.Status {
-	case models.FlipStatusSuccessful:
-		req.Status = models.PaymentStatusCompleted
-	case models.FlipStatusFailed:
-		req.Status = models.PaymentStatusFailed
-		desc := fmt.Sprintf("Payment failed in Flip: %s", payload.Status)
-		req.StatusDescription = &desc
-	case models.FlipStatusExpired:
-		req.Status = models.PaymentStatusExpired
-		desc := "Payment expired in Flip"
-		req.StatusDescription = &desc
-	case models.FlipStatusCancelled:
-		req.Status = models.PaymentStatusCancelled
-		desc := "Payment cancelled in Flip"
-		req.StatusDescription = &desc
-	default:
-		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid payment status"))
-	}
+	return c.JSON(fiber.Map{
+		"status":  "success",
+		"message": "Webhook processed",
+		"result":  event.Status,
+	})
+}
 
-	// Update payment status
-	err = h.paymentService.UpdatePaymentStatus(c.Context(), txID, &req)
+// HandleFlipDisbursementWebhook handles disbursement callbacks from Flip.
+// The callback token is verified and each disbursement status is processed at most once.
+func (h *TransactionHandler) HandleFlipDisbursementWebhook(c *fiber.Ctx) error {
+	event, err := h.inboundWebhookService.HandleDisbursementWebhook(c.Context(), models.PaymentProviderFlip, providerWebhookRequest(c))
 	if err != nil {
-		// Log error but don't return error to Flip to prevent retries
-		fmt.Printf("Failed to update payment status for transaction %s: %v\n", transactionID, err)
+		return pkg.ErrorResponse(c, err)
 	}
 
-	// Always return success to Flip to prevent retries
 	return c.JSON(fiber.Map{
 		"status":  "success",
 		"message": "Webhook processed",
+		"result":  event.Status,
 	})
 }
 
+// providerWebhookRequest captures the raw callback for signature verification
+func providerWebhookRequest(c *fiber.Ctx) models.ProviderWebhookRequest {
+	req := models.ProviderWebhookRequest{
+		ContentType: c.Get(fiber.HeaderContentType),
+		Headers:     make(map[string]string),
+		Body:        c.Body(),
+	}
+	for key, values := range c.GetReqHeaders() {
+		if len(values) > 0 {
+			req.Headers[key] = values[0]
+		}
+	}
+
+	return req
+}
+
 // GetSupportedPaymentMethods returns all supported payment methods with their fees
 func (h *TransactionHandler) GetSupportedPaymentMethods(c *fiber.Ctx) error {
 	// 1. Bind filter from query parameters
@@ -510,3 +546,64 @@ func (h *TransactionHandler) ValidateBankAccountForWithdrawal(c *fiber.Ctx) erro
 
 	return pkg.SuccessResponse(c, response)
 }
+
+// RefundTransaction refunds all or part of a completed topup or payment
+func (h *TransactionHandler) RefundTransaction(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	var req models.RefundRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	refund, err := h.refundService.RefundTransaction(c.Params("id"), &req, &connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, refund)
+}
+
+// GetRefunds lists the refunds and reversals of a transaction
+func (h *TransactionHandler) GetRefunds(c *fiber.Ctx) error {
+	refunds, err := h.refundService.GetRefunds(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, refunds)
+}
+
+// RecordRefundPayout marks a refund as paid out manually
+func (h *TransactionHandler) RecordRefundPayout(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	var req models.RefundPayoutRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	refund, err := h.refundService.RecordRefundPayout(c.Params("id"), &req, &connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, refund)
+}
+
+// ReverseTransaction fully undoes a completed transaction
+func (h *TransactionHandler) ReverseTransaction(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	var req models.ReversalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	reversals, err := h.refundService.ReverseTransaction(c.Params("id"), &req, &connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, reversals)
+}
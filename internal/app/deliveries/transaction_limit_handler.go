@@ -0,0 +1,178 @@
+package deliveries
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/middlewares"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/app/services"
+)
+
+type TransactionLimitHandler struct {
+	limitService          *services.TransactionLimitService
+	authMiddleware        *middlewares.AuthMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
+}
+
+func NewTransactionLimitHandler(
+	limitService *services.TransactionLimitService,
+	authMiddleware *middlewares.AuthMiddleware,
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware,
+) *TransactionLimitHandler {
+	return &TransactionLimitHandler{
+		limitService:          limitService,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
+	}
+}
+
+func (h *TransactionLimitHandler) RegisterRoutes(router fiber.Router) {
+	limitGroup := router.Group("/admin/transaction-limits", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAdmin, h.idempotencyMiddleware.Idempotent)
+	limitGroup.Get("/", h.GetConfig)
+	limitGroup.Put("/default", h.UpdateDefaultLimits)
+	limitGroup.Put("/account-types/:type", h.SetAccountTypeOverride)
+	limitGroup.Delete("/account-types/:type", h.DeleteAccountTypeOverride)
+	limitGroup.Put("/kyc-statuses/:status", h.SetKYCOverride)
+	limitGroup.Delete("/kyc-statuses/:status", h.DeleteKYCOverride)
+	limitGroup.Put("/kyc-levels/:level", h.SetKYCLevelOverride)
+	limitGroup.Delete("/kyc-levels/:level", h.DeleteKYCLevelOverride)
+	limitGroup.Put("/accounts/:id", h.UpdateAccountLimits)
+}
+
+// GetConfig returns the default limits and all overrides
+func (h *TransactionLimitHandler) GetConfig(c *fiber.Ctx) error {
+	config, err := h.limitService.GetConfig(c.Context())
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, config)
+}
+
+// UpdateDefaultLimits replaces the default limits
+func (h *TransactionLimitHandler) UpdateDefaultLimits(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	var req models.TransactionLimits
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	config, err := h.limitService.UpdateDefaultLimits(c.Context(), &req, connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, config)
+}
+
+// SetAccountTypeOverride replaces the limit override of an account type
+func (h *TransactionLimitHandler) SetAccountTypeOverride(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	var req models.TransactionLimitsOverride
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	config, err := h.limitService.SetAccountTypeOverride(c.Context(), models.AccountType(c.Params("type")), &req, connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, config)
+}
+
+// DeleteAccountTypeOverride removes the limit override of an account type
+func (h *TransactionLimitHandler) DeleteAccountTypeOverride(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	config, err := h.limitService.DeleteAccountTypeOverride(c.Context(), models.AccountType(c.Params("type")), connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, config)
+}
+
+// SetKYCOverride replaces the limit override of a KYC status
+func (h *TransactionLimitHandler) SetKYCOverride(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	var req models.TransactionLimitsOverride
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	config, err := h.limitService.SetKYCOverride(c.Context(), models.KYCStatus(c.Params("status")), &req, connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, config)
+}
+
+// DeleteKYCOverride removes the limit override of a KYC status
+func (h *TransactionLimitHandler) DeleteKYCOverride(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	config, err := h.limitService.DeleteKYCOverride(c.Context(), models.KYCStatus(c.Params("status")), connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, config)
+}
+
+// SetKYCLevelOverride replaces the limit override of a KYC level
+func (h *TransactionLimitHandler) SetKYCLevelOverride(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	var req models.TransactionLimitsOverride
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	config, err := h.limitService.SetKYCLevelOverride(c.Context(), models.KYCLevel(c.Params("level")), &req, connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, config)
+}
+
+// DeleteKYCLevelOverride removes the limit override of a KYC level
+func (h *TransactionLimitHandler) DeleteKYCLevelOverride(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	config, err := h.limitService.DeleteKYCLevelOverride(c.Context(), models.KYCLevel(c.Params("level")), connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, config)
+}
+
+// UpdateAccountLimits sets the daily and monthly spending limits of one account
+func (h *TransactionLimitHandler) UpdateAccountLimits(c *fiber.Ctx) error {
+	connectUser := c.Locals("connect_user").(*models.ConnectUser)
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid account ID"))
+	}
+
+	var req models.AccountLimitsUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Invalid request body"))
+	}
+
+	account, err := h.limitService.UpdateAccountLimits(c.Context(), id, &req, connectUser.ID)
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	return pkg.SuccessResponse(c, account)
+}
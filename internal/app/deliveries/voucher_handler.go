@@ -11,14 +11,16 @@ import (
 )
 
 type VoucherHandler struct {
-	voucherService *services.VoucherService
-	authMiddleware *middlewares.AuthMiddleware
+	voucherService        *services.VoucherService
+	authMiddleware        *middlewares.AuthMiddleware
+	idempotencyMiddleware *middlewares.IdempotencyMiddleware
 }
 
-func NewVoucherHandler(voucherService *services.VoucherService, authMiddleware *middlewares.AuthMiddleware) *VoucherHandler {
+func NewVoucherHandler(voucherService *services.VoucherService, authMiddleware *middlewares.AuthMiddleware, idempotencyMiddleware *middlewares.IdempotencyMiddleware) *VoucherHandler {
 	return &VoucherHandler{
-		voucherService: voucherService,
-		authMiddleware: authMiddleware,
+		voucherService:        voucherService,
+		authMiddleware:        authMiddleware,
+		idempotencyMiddleware: idempotencyMiddleware,
 	}
 }
 
@@ -32,9 +34,9 @@ func (h *VoucherHandler) RegisterRoutes(router fiber.Router) {
 	voucherGroup.Post("/validate/:code", h.ValidateVoucher)
 
 	// Protected endpoints (require authentication)
-	voucherGroup.Post("/", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.CreateVoucher)
-	voucherGroup.Patch("/:id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.UpdateVoucher)
-	voucherGroup.Delete("/:id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.DeleteVoucher)
+	voucherGroup.Post("/", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent, h.CreateVoucher)
+	voucherGroup.Patch("/:id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent, h.UpdateVoucher)
+	voucherGroup.Delete("/:id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent, h.DeleteVoucher)
 }
 
 func (h *VoucherHandler) CreateVoucher(c *fiber.Ctx) error {
@@ -13,12 +13,14 @@ import (
 type VoucherRedemptionHandler struct {
 	voucherRedemptionService *services.VoucherRedemptionService
 	authMiddleware           *middlewares.AuthMiddleware
+	idempotencyMiddleware    *middlewares.IdempotencyMiddleware
 }
 
-func NewVoucherRedemptionHandler(voucherRedemptionService *services.VoucherRedemptionService, authMiddleware *middlewares.AuthMiddleware) *VoucherRedemptionHandler {
+func NewVoucherRedemptionHandler(voucherRedemptionService *services.VoucherRedemptionService, authMiddleware *middlewares.AuthMiddleware, idempotencyMiddleware *middlewares.IdempotencyMiddleware) *VoucherRedemptionHandler {
 	return &VoucherRedemptionHandler{
 		voucherRedemptionService: voucherRedemptionService,
 		authMiddleware:           authMiddleware,
+		idempotencyMiddleware:    idempotencyMiddleware,
 	}
 }
 
@@ -26,14 +28,14 @@ func (h *VoucherRedemptionHandler) RegisterRoutes(router fiber.Router) {
 	redemptionGroup := router.Group("/voucher-redemptions")
 
 	// All endpoints require authentication
-	redemptionGroup.Post("/", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.CreateRedemption)
+	redemptionGroup.Post("/", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent, h.CreateRedemption)
 	redemptionGroup.Get("/me", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.GetMyRedemptions)
 	redemptionGroup.Get("/:id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.GetRedemption)
-	redemptionGroup.Patch("/:id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.UpdateRedemption)
-	redemptionGroup.Delete("/:id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.DeleteRedemption)
+	redemptionGroup.Patch("/:id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent, h.UpdateRedemption)
+	redemptionGroup.Delete("/:id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent, h.DeleteRedemption)
 
 	// Voucher redemption endpoint
-	redemptionGroup.Post("/redeem", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.RedeemVoucher)
+	redemptionGroup.Post("/redeem", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.idempotencyMiddleware.Idempotent, h.RedeemVoucher)
 
 	// Admin endpoints for getting redemptions by voucher
 	redemptionGroup.Get("/voucher/:voucher_id", h.authMiddleware.AuthConnect, h.authMiddleware.AuthAccount, h.GetRedemptionsByVoucher)
@@ -2,32 +2,61 @@ package middlewares
 
 import (
 	"context"
+	"crypto/hmac"
+	stderrors "errors"
 	"fmt"
+	"net/netip"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/redis/go-redis/v9"
 	"github.com/safatanc/gsalt-core/internal/app/errors"
 	"github.com/safatanc/gsalt-core/internal/app/models"
 	"github.com/safatanc/gsalt-core/internal/app/pkg"
 	"github.com/safatanc/gsalt-core/internal/app/services"
 )
 
-// APIKeyMiddleware handles API key authentication and rate limiting
+const (
+	// RequestSignatureHeader carries the hex HMAC-SHA256 of a signed merchant request
+	RequestSignatureHeader = "X-GSALT-Signature"
+	// RequestTimestampHeader carries the Unix time the request was signed at
+	RequestTimestampHeader = "X-GSALT-Timestamp"
+	// RequestNonceHeader carries a unique value per signed request
+	RequestNonceHeader = "X-GSALT-Nonce"
+
+	// requestSignatureMaxSkew is how far a signed request's timestamp may be from the server clock
+	requestSignatureMaxSkew = 5 * time.Minute
+	minRequestNonceLength   = 16
+	maxRequestNonceLength   = 128
+)
+
+// APIKeyMiddleware handles API key authentication, request signatures and rate limiting
 type APIKeyMiddleware struct {
 	apiKeyService *services.MerchantAPIKeyService
+	usageRecorder *services.APIKeyUsageRecorder
 	rateLimiter   RateLimiter
+	redis         *redis.Client
+	keyPrefix     string
 }
 
 // NewAPIKeyMiddleware creates a new APIKeyMiddleware
-func NewAPIKeyMiddleware(apiKeyService *services.MerchantAPIKeyService, rateLimiter RateLimiter) *APIKeyMiddleware {
+func NewAPIKeyMiddleware(apiKeyService *services.MerchantAPIKeyService, usageRecorder *services.APIKeyUsageRecorder, rateLimiter RateLimiter, redis *redis.Client, keyPrefix string) *APIKeyMiddleware {
 	return &APIKeyMiddleware{
 		apiKeyService: apiKeyService,
+		usageRecorder: usageRecorder,
 		rateLimiter:   rateLimiter,
+		redis:         redis,
+		keyPrefix:     keyPrefix,
 	}
 }
 
-// AuthAPIKey creates a middleware that authenticates API key
-func (m *APIKeyMiddleware) AuthAPIKey(c *fiber.Ctx) error {
+// AuthAPIKey creates a middleware that authenticates API key. Every request made with a valid
+// key is recorded once it completes, including requests rejected after authentication.
+func (m *APIKeyMiddleware) AuthAPIKey(c *fiber.Ctx) (err error) {
 	// Get API key from header
 	key := c.Get("X-API-Key")
 	if key == "" {
@@ -46,6 +75,16 @@ func (m *APIKeyMiddleware) AuthAPIKey(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, errors.NewUnauthorizedError("API key is inactive or expired"))
 	}
 
+	startedAt := time.Now()
+	defer func() {
+		m.recordUsage(c, apiKey, startedAt, err)
+	}()
+
+	// Check IP allowlist
+	if !isAllowedIP(apiKey.AllowedCIDRs, c.IP()) {
+		return pkg.ErrorResponse(c, errors.NewForbiddenError(fmt.Sprintf("IP address %s is not allowed for this API key", c.IP())))
+	}
+
 	// Check rate limit
 	allowed, info := m.rateLimiter.Allow(
 		"apikey:"+apiKey.ID.String(),
@@ -64,26 +103,12 @@ func (m *APIKeyMiddleware) AuthAPIKey(c *fiber.Ctx) error {
 		return pkg.ErrorResponse(c, errors.NewTooManyRequestsError("Rate limit exceeded", info.Limit, info.Reset.Unix()))
 	}
 
-	// Check required scopes
-	if !hasRequiredScopes(c, apiKey) {
-		return pkg.ErrorResponse(c, errors.NewForbiddenError("Insufficient permissions"))
-	}
-
-	// Log API key usage asynchronously
-	go func() {
-		usage := &models.MerchantAPIKeyUsage{
-			APIKeyID:   apiKey.ID,
-			Endpoint:   c.Path(),
-			Method:     c.Method(),
-			IPAddress:  c.IP(),
-			UserAgent:  c.Get("User-Agent"),
-			StatusCode: c.Response().StatusCode(),
-			CreatedAt:  time.Now(),
+	// Verify the request signature when the key requires one or the request carries one
+	if apiKey.RequireSignature || c.Get(RequestSignatureHeader) != "" {
+		if err := m.verifyRequestSignature(c, apiKey); err != nil {
+			return pkg.ErrorResponse(c, err)
 		}
-		if err := m.apiKeyService.LogAPIKeyUsage(context.Background(), usage); err != nil {
-			// TODO: Add proper error logging
-		}
-	}()
+	}
 
 	// Add API key and merchant ID to locals
 	c.Locals("api_key", apiKey)
@@ -92,37 +117,198 @@ func (m *APIKeyMiddleware) AuthAPIKey(c *fiber.Ctx) error {
 	return c.Next()
 }
 
-// hasRequiredScopes checks if the API key has all required scopes for the request
-func hasRequiredScopes(c *fiber.Ctx, key *models.MerchantAPIKey) bool {
-	// Define required scopes based on method and path
-	var requiredScopes []models.APIKeyScope
+// RequireAPIKey authenticates like AuthAPIKey but rejects requests without an API key
+func (m *APIKeyMiddleware) RequireAPIKey(c *fiber.Ctx) error {
+	if c.Get("X-API-Key") == "" {
+		return pkg.ErrorResponse(c, errors.NewUnauthorizedError("API key is required"))
+	}
+
+	return m.AuthAPIKey(c)
+}
+
+// recordUsage queues the usage record of a completed request. Values read from the fiber
+// context are copied because the context is reused once the request ends.
+func (m *APIKeyMiddleware) recordUsage(c *fiber.Ctx, apiKey *models.MerchantAPIKey, startedAt time.Time, err error) {
+	statusCode := c.Response().StatusCode()
+	if err != nil {
+		// The error handler writes the response after the middleware returns
+		statusCode = fiber.StatusInternalServerError
+		var fiberErr *fiber.Error
+		if stderrors.As(err, &fiberErr) {
+			statusCode = fiberErr.Code
+		}
+	}
+
+	m.usageRecorder.Record(models.MerchantAPIKeyUsage{
+		APIKeyID:   apiKey.ID,
+		Endpoint:   truncate(utils.CopyString(c.Path()), 255),
+		Method:     utils.CopyString(c.Method()),
+		IPAddress:  utils.CopyString(c.IP()),
+		UserAgent:  truncate(utils.CopyString(c.Get(fiber.HeaderUserAgent)), 255),
+		StatusCode: statusCode,
+		LatencyMs:  time.Since(startedAt).Milliseconds(),
+		CreatedAt:  startedAt,
+	})
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
+
+// verifyRequestSignature checks the HMAC signature, timestamp and nonce of a signed request.
+// The nonce is only recorded once the signature is valid, so forged requests cannot use it up.
+func (m *APIKeyMiddleware) verifyRequestSignature(c *fiber.Ctx, apiKey *models.MerchantAPIKey) error {
+	signature := c.Get(RequestSignatureHeader)
+	timestamp := c.Get(RequestTimestampHeader)
+	nonce := c.Get(RequestNonceHeader)
+
+	if signature == "" || timestamp == "" || nonce == "" {
+		return errors.NewUnauthorizedError(fmt.Sprintf("Request signature is required (%s, %s, %s)", RequestSignatureHeader, RequestTimestampHeader, RequestNonceHeader))
+	}
+
+	if apiKey.SigningSecret == nil {
+		return errors.NewUnauthorizedError("API key has no signing secret; rotate it to enable request signing")
+	}
 
-	// Add READ scope for GET requests
-	if c.Method() == fiber.MethodGet {
-		requiredScopes = append(requiredScopes, models.APIKeyScopeRead)
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.NewUnauthorizedError("Invalid request timestamp")
 	}
 
-	// Add WRITE scope for POST/PUT/DELETE requests
-	if c.Method() == fiber.MethodPost || c.Method() == fiber.MethodPut || c.Method() == fiber.MethodDelete {
-		requiredScopes = append(requiredScopes, models.APIKeyScopeWrite)
+	skew := time.Since(time.Unix(signedAt, 0))
+	if skew > requestSignatureMaxSkew || skew < -requestSignatureMaxSkew {
+		return errors.NewUnauthorizedError("Request timestamp is outside the allowed clock skew")
 	}
 
-	// Add PAYMENT scope for payment endpoints
-	if c.Path() == "/api/v1/payments" {
-		requiredScopes = append(requiredScopes, models.APIKeyScopePayment)
+	if len(nonce) < minRequestNonceLength || len(nonce) > maxRequestNonceLength {
+		return errors.NewUnauthorizedError(fmt.Sprintf("Request nonce must be %d to %d characters", minRequestNonceLength, maxRequestNonceLength))
 	}
 
-	// Add WITHDRAWAL scope for withdrawal endpoints
-	if c.Path() == "/api/v1/withdrawals" {
-		requiredScopes = append(requiredScopes, models.APIKeyScopeWithdrawal)
+	expected := services.SignRequest(*apiKey.SigningSecret, c.Method(), c.OriginalURL(), timestamp, nonce, c.Body())
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signature))) {
+		return errors.NewUnauthorizedError("Invalid request signature")
 	}
 
-	// Check if API key has all required scopes
-	for _, scope := range requiredScopes {
-		if !key.HasScope(scope) {
-			return false
+	// Nonces are kept for twice the allowed skew, longer than any timestamp is accepted
+	nonceKey := fmt.Sprintf("%s:apikey:nonce:%s:%s", m.keyPrefix, apiKey.ID, nonce)
+	fresh, err := m.redis.SetNX(context.Background(), nonceKey, 1, 2*requestSignatureMaxSkew).Result()
+	if err != nil {
+		return errors.NewInternalServerError(err, "Failed to verify request nonce")
+	}
+	if !fresh {
+		return errors.NewUnauthorizedError("Request nonce has already been used")
+	}
+
+	return nil
+}
+
+// isAllowedIP reports whether ip falls in one of the allowed CIDR ranges. An empty allowlist
+// accepts any address.
+func isAllowedIP(allowedCIDRs []string, ip string) bool {
+	if len(allowedCIDRs) == 0 {
+		return true
+	}
+
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, cidr := range allowedCIDRs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequireScopes declares the scopes an API key needs to call a route. Attach it to every route
+// behind RequireAPIKey or AuthAPIKey; CheckRouteScopes fails startup for routes that don't.
+// Requests authenticated without an API key are not affected.
+func (m *APIKeyMiddleware) RequireScopes(scopes ...models.APIKeyScope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		apiKey, ok := c.Locals("api_key").(*models.MerchantAPIKey)
+		if !ok {
+			return c.Next()
 		}
+
+		for _, scope := range scopes {
+			if !apiKey.HasScope(scope) {
+				return pkg.ErrorResponse(c, errors.NewForbiddenError(fmt.Sprintf("API key is missing the %s scope", scope)))
+			}
+		}
+
+		return c.Next()
 	}
+}
+
+// CheckRouteScopes verifies that every route reachable with an API key declares its scopes
+// with RequireScopes. The API key middleware can be attached to the route itself or to a group.
+func (m *APIKeyMiddleware) CheckRouteScopes(app *fiber.App) error {
+	authHandlers := []uintptr{handlerPointer(m.AuthAPIKey), handlerPointer(m.RequireAPIKey)}
+	scopeHandler := handlerPointer(m.RequireScopes())
+
+	// Group middleware is registered as separate "use" routes matching a path prefix
+	routes := app.GetRoutes(true)
+	var authPrefixes []fiber.Route
+	for _, route := range app.GetRoutes(false) {
+		if containsHandler(route.Handlers, authHandlers...) {
+			authPrefixes = append(authPrefixes, route)
+		}
+	}
+
+	var undeclared []string
+	for _, route := range routes {
+		authenticated := containsHandler(route.Handlers, authHandlers...)
+		for _, prefix := range authPrefixes {
+			if prefix.Method == route.Method && matchesPrefix(route.Path, prefix.Path) {
+				authenticated = true
+				break
+			}
+		}
+
+		if authenticated && !containsHandler(route.Handlers, scopeHandler) {
+			undeclared = append(undeclared, route.Method+" "+route.Path)
+		}
+	}
+
+	if len(undeclared) > 0 {
+		return fmt.Errorf("API key routes without declared scopes: %s", strings.Join(undeclared, ", "))
+	}
+
+	return nil
+}
+
+// handlerPointer returns the code pointer of a handler. Method values of the same method, and
+// closures created by the same function, share a code pointer.
+func handlerPointer(handler fiber.Handler) uintptr {
+	return reflect.ValueOf(handler).Pointer()
+}
+
+// containsHandler reports whether any of the handlers has one of the given code pointers
+func containsHandler(handlers []fiber.Handler, pointers ...uintptr) bool {
+	for _, handler := range handlers {
+		for _, pointer := range pointers {
+			if handlerPointer(handler) == pointer {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-	return true
+// matchesPrefix reports whether a route path falls under a "use" route prefix
+func matchesPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
 }
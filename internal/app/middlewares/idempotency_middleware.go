@@ -0,0 +1,142 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/app/services"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the client-chosen key
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set on responses replayed from a stored key
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// completeAttempts is how often storing a response is tried before the key is released
+	completeAttempts = 3
+)
+
+// IdempotencyMiddleware replays the stored response when a mutating request is retried
+// with the same Idempotency-Key
+type IdempotencyMiddleware struct {
+	idempotencyService *services.IdempotencyService
+}
+
+// NewIdempotencyMiddleware creates a new IdempotencyMiddleware
+func NewIdempotencyMiddleware(idempotencyService *services.IdempotencyService) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		idempotencyService: idempotencyService,
+	}
+}
+
+// Idempotent must run after authentication. Requests without the header, and GET/HEAD/OPTIONS
+// requests, pass through unchanged. The first response below 500 is stored for 24 hours.
+func (m *IdempotencyMiddleware) Idempotent(c *fiber.Ctx) error {
+	switch c.Method() {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+	default:
+		return c.Next()
+	}
+
+	key := c.Get(IdempotencyKeyHeader)
+	if key == "" {
+		return c.Next()
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		return pkg.ErrorResponse(c, errors.NewBadRequestError("Idempotency-Key must be at most 255 characters"))
+	}
+
+	scope := idempotencyScope(c)
+	if scope == "" {
+		return c.Next()
+	}
+
+	record, isNew, err := m.idempotencyService.Begin(c.Context(), scope, key, c.Method(), c.Path(), requestHash(c))
+	if err != nil {
+		return pkg.ErrorResponse(c, err)
+	}
+
+	if !isNew {
+		c.Set(IdempotentReplayedHeader, "true")
+		if record.ResponseContentType != nil {
+			c.Set(fiber.HeaderContentType, *record.ResponseContentType)
+		}
+		return c.Status(*record.ResponseStatus).Send(record.ResponseBody)
+	}
+
+	if err := c.Next(); err != nil {
+		m.release(record)
+		return err
+	}
+
+	status := c.Response().StatusCode()
+	if status >= fiber.StatusInternalServerError {
+		m.release(record)
+		return nil
+	}
+
+	// The response buffer is reused by fasthttp, so store a copy
+	body := append([]byte(nil), c.Response().Body()...)
+	contentType := string(c.Response().Header.ContentType())
+	m.complete(record, status, contentType, body)
+
+	return nil
+}
+
+// complete stores the response, retrying briefly. When it cannot be stored the key is released
+// rather than left PROCESSING, so retries are not rejected until the lease runs out.
+func (m *IdempotencyMiddleware) complete(record *models.IdempotencyKey, status int, contentType string, body []byte) {
+	var err error
+	for attempt := 1; attempt <= completeAttempts; attempt++ {
+		if err = m.idempotencyService.Complete(context.Background(), record, status, contentType, body); err == nil {
+			return
+		}
+		if attempt < completeAttempts {
+			time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+		}
+	}
+
+	logrus.WithField("idempotency_key", record.Key).WithError(err).Error("Failed to store idempotent response")
+	m.release(record)
+}
+
+// release frees the key so the client can retry after a failed request
+func (m *IdempotencyMiddleware) release(record *models.IdempotencyKey) {
+	if err := m.idempotencyService.Release(context.Background(), record); err != nil {
+		logrus.WithField("idempotency_key", record.Key).WithError(err).Error("Failed to release idempotency key")
+	}
+}
+
+// idempotencyScope namespaces keys by the authenticated caller
+func idempotencyScope(c *fiber.Ctx) string {
+	if apiKey, ok := c.Locals("api_key").(*models.MerchantAPIKey); ok && apiKey != nil {
+		return "apikey:" + apiKey.ID.String()
+	}
+	if account, ok := c.Locals("account").(*models.Account); ok && account != nil {
+		return "account:" + account.ConnectID.String()
+	}
+	if connectUser, ok := c.Locals("connect_user").(*models.ConnectUser); ok && connectUser != nil {
+		return "account:" + connectUser.ID.String()
+	}
+	return ""
+}
+
+// requestHash fingerprints the method, path and body of a request
+func requestHash(c *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(c.Method()))
+	hash.Write([]byte{'\n'})
+	hash.Write([]byte(c.Path()))
+	hash.Write([]byte{'\n'})
+	hash.Write(c.Body())
+	return hex.EncodeToString(hash.Sum(nil))
+}
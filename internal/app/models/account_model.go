@@ -22,6 +22,10 @@ const (
 	AccountStatusActive    AccountStatus = "ACTIVE"
 	AccountStatusSuspended AccountStatus = "SUSPENDED"
 	AccountStatusBlocked   AccountStatus = "BLOCKED"
+	// AccountStatusClosing is the cooling-off period after the owner asked to close the account
+	AccountStatusClosing AccountStatus = "CLOSING"
+	// AccountStatusClosed accounts are anonymised and soft-deleted
+	AccountStatusClosed AccountStatus = "CLOSED"
 )
 
 // KYCStatus represents the KYC verification status
@@ -34,17 +38,53 @@ const (
 	KYCStatusRejected   KYCStatus = "REJECTED"
 )
 
+// Account holds a GSALT wallet. Balance is the ledger balance; HeldBalance is reserved by
+// active balance holds and AvailableBalance (balance - held_balance) is what can be spent.
 type Account struct {
-	ConnectID      uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"connect_id"`
-	Balance        int64          `json:"balance"`
-	Points         int64          `json:"points"`
-	AccountType    AccountType    `json:"account_type"`
-	Status         AccountStatus  `json:"status"`
-	KYCStatus      KYCStatus      `json:"kyc_status"`
-	DailyLimit     *int64         `json:"daily_limit"`
-	MonthlyLimit   *int64         `json:"monthly_limit"`
-	LastActivityAt *time.Time     `json:"last_activity_at"`
-	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	ConnectID          uuid.UUID      `gorm:"type:uuid;primaryKey;default:gen_random_uuid()" json:"connect_id"`
+	Balance            int64          `json:"balance"`
+	HeldBalance        int64          `json:"held_balance"`
+	AvailableBalance   int64          `gorm:"->" json:"available_balance"`
+	Points             int64          `json:"points"`
+	AccountType        AccountType    `json:"account_type"`
+	Status             AccountStatus  `json:"status"`
+	StatusReason       *string        `json:"status_reason,omitempty"`
+	StatusChangedAt    *time.Time     `json:"status_changed_at,omitempty"`
+	ClosureRequestedAt *time.Time     `json:"closure_requested_at,omitempty"`
+	ClosureScheduledAt *time.Time     `json:"closure_scheduled_at,omitempty"`
+	ClosedAt           *time.Time     `json:"closed_at,omitempty"`
+	KYCStatus          KYCStatus      `json:"kyc_status"`
+	KYCLevel           KYCLevel       `gorm:"default:NONE" json:"kyc_level"`
+	DailyLimit         *int64         `json:"daily_limit"`
+	MonthlyLimit       *int64         `json:"monthly_limit"`
+	LastActivityAt     *time.Time     `json:"last_activity_at"`
+	CreatedAt          time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt          gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+// BalanceDisposition is what happens to the remaining balance of an account being closed
+type BalanceDisposition string
+
+const (
+	// BalanceDispositionDonate gives the remaining balance away as a DONATION transaction
+	BalanceDispositionDonate BalanceDisposition = "DONATE"
+)
+
+// AccountClosureRequest asks to close the caller's account. A remaining balance must be
+// withdrawn first, or donated with balance_disposition DONATE.
+type AccountClosureRequest struct {
+	Reason             *string             `json:"reason,omitempty" validate:"omitempty,max=500"`
+	BalanceDisposition *BalanceDisposition `json:"balance_disposition,omitempty" validate:"omitempty,oneof=DONATE"`
+}
+
+// AccountClosure describes the closure of an account
+type AccountClosure struct {
+	Status             AccountStatus `json:"status"`
+	Reason             *string       `json:"reason,omitempty"`
+	ClosureRequestedAt *time.Time    `json:"closure_requested_at,omitempty"`
+	ClosureScheduledAt *time.Time    `json:"closure_scheduled_at,omitempty"`
+	Balance            int64         `json:"balance"`
+	HeldBalance        int64         `json:"held_balance"`
+	DonationID         *uuid.UUID    `json:"donation_transaction_id,omitempty"`
 }
@@ -0,0 +1,35 @@
+package models
+
+// AdjustmentDirection tells whether a balance adjustment adds to or takes from a wallet
+type AdjustmentDirection string
+
+const (
+	AdjustmentDirectionCredit AdjustmentDirection = "CREDIT"
+	AdjustmentDirectionDebit  AdjustmentDirection = "DEBIT"
+)
+
+// AdminAccountFilter narrows the account list of the back office
+type AdminAccountFilter struct {
+	Status      *AccountStatus `query:"status" validate:"omitempty,oneof=ACTIVE SUSPENDED BLOCKED"`
+	AccountType *AccountType   `query:"account_type" validate:"omitempty,oneof=PERSONAL MERCHANT"`
+	KYCStatus   *KYCStatus     `query:"kyc_status" validate:"omitempty,oneof=UNVERIFIED PENDING VERIFIED REJECTED"`
+}
+
+// AdminAccountDetail is an account with its Connect user. ConnectUser is nil when Connect
+// could not be reached.
+type AdminAccountDetail struct {
+	Account     *Account     `json:"account"`
+	ConnectUser *ConnectUser `json:"connect_user,omitempty"`
+}
+
+// AccountStatusChangeRequest suspends, blocks or reactivates an account
+type AccountStatusChangeRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// BalanceAdjustmentRequest corrects an account balance outside of the normal transaction flows
+type BalanceAdjustmentRequest struct {
+	AmountGsalt string              `json:"amount_gsalt" validate:"required,numeric,gt=0"`
+	Direction   AdjustmentDirection `json:"direction" validate:"required,oneof=CREDIT DEBIT"`
+	Reason      string              `json:"reason" validate:"required,max=500"`
+}
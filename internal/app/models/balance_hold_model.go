@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BalanceHoldStatus represents the lifecycle of a balance hold
+type BalanceHoldStatus string
+
+const (
+	BalanceHoldStatusActive   BalanceHoldStatus = "ACTIVE"
+	BalanceHoldStatusCaptured BalanceHoldStatus = "CAPTURED"
+	BalanceHoldStatusReleased BalanceHoldStatus = "RELEASED"
+)
+
+// BalanceHold reserves part of an account balance for a pending money movement.
+// While ACTIVE its amount counts towards accounts.held_balance and cannot be spent.
+// Capturing debits up to the held amount and returns the rest; releasing returns all of it.
+type BalanceHold struct {
+	ID             uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	AccountID      uuid.UUID         `json:"account_id" gorm:"type:uuid;not null"`
+	TransactionID  *uuid.UUID        `json:"transaction_id,omitempty" gorm:"type:uuid"`
+	Reference      string            `json:"reference" gorm:"type:varchar(255);not null"`
+	Description    *string           `json:"description,omitempty" gorm:"type:text"`
+	Amount         int64             `json:"amount" gorm:"type:bigint;not null"`
+	CapturedAmount int64             `json:"captured_amount" gorm:"type:bigint;not null;default:0"`
+	Status         BalanceHoldStatus `json:"status" gorm:"type:varchar(20);not null"`
+	CapturedAt     *time.Time        `json:"captured_at,omitempty" gorm:"type:timestamp with time zone"`
+	ReleasedAt     *time.Time        `json:"released_at,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt      time.Time         `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt      time.Time         `json:"updated_at" gorm:"type:timestamp with time zone;autoUpdateTime"`
+}
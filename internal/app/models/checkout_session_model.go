@@ -0,0 +1,144 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CheckoutSessionStatus represents the lifecycle of a checkout session
+type CheckoutSessionStatus string
+
+const (
+	CheckoutSessionStatusOpen CheckoutSessionStatus = "OPEN"
+	// CheckoutSessionStatusAuthorized sessions hold the payer's balance until the merchant
+	// captures or voids them (manual capture only)
+	CheckoutSessionStatusAuthorized CheckoutSessionStatus = "AUTHORIZED"
+	CheckoutSessionStatusCompleted  CheckoutSessionStatus = "COMPLETED"
+	CheckoutSessionStatusCancelled  CheckoutSessionStatus = "CANCELLED"
+	CheckoutSessionStatusExpired    CheckoutSessionStatus = "EXPIRED"
+)
+
+// CheckoutCaptureMethod decides when an approved session moves the funds
+type CheckoutCaptureMethod string
+
+const (
+	// CheckoutCaptureMethodAutomatic sessions are paid as soon as the payer approves them
+	CheckoutCaptureMethodAutomatic CheckoutCaptureMethod = "AUTOMATIC"
+	// CheckoutCaptureMethodManual sessions only place a balance hold on approval; the merchant
+	// captures all or part of it later, or voids it
+	CheckoutCaptureMethodManual CheckoutCaptureMethod = "MANUAL"
+)
+
+// CheckoutSession is a merchant's request to be paid in GSALT. The merchant creates it with an
+// API key, the payer approves it with their Connect token, and on approval the amount moves
+// from the payer to the merchant wallet minus the merchant fee. Sessions created with a test key
+// are paid on the sandbox ledger and have no transaction. With manual capture the approval only
+// holds the amount; AmountGsaltUnits becomes the captured amount and the original amount is
+// kept in AuthorizedAmountGsaltUnits.
+type CheckoutSession struct {
+	ID                         uuid.UUID             `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	MerchantID                 uuid.UUID             `json:"merchant_id" gorm:"type:uuid;not null"`
+	APIKeyID                   uuid.UUID             `json:"api_key_id" gorm:"type:uuid;not null"`
+	Environment                APIKeyEnvironment     `json:"environment" gorm:"type:varchar(10);not null;default:LIVE"`
+	ReferenceID                *string               `json:"reference_id,omitempty" gorm:"type:varchar(255)"`
+	AmountGsaltUnits           int64                 `json:"amount_gsalt_units" gorm:"type:bigint;not null"`
+	FeeGsaltUnits              int64                 `json:"fee_gsalt_units" gorm:"type:bigint;not null;default:0"`
+	NetAmountGsaltUnits        int64                 `json:"net_amount_gsalt_units" gorm:"type:bigint;not null"`
+	Currency                   string                `json:"currency" gorm:"type:varchar(10);not null;default:'GSALT'"`
+	Description                *string               `json:"description,omitempty" gorm:"type:text"`
+	Items                      json.RawMessage       `json:"items" gorm:"type:jsonb"`
+	RedirectURL                string                `json:"redirect_url" gorm:"type:text;not null"`
+	SigningSecret              string                `json:"-" gorm:"type:varchar(64);not null"`
+	Status                     CheckoutSessionStatus `json:"status" gorm:"type:varchar(20);not null"`
+	CaptureMethod              CheckoutCaptureMethod `json:"capture_method" gorm:"type:varchar(20);not null;default:AUTOMATIC"`
+	PayerAccountID             *uuid.UUID            `json:"payer_account_id,omitempty" gorm:"type:uuid"`
+	TransactionID              *uuid.UUID            `json:"transaction_id,omitempty" gorm:"type:uuid"`
+	JournalEntryID             *uuid.UUID            `json:"journal_entry_id,omitempty" gorm:"type:uuid"`
+	HoldID                     *uuid.UUID            `json:"hold_id,omitempty" gorm:"type:uuid"`
+	AuthorizedAmountGsaltUnits *int64                `json:"authorized_amount_gsalt_units,omitempty" gorm:"type:bigint"`
+	AuthorizedAt               *time.Time            `json:"authorized_at,omitempty" gorm:"type:timestamp with time zone"`
+	AuthorizationExpiresAt     *time.Time            `json:"authorization_expires_at,omitempty" gorm:"type:timestamp with time zone"`
+	ExpiresAt                  time.Time             `json:"expires_at" gorm:"type:timestamp with time zone;not null"`
+	CompletedAt                *time.Time            `json:"completed_at,omitempty" gorm:"type:timestamp with time zone"`
+	CancelledAt                *time.Time            `json:"cancelled_at,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt                  time.Time             `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt                  time.Time             `json:"updated_at" gorm:"type:timestamp with time zone;autoUpdateTime"`
+}
+
+// IsExpired reports whether an open session can no longer be approved
+func (s *CheckoutSession) IsExpired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+// CheckoutSessionItem is a line item stored with a session, amounts in GSALT units
+type CheckoutSessionItem struct {
+	Name                 string  `json:"name"`
+	Quantity             int     `json:"quantity"`
+	UnitAmountGsaltUnits int64   `json:"unit_amount_gsalt_units"`
+	ImageURL             *string `json:"image_url,omitempty"`
+}
+
+// CheckoutItemRequest is a line item of a checkout session request
+type CheckoutItemRequest struct {
+	Name            string  `json:"name" validate:"required,max=255"`
+	Quantity        int     `json:"quantity" validate:"required,min=1"`
+	UnitAmountGsalt string  `json:"unit_amount_gsalt" validate:"required,numeric,gt=0"`
+	ImageURL        *string `json:"image_url,omitempty" validate:"omitempty,url"`
+}
+
+// CheckoutSessionCreateRequest creates a checkout session. When items are given their total
+// must equal the amount.
+type CheckoutSessionCreateRequest struct {
+	AmountGsalt      string                `json:"amount_gsalt" validate:"required,numeric,gt=0"`
+	ReferenceID      *string               `json:"reference_id,omitempty" validate:"omitempty,max=255"`
+	Description      *string               `json:"description,omitempty" validate:"omitempty,max=500"`
+	Items            []CheckoutItemRequest `json:"items,omitempty" validate:"omitempty,max=100,dive"`
+	RedirectURL      string                `json:"redirect_url" validate:"required,url"`
+	ExpiresInMinutes *int                  `json:"expires_in_minutes,omitempty" validate:"omitempty,min=5,max=1440"`
+	CaptureMethod    CheckoutCaptureMethod `json:"capture_method,omitempty" validate:"omitempty,oneof=AUTOMATIC MANUAL"`
+}
+
+// CheckoutCaptureRequest captures an authorized session. Without amount_gsalt the full
+// authorized amount is captured; a smaller amount releases the rest of the hold.
+type CheckoutCaptureRequest struct {
+	AmountGsalt *string `json:"amount_gsalt,omitempty" validate:"omitempty,numeric,gt=0"`
+}
+
+// CheckoutSessionCreateResponse returns the session with its signing secret. The secret is
+// only shown once and verifies the signature of the checkout result.
+type CheckoutSessionCreateResponse struct {
+	CheckoutSession
+	SigningSecret string `json:"signing_secret"`
+}
+
+// CheckoutResult is the signed outcome of a session, returned to the payer together with the
+// merchant redirect URL carrying the same fields as query parameters. Signature is the hex
+// HMAC-SHA256 of "session_id.status.transaction_id.amount_gsalt_units.timestamp" keyed with
+// the session signing secret.
+type CheckoutResult struct {
+	SessionID        uuid.UUID             `json:"session_id"`
+	ReferenceID      *string               `json:"reference_id,omitempty"`
+	Status           CheckoutSessionStatus `json:"status"`
+	TransactionID    *uuid.UUID            `json:"transaction_id,omitempty"`
+	AmountGsaltUnits int64                 `json:"amount_gsalt_units"`
+	Timestamp        int64                 `json:"timestamp"`
+	Signature        string                `json:"signature"`
+	RedirectURL      string                `json:"redirect_url"`
+}
+
+// CheckoutSessionPayerView is what the payer sees before approving a session
+type CheckoutSessionPayerView struct {
+	ID               uuid.UUID              `json:"id"`
+	MerchantID       uuid.UUID              `json:"merchant_id"`
+	Merchant         *MerchantPublicProfile `json:"merchant,omitempty"`
+	ReferenceID      *string                `json:"reference_id,omitempty"`
+	AmountGsaltUnits int64                  `json:"amount_gsalt_units"`
+	Currency         string                 `json:"currency"`
+	Description      *string                `json:"description,omitempty"`
+	Items            json.RawMessage        `json:"items"`
+	Status           CheckoutSessionStatus  `json:"status"`
+	Environment      APIKeyEnvironment      `json:"environment"`
+	ExpiresAt        time.Time              `json:"expires_at"`
+}
@@ -110,6 +110,15 @@ type CreateBillRequest struct {
 	ItemDetails           []ItemDetail `json:"item_details,omitempty"`
 }
 
+// EditBillRequest represents the request to edit an existing bill. Empty fields are left unchanged.
+type EditBillRequest struct {
+	Title       string `json:"title,omitempty" validate:"omitempty,max=255"`
+	Amount      int64  `json:"amount,omitempty" validate:"omitempty,min=100"`
+	ExpiredDate string `json:"expired_date,omitempty"`
+	RedirectURL string `json:"redirect_url,omitempty" validate:"omitempty,url"`
+	Status      string `json:"status,omitempty" validate:"omitempty,oneof=ACTIVE INACTIVE"` // INACTIVE stops the bill from accepting payments
+}
+
 // ItemDetail represents product/service details in the bill
 type ItemDetail struct {
 	ID       string `json:"id,omitempty" validate:"omitempty,max=100"`
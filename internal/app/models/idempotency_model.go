@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyKeyStatus tracks whether the request behind a key has finished
+type IdempotencyKeyStatus string
+
+const (
+	IdempotencyKeyStatusProcessing IdempotencyKeyStatus = "PROCESSING"
+	IdempotencyKeyStatusCompleted  IdempotencyKeyStatus = "COMPLETED"
+)
+
+// IdempotencyKey stores the first response for an Idempotency-Key so retries can be replayed.
+// Keys are unique per scope (the authenticated account or API key). A PROCESSING key is leased
+// until LockedUntil; after that a retry may claim it again.
+type IdempotencyKey struct {
+	ID                  uuid.UUID            `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Scope               string               `json:"scope" gorm:"type:varchar(100);not null"`
+	Key                 string               `json:"key" gorm:"type:varchar(255);not null"`
+	RequestMethod       string               `json:"request_method" gorm:"type:varchar(10);not null"`
+	RequestPath         string               `json:"request_path" gorm:"type:text;not null"`
+	RequestHash         string               `json:"request_hash" gorm:"type:varchar(64);not null"`
+	Status              IdempotencyKeyStatus `json:"status" gorm:"type:varchar(20);not null"`
+	ResponseStatus      *int                 `json:"response_status,omitempty" gorm:"type:integer"`
+	ResponseContentType *string              `json:"response_content_type,omitempty" gorm:"type:varchar(100)"`
+	ResponseBody        []byte               `json:"-" gorm:"type:bytea"`
+	CreatedAt           time.Time            `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	CompletedAt         *time.Time           `json:"completed_at,omitempty" gorm:"type:timestamp with time zone"`
+	LockedUntil         *time.Time           `json:"locked_until,omitempty" gorm:"type:timestamp with time zone"`
+	ExpiresAt           time.Time            `json:"expires_at" gorm:"type:timestamp with time zone;not null"`
+}
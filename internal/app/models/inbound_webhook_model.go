@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboundWebhookEventType identifies what kind of provider callback an event carries
+type InboundWebhookEventType string
+
+const (
+	InboundWebhookEventTypePayment      InboundWebhookEventType = "PAYMENT"
+	InboundWebhookEventTypeDisbursement InboundWebhookEventType = "DISBURSEMENT"
+)
+
+// InboundWebhookEventStatus tracks how far an inbound callback has been processed
+type InboundWebhookEventStatus string
+
+const (
+	InboundWebhookEventStatusReceived  InboundWebhookEventStatus = "RECEIVED"
+	InboundWebhookEventStatusProcessed InboundWebhookEventStatus = "PROCESSED"
+	InboundWebhookEventStatusRejected  InboundWebhookEventStatus = "REJECTED"
+	InboundWebhookEventStatusFailed    InboundWebhookEventStatus = "FAILED"
+)
+
+// InboundWebhookEvent records every verified provider callback.
+// (provider, event_type, external_id) is unique so replays are processed at most once.
+type InboundWebhookEvent struct {
+	ID                uuid.UUID                 `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Provider          string                    `json:"provider" gorm:"type:varchar(50);not null"`
+	EventType         InboundWebhookEventType   `json:"event_type" gorm:"type:varchar(20);not null"`
+	ExternalID        string                    `json:"external_id" gorm:"type:varchar(255);not null"`
+	ProviderPaymentID *string                   `json:"provider_payment_id,omitempty" gorm:"type:varchar(255)"`
+	TransactionID     *uuid.UUID                `json:"transaction_id,omitempty" gorm:"type:uuid"`
+	PaymentStatus     PaymentStatus             `json:"payment_status" gorm:"type:varchar(20);not null"`
+	Amount            int64                     `json:"amount" gorm:"type:bigint;not null;default:0"`
+	Status            InboundWebhookEventStatus `json:"status" gorm:"type:varchar(20);not null;default:RECEIVED"`
+	ErrorMessage      *string                   `json:"error_message,omitempty" gorm:"type:text"`
+	Payload           json.RawMessage           `json:"payload" gorm:"type:jsonb"`
+	ReceivedAt        time.Time                 `json:"received_at" gorm:"type:timestamp with time zone;not null"`
+	ProcessedAt       *time.Time                `json:"processed_at,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt         time.Time                 `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt         time.Time                 `json:"updated_at" gorm:"type:timestamp with time zone;autoUpdateTime"`
+}
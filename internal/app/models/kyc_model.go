@@ -0,0 +1,116 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// KYCLevel is the verification tier of an account. Higher tiers need more identity evidence
+// and can be given higher transaction limits.
+type KYCLevel string
+
+const (
+	KYCLevelNone  KYCLevel = "NONE"
+	KYCLevelBasic KYCLevel = "BASIC"
+	KYCLevelFull  KYCLevel = "FULL"
+)
+
+// Rank orders the levels from NONE (0) to FULL (2)
+func (l KYCLevel) Rank() int {
+	switch l {
+	case KYCLevelBasic:
+		return 1
+	case KYCLevelFull:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// KYCSubmissionStatus represents the review state of a KYC submission
+type KYCSubmissionStatus string
+
+const (
+	KYCSubmissionStatusPending  KYCSubmissionStatus = "PENDING"
+	KYCSubmissionStatusApproved KYCSubmissionStatus = "APPROVED"
+	KYCSubmissionStatusRejected KYCSubmissionStatus = "REJECTED"
+)
+
+// KYCIDType is the kind of identity document of a submission
+type KYCIDType string
+
+const (
+	KYCIDTypeNationalID    KYCIDType = "NATIONAL_ID"
+	KYCIDTypePassport      KYCIDType = "PASSPORT"
+	KYCIDTypeDriverLicense KYCIDType = "DRIVER_LICENSE"
+)
+
+// KYCSubmission is an account's request to be verified at a KYC level. Documents are stored
+// elsewhere; the submission keeps references to them.
+type KYCSubmission struct {
+	ID                      uuid.UUID           `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	AccountID               uuid.UUID           `json:"account_id" gorm:"type:uuid;not null"`
+	Level                   KYCLevel            `json:"level" gorm:"type:varchar(10);not null"`
+	Status                  KYCSubmissionStatus `json:"status" gorm:"type:varchar(20);not null"`
+	FullName                string              `json:"full_name" gorm:"type:varchar(255);not null"`
+	DateOfBirth             time.Time           `json:"date_of_birth" gorm:"type:date;not null"`
+	Nationality             string              `json:"nationality" gorm:"type:varchar(2);not null"`
+	IDType                  KYCIDType           `json:"id_type" gorm:"type:varchar(20);not null"`
+	IDNumber                string              `json:"id_number" gorm:"type:varchar(50);not null"`
+	Address                 *string             `json:"address,omitempty" gorm:"type:text"`
+	IDDocumentReference     string              `json:"id_document_reference" gorm:"type:varchar(500);not null"`
+	SelfieReference         *string             `json:"selfie_reference,omitempty" gorm:"type:varchar(500)"`
+	ProofOfAddressReference *string             `json:"proof_of_address_reference,omitempty" gorm:"type:varchar(500)"`
+	ReviewedBy              *uuid.UUID          `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewReason            *string             `json:"review_reason,omitempty" gorm:"type:text"`
+	ReviewedAt              *time.Time          `json:"reviewed_at,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt               time.Time           `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt               time.Time           `json:"updated_at" gorm:"type:timestamp with time zone;autoUpdateTime"`
+}
+
+// KYCStatusHistory records every change of an account's KYC status or level
+type KYCStatusHistory struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	AccountID    uuid.UUID  `json:"account_id" gorm:"type:uuid;not null"`
+	SubmissionID *uuid.UUID `json:"submission_id,omitempty" gorm:"type:uuid"`
+	FromStatus   KYCStatus  `json:"from_status" gorm:"type:kyc_status;not null"`
+	ToStatus     KYCStatus  `json:"to_status" gorm:"type:kyc_status;not null"`
+	FromLevel    KYCLevel   `json:"from_level" gorm:"type:varchar(10);not null"`
+	ToLevel      KYCLevel   `json:"to_level" gorm:"type:varchar(10);not null"`
+	Reason       *string    `json:"reason,omitempty" gorm:"type:text"`
+	ChangedBy    *uuid.UUID `json:"changed_by,omitempty" gorm:"type:uuid"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+}
+
+func (KYCStatusHistory) TableName() string {
+	return "kyc_status_history"
+}
+
+// KYCSubmitRequest submits identity data for a KYC level. BASIC needs an identity document;
+// FULL also needs a selfie, an address and a proof of address.
+type KYCSubmitRequest struct {
+	Level                   KYCLevel  `json:"level" validate:"required,oneof=BASIC FULL"`
+	FullName                string    `json:"full_name" validate:"required,max=255"`
+	DateOfBirth             string    `json:"date_of_birth" validate:"required,datetime=2006-01-02"`
+	Nationality             string    `json:"nationality" validate:"required,iso3166_1_alpha2"`
+	IDType                  KYCIDType `json:"id_type" validate:"required,oneof=NATIONAL_ID PASSPORT DRIVER_LICENSE"`
+	IDNumber                string    `json:"id_number" validate:"required,alphanum,max=50"`
+	Address                 *string   `json:"address,omitempty" validate:"required_if=Level FULL,omitempty,max=1000"`
+	IDDocumentReference     string    `json:"id_document_reference" validate:"required,max=500"`
+	SelfieReference         *string   `json:"selfie_reference,omitempty" validate:"required_if=Level FULL,omitempty,max=500"`
+	ProofOfAddressReference *string   `json:"proof_of_address_reference,omitempty" validate:"required_if=Level FULL,omitempty,max=500"`
+}
+
+// KYCReviewRequest approves or rejects a KYC submission. A reason is required to reject.
+type KYCReviewRequest struct {
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
+}
+
+// KYCOverview is an account's KYC state with its latest submission and status history
+type KYCOverview struct {
+	KYCStatus        KYCStatus          `json:"kyc_status"`
+	KYCLevel         KYCLevel           `json:"kyc_level"`
+	LatestSubmission *KYCSubmission     `json:"latest_submission"`
+	History          []KYCStatusHistory `json:"history"`
+}
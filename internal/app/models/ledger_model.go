@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerAccountType represents the accounting classification of a ledger account
+type LedgerAccountType string
+
+const (
+	LedgerAccountTypeAsset     LedgerAccountType = "ASSET"
+	LedgerAccountTypeLiability LedgerAccountType = "LIABILITY"
+	LedgerAccountTypeEquity    LedgerAccountType = "EQUITY"
+	LedgerAccountTypeRevenue   LedgerAccountType = "REVENUE"
+	LedgerAccountTypeExpense   LedgerAccountType = "EXPENSE"
+)
+
+// PostingDirection represents the side of a journal entry a posting is written to
+type PostingDirection string
+
+const (
+	PostingDirectionDebit  PostingDirection = "DEBIT"
+	PostingDirectionCredit PostingDirection = "CREDIT"
+)
+
+// System ledger account codes
+const (
+	LedgerAccountCodeFeeRevenue     = "FEE_REVENUE"
+	LedgerAccountCodePromoExpense   = "PROMO_EXPENSE"
+	LedgerAccountCodeOpeningBalance = "OPENING_BALANCE"
+	// LedgerAccountCodeManualAdjustment is the counterpart of balance adjustments made by admins
+	LedgerAccountCodeManualAdjustment = "MANUAL_ADJUSTMENT"
+	// LedgerAccountCodeDonationsPayable holds balances donated by closed accounts until paid out
+	LedgerAccountCodeDonationsPayable = "DONATIONS_PAYABLE"
+	// LedgerAccountCodeSandboxFeeRevenue collects fees of test mode payments
+	LedgerAccountCodeSandboxFeeRevenue = "SANDBOX:FEE_REVENUE"
+
+	// Per-entity ledger accounts are created lazily with these code prefixes
+	LedgerAccountCodeWalletPrefix           = "WALLET:"
+	LedgerAccountCodeProviderClearingPrefix = "PROVIDER_CLEARING:"
+	// Sandbox wallets hold test mode money; they have no owner so account balances never change
+	LedgerAccountCodeSandboxWalletPrefix = "SANDBOX:WALLET:"
+)
+
+// LedgerAccount represents an account in the double-entry ledger.
+// Balance is kept on the account's normal side (debit for assets and expenses,
+// credit for liabilities, equity and revenue) and only changes through postings.
+type LedgerAccount struct {
+	ID             uuid.UUID         `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Code           string            `json:"code" gorm:"type:varchar(100);not null;unique"`
+	Name           string            `json:"name" gorm:"type:varchar(255);not null"`
+	Type           LedgerAccountType `json:"type" gorm:"type:ledger_account_type;not null"`
+	OwnerAccountID *uuid.UUID        `json:"owner_account_id,omitempty" gorm:"type:uuid"`
+	Currency       string            `json:"currency" gorm:"type:varchar(10);not null;default:GSALT"`
+	Balance        int64             `json:"balance" gorm:"type:bigint;not null;default:0"`
+	AllowNegative  bool              `json:"allow_negative" gorm:"not null;default:false"`
+	CreatedAt      time.Time         `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt      time.Time         `json:"updated_at" gorm:"type:timestamp with time zone;autoUpdateTime"`
+}
+
+// IsDebitNormal reports whether debits increase the account balance
+func (a *LedgerAccount) IsDebitNormal() bool {
+	return a.Type == LedgerAccountTypeAsset || a.Type == LedgerAccountTypeExpense
+}
+
+// JournalEntry groups a balanced set of postings that describe one money movement
+type JournalEntry struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Reference   string    `json:"reference" gorm:"type:varchar(255);not null"`
+	Description *string   `json:"description" gorm:"type:text"`
+	PostedAt    time.Time `json:"posted_at" gorm:"type:timestamp with time zone;not null"`
+	CreatedAt   time.Time `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+
+	// Relations
+	Postings []Posting `json:"postings,omitempty" gorm:"foreignKey:JournalEntryID"`
+}
+
+// Posting represents a single debit or credit against a ledger account
+type Posting struct {
+	ID              uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	JournalEntryID  uuid.UUID        `json:"journal_entry_id" gorm:"type:uuid;not null"`
+	LedgerAccountID uuid.UUID        `json:"ledger_account_id" gorm:"type:uuid;not null"`
+	Direction       PostingDirection `json:"direction" gorm:"type:posting_direction;not null"`
+	Amount          int64            `json:"amount" gorm:"type:bigint;not null"`
+	BalanceAfter    int64            `json:"balance_after" gorm:"type:bigint;not null"`
+	CreatedAt       time.Time        `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+}
+
+// JournalLine describes one side of a journal entry before it is posted
+type JournalLine struct {
+	LedgerAccountID uuid.UUID
+	Direction       PostingDirection
+	Amount          int64
+}
+
+// LedgerReconciliation compares the cached account balances with the balances derived from
+// postings and active balance holds
+type LedgerReconciliation struct {
+	AccountID          uuid.UUID `json:"account_id"`
+	LedgerAccountID    uuid.UUID `json:"ledger_account_id"`
+	AccountBalance     int64     `json:"account_balance"`
+	LedgerBalance      int64     `json:"ledger_balance"`
+	PostingsBalance    int64     `json:"postings_balance"`
+	HeldBalance        int64     `json:"held_balance"`
+	ActiveHoldsBalance int64     `json:"active_holds_balance"`
+	Balanced           bool      `json:"balanced"`
+}
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,24 +21,81 @@ const (
 	APIKeyScopeAdmin      APIKeyScope = "ADMIN"
 )
 
-// MerchantAPIKey represents a merchant API key
+// APIKeyEnvironment separates live keys from test keys. Money moved with test keys only touches
+// the sandbox ledger.
+type APIKeyEnvironment string
+
+const (
+	APIKeyEnvironmentLive APIKeyEnvironment = "LIVE"
+	APIKeyEnvironmentTest APIKeyEnvironment = "TEST"
+)
+
+// APIKeyScopes is stored as a Postgres api_key_scope[] array
+type APIKeyScopes []APIKeyScope
+
+// Scan parses a Postgres array literal such as {READ,WRITE}
+func (s *APIKeyScopes) Scan(value interface{}) error {
+	var literal string
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case string:
+		literal = v
+	case []byte:
+		literal = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into APIKeyScopes", value)
+	}
+
+	literal = strings.TrimSuffix(strings.TrimPrefix(literal, "{"), "}")
+	scopes := APIKeyScopes{}
+	if literal != "" {
+		for _, scope := range strings.Split(literal, ",") {
+			scopes = append(scopes, APIKeyScope(strings.Trim(scope, `"`)))
+		}
+	}
+	*s = scopes
+	return nil
+}
+
+// Value formats the scopes as a Postgres array literal
+func (s APIKeyScopes) Value() (driver.Value, error) {
+	scopes := make([]string, len(s))
+	for i, scope := range s {
+		scopes[i] = string(scope)
+	}
+	return "{" + strings.Join(scopes, ",") + "}", nil
+}
+
+// MerchantAPIKey represents a merchant API key. The key itself is only returned when it is
+// created or rotated; it is stored as an HMAC (KeyHash) and looked up by its public KeyID.
+// LegacyAPIKey holds the plaintext of keys created before hashing until it is hashed at startup.
+// SigningSecret keys optional HMAC request signatures, which RequireSignature makes mandatory.
+// A non-empty AllowedCIDRs only accepts requests from those networks.
 type MerchantAPIKey struct {
-	ID         uuid.UUID     `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	MerchantID uuid.UUID     `json:"merchant_id" gorm:"type:uuid;not null"`
-	KeyName    string        `json:"key_name" gorm:"type:varchar(100);not null"`
-	APIKey     string        `json:"api_key" gorm:"type:varchar(255);not null;unique"`
-	Prefix     string        `json:"prefix" gorm:"type:varchar(10);not null"`
-	Scopes     []APIKeyScope `json:"scopes" gorm:"type:api_key_scope[];not null"`
-	RateLimit  int           `json:"rate_limit" gorm:"not null;default:100"`
-	LastUsedAt *time.Time    `json:"last_used_at"`
-	ExpiresAt  *time.Time    `json:"expires_at"`
-	CreatedAt  time.Time     `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
-	UpdatedAt  time.Time     `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
-	DeletedAt  *time.Time    `json:"deleted_at"`
+	ID               uuid.UUID         `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	MerchantID       uuid.UUID         `json:"merchant_id" gorm:"type:uuid;not null"`
+	KeyName          string            `json:"key_name" gorm:"type:varchar(100);not null"`
+	KeyID            string            `json:"key_id" gorm:"type:varchar(16);not null;unique"`
+	KeyHash          string            `json:"-" gorm:"type:varchar(64)"`
+	LegacyAPIKey     *string           `json:"-" gorm:"column:api_key;type:varchar(255)"`
+	Prefix           string            `json:"prefix" gorm:"type:varchar(10);not null"`
+	Environment      APIKeyEnvironment `json:"environment" gorm:"type:varchar(10);not null;default:LIVE"`
+	AllowedCIDRs     []string          `json:"allowed_cidrs" gorm:"column:allowed_cidrs;type:jsonb;serializer:json;not null"`
+	SigningSecret    *string           `json:"-" gorm:"type:varchar(100)"`
+	RequireSignature bool              `json:"require_signature" gorm:"not null;default:false"`
+	Scopes           APIKeyScopes      `json:"scopes" gorm:"type:api_key_scope[];not null"`
+	RateLimit        int               `json:"rate_limit" gorm:"not null;default:100"`
+	LastUsedAt       *time.Time        `json:"last_used_at"`
+	ExpiresAt        *time.Time        `json:"expires_at"`
+	CreatedAt        time.Time         `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	UpdatedAt        time.Time         `json:"updated_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
+	DeletedAt        *time.Time        `json:"deleted_at"`
 
 	// Relations
 	Merchant     Account               `json:"-" gorm:"foreignkey:MerchantID;references:ConnectID"`
-	UsageHistory []MerchantAPIKeyUsage `json:"usage_history" gorm:"foreignkey:APIKeyID"`
+	UsageHistory []MerchantAPIKeyUsage `json:"usage_history,omitempty" gorm:"foreignkey:APIKeyID"`
 }
 
 // MerchantAPIKeyUsage represents an API key usage record
@@ -47,6 +107,7 @@ type MerchantAPIKeyUsage struct {
 	IPAddress  string    `json:"ip_address" gorm:"type:varchar(45);not null"`
 	UserAgent  string    `json:"user_agent" gorm:"type:varchar(255)"`
 	StatusCode int       `json:"status_code" gorm:"not null"`
+	LatencyMs  int64     `json:"latency_ms" gorm:"not null;default:0"`
 	CreatedAt  time.Time `json:"created_at" gorm:"not null;default:CURRENT_TIMESTAMP"`
 
 	// Relations
@@ -85,3 +146,64 @@ func (MerchantAPIKey) TableName() string {
 func (MerchantAPIKeyUsage) TableName() string {
 	return "merchant_api_key_usage"
 }
+
+// MerchantAPIKeyAnalytics is a row of the mv_merchant_api_key_analytics materialized view,
+// refreshed hourly by the refresh_materialized_views job
+type MerchantAPIKeyAnalytics struct {
+	APIKeyID           uuid.UUID  `json:"api_key_id"`
+	MerchantID         uuid.UUID  `json:"merchant_id"`
+	KeyName            string     `json:"key_name"`
+	Prefix             string     `json:"prefix"`
+	RateLimit          int        `json:"rate_limit"`
+	TotalRequests      int64      `json:"total_requests"`
+	SuccessfulRequests int64      `json:"successful_requests"`
+	FailedRequests     int64      `json:"failed_requests"`
+	UniqueIPs          int64      `json:"unique_ips" gorm:"column:unique_ips"`
+	FirstUsedAt        *time.Time `json:"first_used_at"`
+	LastUsedAt         *time.Time `json:"last_used_at"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	ExpiryStatus       string     `json:"expiry_status"`
+}
+
+// TableName returns the table name for GORM
+func (MerchantAPIKeyAnalytics) TableName() string {
+	return "mv_merchant_api_key_analytics"
+}
+
+// MerchantAPIKeySecretResponse returns an API key together with its secret value and request
+// signing secret. Both are only shown when the key is created or rotated.
+type MerchantAPIKeySecretResponse struct {
+	MerchantAPIKey
+	APIKey        string `json:"api_key"`
+	SigningSecret string `json:"signing_secret"`
+}
+
+// APIKeyUsageResponse combines the usage summary of a key with its recent requests
+type APIKeyUsageResponse struct {
+	Summary  *MerchantAPIKeyAnalytics           `json:"summary"`
+	Requests *Pagination[[]MerchantAPIKeyUsage] `json:"requests"`
+}
+
+// APIKeyCreateRequest creates a merchant API key. Keys without expires_at never expire, and
+// keys with require_signature reject requests that are not HMAC signed. allowed_cidrs takes
+// CIDR ranges or single IP addresses. The environment defaults to LIVE and cannot be changed.
+type APIKeyCreateRequest struct {
+	KeyName          string             `json:"key_name" validate:"required,max=100"`
+	Environment      *APIKeyEnvironment `json:"environment,omitempty" validate:"omitempty,oneof=LIVE TEST"`
+	Scopes           []APIKeyScope      `json:"scopes" validate:"required,min=1,dive,oneof=READ WRITE PAYMENT WITHDRAWAL"`
+	AllowedCIDRs     []string           `json:"allowed_cidrs,omitempty" validate:"omitempty,max=50,dive,cidr|ip"`
+	RateLimit        *int               `json:"rate_limit,omitempty" validate:"omitempty,min=1,max=10000"`
+	ExpiresAt        *time.Time         `json:"expires_at,omitempty"`
+	RequireSignature *bool              `json:"require_signature,omitempty"`
+}
+
+// APIKeyUpdateRequest changes a merchant API key. Omitted fields are left unchanged; an empty
+// allowed_cidrs list removes the allowlist.
+type APIKeyUpdateRequest struct {
+	KeyName          *string       `json:"key_name,omitempty" validate:"omitempty,max=100"`
+	Scopes           []APIKeyScope `json:"scopes,omitempty" validate:"omitempty,min=1,dive,oneof=READ WRITE PAYMENT WITHDRAWAL"`
+	AllowedCIDRs     []string      `json:"allowed_cidrs,omitempty" validate:"omitempty,max=50,dive,cidr|ip"`
+	RateLimit        *int          `json:"rate_limit,omitempty" validate:"omitempty,min=1,max=10000"`
+	ExpiresAt        *time.Time    `json:"expires_at,omitempty"`
+	RequireSignature *bool         `json:"require_signature,omitempty"`
+}
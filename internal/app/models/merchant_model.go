@@ -0,0 +1,144 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MerchantCategory is the line of business of a merchant
+type MerchantCategory string
+
+const (
+	MerchantCategoryRetail        MerchantCategory = "RETAIL"
+	MerchantCategoryFoodBeverage  MerchantCategory = "FOOD_BEVERAGE"
+	MerchantCategoryDigitalGoods  MerchantCategory = "DIGITAL_GOODS"
+	MerchantCategoryServices      MerchantCategory = "SERVICES"
+	MerchantCategoryEducation     MerchantCategory = "EDUCATION"
+	MerchantCategoryEntertainment MerchantCategory = "ENTERTAINMENT"
+	MerchantCategoryTravel        MerchantCategory = "TRAVEL"
+	MerchantCategoryOther         MerchantCategory = "OTHER"
+)
+
+// MerchantBusinessType tells a sole proprietor from a registered company
+type MerchantBusinessType string
+
+const (
+	MerchantBusinessTypeIndividual MerchantBusinessType = "INDIVIDUAL"
+	MerchantBusinessTypeCompany    MerchantBusinessType = "COMPANY"
+)
+
+// MerchantApplicationStatus represents the review state of a merchant application
+type MerchantApplicationStatus string
+
+const (
+	MerchantApplicationStatusPending  MerchantApplicationStatus = "PENDING"
+	MerchantApplicationStatusApproved MerchantApplicationStatus = "APPROVED"
+	MerchantApplicationStatusRejected MerchantApplicationStatus = "REJECTED"
+)
+
+// MerchantApplication is an account's request to become a merchant, or a merchant's request to
+// change its reviewed details. Approval makes the account a MERCHANT account and copies the
+// application into its merchant profile.
+type MerchantApplication struct {
+	ID                      uuid.UUID                 `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	AccountID               uuid.UUID                 `json:"account_id" gorm:"type:uuid;not null"`
+	BusinessName            string                    `json:"business_name" gorm:"type:varchar(255);not null"`
+	BusinessType            MerchantBusinessType      `json:"business_type" gorm:"type:varchar(20);not null"`
+	Category                MerchantCategory          `json:"category" gorm:"type:varchar(30);not null"`
+	Description             *string                   `json:"description,omitempty" gorm:"type:text"`
+	TaxID                   *string                   `json:"tax_id,omitempty" gorm:"type:varchar(50)"`
+	WebsiteURL              *string                   `json:"website_url,omitempty" gorm:"type:text"`
+	LogoURL                 *string                   `json:"logo_url,omitempty" gorm:"type:text"`
+	ContactName             string                    `json:"contact_name" gorm:"type:varchar(255);not null"`
+	ContactEmail            string                    `json:"contact_email" gorm:"type:varchar(255);not null"`
+	ContactPhone            string                    `json:"contact_phone" gorm:"type:varchar(20);not null"`
+	SettlementBankCode      string                    `json:"settlement_bank_code" gorm:"type:varchar(10);not null"`
+	SettlementAccountNumber string                    `json:"settlement_account_number" gorm:"type:varchar(50);not null"`
+	SettlementAccountHolder string                    `json:"settlement_account_holder" gorm:"type:varchar(255);not null"`
+	Status                  MerchantApplicationStatus `json:"status" gorm:"type:varchar(20);not null"`
+	ReviewedBy              *uuid.UUID                `json:"reviewed_by,omitempty" gorm:"type:uuid"`
+	ReviewReason            *string                   `json:"review_reason,omitempty" gorm:"type:text"`
+	ReviewedAt              *time.Time                `json:"reviewed_at,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt               time.Time                 `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt               time.Time                 `json:"updated_at" gorm:"type:timestamp with time zone;autoUpdateTime"`
+}
+
+// MerchantProfile is the business profile of a merchant account
+type MerchantProfile struct {
+	AccountID               uuid.UUID            `json:"account_id" gorm:"type:uuid;primaryKey"`
+	ApplicationID           uuid.UUID            `json:"application_id" gorm:"type:uuid;not null"`
+	BusinessName            string               `json:"business_name" gorm:"type:varchar(255);not null"`
+	BusinessType            MerchantBusinessType `json:"business_type" gorm:"type:varchar(20);not null"`
+	Category                MerchantCategory     `json:"category" gorm:"type:varchar(30);not null"`
+	Description             *string              `json:"description,omitempty" gorm:"type:text"`
+	TaxID                   *string              `json:"tax_id,omitempty" gorm:"type:varchar(50)"`
+	WebsiteURL              *string              `json:"website_url,omitempty" gorm:"type:text"`
+	LogoURL                 *string              `json:"logo_url,omitempty" gorm:"type:text"`
+	ContactName             string               `json:"contact_name" gorm:"type:varchar(255);not null"`
+	ContactEmail            string               `json:"contact_email" gorm:"type:varchar(255);not null"`
+	ContactPhone            string               `json:"contact_phone" gorm:"type:varchar(20);not null"`
+	SettlementBankCode      string               `json:"settlement_bank_code" gorm:"type:varchar(10);not null"`
+	SettlementAccountNumber string               `json:"settlement_account_number" gorm:"type:varchar(50);not null"`
+	SettlementAccountHolder string               `json:"settlement_account_holder" gorm:"type:varchar(255);not null"`
+	CreatedAt               time.Time            `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt               time.Time            `json:"updated_at" gorm:"type:timestamp with time zone;autoUpdateTime"`
+}
+
+// PublicProfile returns the part of the profile shown to payers
+func (p *MerchantProfile) PublicProfile() *MerchantPublicProfile {
+	return &MerchantPublicProfile{
+		MerchantID:   p.AccountID,
+		BusinessName: p.BusinessName,
+		Category:     p.Category,
+		Description:  p.Description,
+		WebsiteURL:   p.WebsiteURL,
+		LogoURL:      p.LogoURL,
+	}
+}
+
+// MerchantPublicProfile is what payers see about a merchant, e.g. at checkout
+type MerchantPublicProfile struct {
+	MerchantID   uuid.UUID        `json:"merchant_id"`
+	BusinessName string           `json:"business_name"`
+	Category     MerchantCategory `json:"category"`
+	Description  *string          `json:"description,omitempty"`
+	WebsiteURL   *string          `json:"website_url,omitempty"`
+	LogoURL      *string          `json:"logo_url,omitempty"`
+}
+
+// MerchantApplicationRequest applies for a merchant account
+type MerchantApplicationRequest struct {
+	BusinessName            string               `json:"business_name" validate:"required,max=255"`
+	BusinessType            MerchantBusinessType `json:"business_type" validate:"required,oneof=INDIVIDUAL COMPANY"`
+	Category                MerchantCategory     `json:"category" validate:"required,oneof=RETAIL FOOD_BEVERAGE DIGITAL_GOODS SERVICES EDUCATION ENTERTAINMENT TRAVEL OTHER"`
+	Description             *string              `json:"description,omitempty" validate:"omitempty,max=1000"`
+	TaxID                   *string              `json:"tax_id,omitempty" validate:"required_if=BusinessType COMPANY,omitempty,max=50"`
+	WebsiteURL              *string              `json:"website_url,omitempty" validate:"omitempty,url"`
+	LogoURL                 *string              `json:"logo_url,omitempty" validate:"omitempty,url"`
+	ContactName             string               `json:"contact_name" validate:"required,max=255"`
+	ContactEmail            string               `json:"contact_email" validate:"required,email,max=255"`
+	ContactPhone            string               `json:"contact_phone" validate:"required,e164"`
+	SettlementBankCode      string               `json:"settlement_bank_code" validate:"required,max=10"`
+	SettlementAccountNumber string               `json:"settlement_account_number" validate:"required,numeric,max=50"`
+	SettlementAccountHolder string               `json:"settlement_account_holder" validate:"required,max=255"`
+}
+
+// MerchantProfileUpdateRequest changes the display and contact details of a merchant profile.
+// The settlement bank account can only change through a new review.
+type MerchantProfileUpdateRequest struct {
+	BusinessName *string           `json:"business_name,omitempty" validate:"omitempty,max=255"`
+	Category     *MerchantCategory `json:"category,omitempty" validate:"omitempty,oneof=RETAIL FOOD_BEVERAGE DIGITAL_GOODS SERVICES EDUCATION ENTERTAINMENT TRAVEL OTHER"`
+	Description  *string           `json:"description,omitempty" validate:"omitempty,max=1000"`
+	WebsiteURL   *string           `json:"website_url,omitempty" validate:"omitempty,url"`
+	LogoURL      *string           `json:"logo_url,omitempty" validate:"omitempty,url"`
+	ContactName  *string           `json:"contact_name,omitempty" validate:"omitempty,max=255"`
+	ContactEmail *string           `json:"contact_email,omitempty" validate:"omitempty,email,max=255"`
+	ContactPhone *string           `json:"contact_phone,omitempty" validate:"omitempty,e164"`
+}
+
+// MerchantApplicationReviewRequest approves or rejects a merchant application. A reason is
+// required to reject.
+type MerchantApplicationReviewRequest struct {
+	Reason *string `json:"reason,omitempty" validate:"omitempty,max=1000"`
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WebhookEventType identifies an event sent to merchant webhook endpoints
+type WebhookEventType string
+
+const (
+	WebhookEventPaymentAuthorized   WebhookEventType = "payment.authorized"
+	WebhookEventPaymentCompleted    WebhookEventType = "payment.completed"
+	WebhookEventPaymentVoided       WebhookEventType = "payment.voided"
+	WebhookEventPaymentReversed     WebhookEventType = "payment.reversed"
+	WebhookEventRefundCompleted     WebhookEventType = "refund.completed"
+	WebhookEventWithdrawalCompleted WebhookEventType = "withdrawal.completed"
+	WebhookEventWithdrawalFailed    WebhookEventType = "withdrawal.failed"
+)
+
+// WebhookDeliveryStatus represents the lifecycle of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryStatusPending deliveries are sent, or retried, at next_attempt_at
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "SUCCEEDED"
+	// WebhookDeliveryStatusFailed deliveries ran out of retries; they can still be redelivered manually
+	WebhookDeliveryStatusFailed WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookEndpoint is a merchant URL that receives signed event notifications
+type WebhookEndpoint struct {
+	ID          uuid.UUID          `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	MerchantID  uuid.UUID          `json:"merchant_id" gorm:"type:uuid;not null"`
+	URL         string             `json:"url" gorm:"type:text;not null"`
+	Description *string            `json:"description,omitempty" gorm:"type:varchar(255)"`
+	Secret      string             `json:"-" gorm:"type:varchar(100);not null"`
+	EventTypes  []WebhookEventType `json:"event_types" gorm:"type:jsonb;serializer:json;not null"`
+	IsActive    bool               `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt   time.Time          `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt   time.Time          `json:"updated_at" gorm:"type:timestamp with time zone;autoUpdateTime"`
+	DeletedAt   gorm.DeletedAt     `json:"-" gorm:"index"`
+}
+
+// Subscribes reports whether the endpoint receives events of the given type
+func (e *WebhookEndpoint) Subscribes(eventType WebhookEventType) bool {
+	for _, t := range e.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent is an outbox entry written in the same database transaction as the change it
+// describes. Data is the event payload sent as the "data" field of the delivery body.
+type WebhookEvent struct {
+	ID         uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	MerchantID uuid.UUID        `json:"merchant_id" gorm:"type:uuid;not null"`
+	Type       WebhookEventType `json:"type" gorm:"type:varchar(50);not null"`
+	Data       json.RawMessage  `json:"data" gorm:"type:jsonb;not null"`
+	CreatedAt  time.Time        `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+}
+
+// WebhookDelivery tracks sending one event to one endpoint
+type WebhookDelivery struct {
+	ID             uuid.UUID                `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	EventID        uuid.UUID                `json:"event_id" gorm:"type:uuid;not null"`
+	EndpointID     uuid.UUID                `json:"endpoint_id" gorm:"type:uuid;not null"`
+	MerchantID     uuid.UUID                `json:"merchant_id" gorm:"type:uuid;not null"`
+	Status         WebhookDeliveryStatus    `json:"status" gorm:"type:varchar(20);not null"`
+	Attempts       int                      `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  *time.Time               `json:"next_attempt_at,omitempty" gorm:"type:timestamp with time zone"`
+	LastAttemptAt  *time.Time               `json:"last_attempt_at,omitempty" gorm:"type:timestamp with time zone"`
+	LastStatusCode *int                     `json:"last_status_code,omitempty"`
+	LastError      *string                  `json:"last_error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time               `json:"delivered_at,omitempty" gorm:"type:timestamp with time zone"`
+	CreatedAt      time.Time                `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+	UpdatedAt      time.Time                `json:"updated_at" gorm:"type:timestamp with time zone;autoUpdateTime"`
+	Event          *WebhookEvent            `json:"event,omitempty" gorm:"foreignKey:EventID"`
+	AttemptLog     []WebhookDeliveryAttempt `json:"attempt_log,omitempty" gorm:"foreignKey:DeliveryID"`
+}
+
+// WebhookDeliveryAttempt is one HTTP request made for a delivery. The start of the response
+// body is kept for support but never returned to merchants.
+type WebhookDeliveryAttempt struct {
+	ID            uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	DeliveryID    uuid.UUID `json:"delivery_id" gorm:"type:uuid;not null"`
+	AttemptNumber int       `json:"attempt_number" gorm:"not null"`
+	URL           string    `json:"url" gorm:"type:text;not null"`
+	StatusCode    *int      `json:"status_code,omitempty"`
+	ResponseBody  *string   `json:"-" gorm:"type:text"`
+	Error         *string   `json:"error,omitempty" gorm:"type:text"`
+	DurationMs    int64     `json:"duration_ms" gorm:"not null"`
+	IsManual      bool      `json:"is_manual" gorm:"not null;default:false"`
+	CreatedAt     time.Time `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+}
+
+// WebhookPayload is the JSON body POSTed to an endpoint
+type WebhookPayload struct {
+	ID        uuid.UUID        `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	CreatedAt time.Time        `json:"created_at"`
+	Data      json.RawMessage  `json:"data"`
+}
+
+// WebhookEndpointCreateRequest registers a webhook endpoint
+type WebhookEndpointCreateRequest struct {
+	URL         string             `json:"url" validate:"required,url,startswith=https://,max=2048"`
+	Description *string            `json:"description,omitempty" validate:"omitempty,max=255"`
+	EventTypes  []WebhookEventType `json:"event_types" validate:"required,min=1,dive,oneof=payment.authorized payment.completed payment.voided payment.reversed refund.completed withdrawal.completed withdrawal.failed"`
+}
+
+// WebhookEndpointUpdateRequest changes a webhook endpoint. Empty fields are left unchanged.
+type WebhookEndpointUpdateRequest struct {
+	URL         *string            `json:"url,omitempty" validate:"omitempty,url,startswith=https://,max=2048"`
+	Description *string            `json:"description,omitempty" validate:"omitempty,max=255"`
+	EventTypes  []WebhookEventType `json:"event_types,omitempty" validate:"omitempty,min=1,dive,oneof=payment.authorized payment.completed payment.voided payment.reversed refund.completed withdrawal.completed withdrawal.failed"`
+	IsActive    *bool              `json:"is_active,omitempty"`
+}
+
+// WebhookEndpointSecretResponse returns an endpoint with its signing secret. The secret is
+// only shown when the endpoint is created or its secret rotated.
+type WebhookEndpointSecretResponse struct {
+	WebhookEndpoint
+	Secret string `json:"secret"`
+}
@@ -41,6 +41,7 @@ type PaymentDetails struct {
 	ExpiryTime           *time.Time      `json:"expiry_time" gorm:"type:timestamp with time zone"`
 	PaymentTime          *time.Time      `json:"payment_time" gorm:"type:timestamp with time zone"`
 	ProviderFeeAmount    *int64          `json:"provider_fee_amount" gorm:"type:bigint"`
+	ReceiptURL           *string         `json:"receipt_url" gorm:"type:text"`
 	StatusHistory        json.RawMessage `json:"status_history" gorm:"type:jsonb"`
 	RawProviderResponse  json.RawMessage `json:"raw_provider_response" gorm:"type:jsonb"`
 	CreatedAt            time.Time       `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
@@ -91,4 +92,5 @@ type PaymentStatusUpdateRequest struct {
 	StatusDescription *string       `json:"status_description"`
 	ProviderPaymentID *string       `json:"provider_payment_id"`
 	PaymentTime       *time.Time    `json:"payment_time"`
+	ReceiptURL        *string       `json:"receipt_url"`
 }
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Provider codes as stored in payment_methods.provider_code
+const (
+	PaymentProviderFlip = "FLIP"
+)
+
+// ProviderChargeRequest is the provider-agnostic request to collect money from a payer
+type ProviderChargeRequest struct {
+	TransactionID uuid.UUID
+	Title         string
+	Amount        int64 // In payment currency, including fees
+	Currency      string
+	ReferenceID   string
+	ExpiresAt     time.Time
+	MethodCode    string // PaymentMethod.ProviderMethodCode
+	MethodType    string // PaymentMethod.ProviderMethodType
+	CustomerName  string
+	CustomerEmail string
+	CustomerPhone string
+	RedirectURL   string
+	ChargeFee     bool
+	Items         []ItemDetail
+}
+
+// ProviderChargeResponse is the result of creating a charge at a provider
+type ProviderChargeResponse struct {
+	ProviderPaymentID    string
+	PaymentURL           *string
+	QRCode               *string
+	VirtualAccountNumber *string
+	ExpiryTime           *time.Time
+	RawResponse          json.RawMessage
+}
+
+// ProviderChargeStatus is the provider's current view of a charge
+type ProviderChargeStatus struct {
+	ProviderPaymentID string
+	Status            PaymentStatus
+	Amount            int64
+	PaidAt            *time.Time
+	RawResponse       json.RawMessage
+}
+
+// ProviderWebhookRequest carries an inbound provider callback as received over HTTP
+type ProviderWebhookRequest struct {
+	ContentType string
+	Headers     map[string]string
+	Body        []byte
+}
+
+// ProviderWebhookEvent is a verified, normalized provider callback
+type ProviderWebhookEvent struct {
+	EventID           string // Unique per payment status change, used for deduplication
+	ProviderPaymentID string // Matches PaymentDetails.ProviderPaymentID
+	Status            PaymentStatus
+	Amount            int64
+	OccurredAt        *time.Time
+	RawPayload        json.RawMessage
+}
+
+// ProviderPayoutEvent is a verified, normalized payout callback
+type ProviderPayoutEvent struct {
+	EventID string // Unique per payout status change, used for deduplication
+	Amount  int64  // In IDR
+	Payout  ProviderPayoutResponse
+}
+
+// ProviderPayoutRequest is the provider-agnostic request to send money to a bank account
+type ProviderPayoutRequest struct {
+	IdempotencyKey string
+	BankCode       string
+	AccountNumber  string
+	Amount         int64 // In IDR
+	Remark         string
+}
+
+// ProviderPayoutResponse is the provider's view of a payout
+type ProviderPayoutResponse struct {
+	ProviderPayoutID string
+	IdempotencyKey   string
+	Status           PaymentStatus
+	ProviderStatus   string
+	Fee              int64
+	ReceiptURL       *string
+	Reason           *string
+	RawResponse      json.RawMessage
+}
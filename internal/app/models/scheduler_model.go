@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ScheduledJobRunStatus represents the outcome of a job run
+type ScheduledJobRunStatus string
+
+const (
+	ScheduledJobRunStatusRunning   ScheduledJobRunStatus = "RUNNING"
+	ScheduledJobRunStatusSucceeded ScheduledJobRunStatus = "SUCCEEDED"
+	ScheduledJobRunStatusFailed    ScheduledJobRunStatus = "FAILED"
+)
+
+// ScheduledJobTrigger represents what started a job run
+type ScheduledJobTrigger string
+
+const (
+	ScheduledJobTriggerSchedule ScheduledJobTrigger = "SCHEDULE"
+	ScheduledJobTriggerManual   ScheduledJobTrigger = "MANUAL"
+)
+
+// ScheduledJobRun records a single execution of a scheduled job
+type ScheduledJobRun struct {
+	ID           uuid.UUID             `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	JobName      string                `json:"job_name" gorm:"type:varchar(100);not null"`
+	Trigger      ScheduledJobTrigger   `json:"trigger" gorm:"type:varchar(20);not null"`
+	TriggeredBy  *uuid.UUID            `json:"triggered_by,omitempty" gorm:"type:uuid"`
+	InstanceID   string                `json:"instance_id" gorm:"type:varchar(100);not null"`
+	Status       ScheduledJobRunStatus `json:"status" gorm:"type:varchar(20);not null"`
+	ErrorMessage *string               `json:"error_message,omitempty" gorm:"type:text"`
+	StartedAt    time.Time             `json:"started_at" gorm:"type:timestamp with time zone;not null"`
+	FinishedAt   *time.Time            `json:"finished_at,omitempty" gorm:"type:timestamp with time zone"`
+	DurationMs   *int64                `json:"duration_ms,omitempty" gorm:"type:bigint"`
+	CreatedAt    time.Time             `json:"created_at" gorm:"type:timestamp with time zone;autoCreateTime"`
+}
+
+// ScheduledJobInfo describes a registered job for the admin API
+type ScheduledJobInfo struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Spec        string           `json:"spec"`
+	Timeout     string           `json:"timeout"`
+	Running     bool             `json:"running"`
+	NextRunAt   *time.Time       `json:"next_run_at,omitempty"`
+	LastRun     *ScheduledJobRun `json:"last_run,omitempty"`
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SystemConfiguration is a JSON setting stored in system_configurations, identified by its
+// category and key
+type SystemConfiguration struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	Category    string    `json:"category" gorm:"type:varchar(50);not null"`
+	Key         string    `json:"key" gorm:"type:varchar(100);not null"`
+	Value       string    `json:"value" gorm:"type:jsonb;not null"`
+	Description *string   `json:"description"`
+	IsActive    bool      `json:"is_active" gorm:"not null;default:true"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Transaction limit settings in system_configurations
+const (
+	ConfigCategoryTransactionLimits    = "transaction_limits"
+	ConfigKeyDefaultLimits             = "default_limits"
+	ConfigKeyAccountTypeLimitOverrides = "account_type_overrides"
+	ConfigKeyKYCLimitOverrides         = "kyc_overrides"
+	ConfigKeyKYCLevelLimitOverrides    = "kyc_level_overrides"
+)
+
+// TransactionLimits are the amount limits applied to an account, in GSALT units
+// (100 units = 1 GSALT). Withdrawals use the transfer limits.
+type TransactionLimits struct {
+	MinTopupAmount       int64 `json:"min_topup_gsalt" validate:"required,gt=0"`
+	MaxTopupAmount       int64 `json:"max_topup_gsalt" validate:"required,gtefield=MinTopupAmount"`
+	MinTransferAmount    int64 `json:"min_transfer_gsalt" validate:"required,gt=0"`
+	MaxTransferAmount    int64 `json:"max_transfer_gsalt" validate:"required,gtefield=MinTransferAmount"`
+	MinPaymentAmount     int64 `json:"min_payment_gsalt" validate:"required,gt=0"`
+	MaxPaymentAmount     int64 `json:"max_payment_gsalt" validate:"required,gtefield=MinPaymentAmount"`
+	DailyTransferLimit   int64 `json:"daily_transfer_limit" validate:"required,gtefield=MaxTransferAmount"`
+	MonthlyTransferLimit int64 `json:"monthly_transfer_limit" validate:"required,gtefield=DailyTransferLimit"`
+	DailyPaymentLimit    int64 `json:"daily_payment_limit" validate:"required,gtefield=MaxPaymentAmount"`
+	MonthlyPaymentLimit  int64 `json:"monthly_payment_limit" validate:"required,gtefield=DailyPaymentLimit"`
+}
+
+// TransactionLimitsOverride replaces some of the default limits. Unset fields keep the
+// default value.
+type TransactionLimitsOverride struct {
+	MinTopupAmount       *int64 `json:"min_topup_gsalt,omitempty" validate:"omitempty,gt=0"`
+	MaxTopupAmount       *int64 `json:"max_topup_gsalt,omitempty" validate:"omitempty,gt=0"`
+	MinTransferAmount    *int64 `json:"min_transfer_gsalt,omitempty" validate:"omitempty,gt=0"`
+	MaxTransferAmount    *int64 `json:"max_transfer_gsalt,omitempty" validate:"omitempty,gt=0"`
+	MinPaymentAmount     *int64 `json:"min_payment_gsalt,omitempty" validate:"omitempty,gt=0"`
+	MaxPaymentAmount     *int64 `json:"max_payment_gsalt,omitempty" validate:"omitempty,gt=0"`
+	DailyTransferLimit   *int64 `json:"daily_transfer_limit,omitempty" validate:"omitempty,gt=0"`
+	MonthlyTransferLimit *int64 `json:"monthly_transfer_limit,omitempty" validate:"omitempty,gt=0"`
+	DailyPaymentLimit    *int64 `json:"daily_payment_limit,omitempty" validate:"omitempty,gt=0"`
+	MonthlyPaymentLimit  *int64 `json:"monthly_payment_limit,omitempty" validate:"omitempty,gt=0"`
+}
+
+// Apply returns the limits with the fields set in o replaced
+func (l TransactionLimits) Apply(o TransactionLimitsOverride) TransactionLimits {
+	set := func(dst *int64, src *int64) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+
+	set(&l.MinTopupAmount, o.MinTopupAmount)
+	set(&l.MaxTopupAmount, o.MaxTopupAmount)
+	set(&l.MinTransferAmount, o.MinTransferAmount)
+	set(&l.MaxTransferAmount, o.MaxTransferAmount)
+	set(&l.MinPaymentAmount, o.MinPaymentAmount)
+	set(&l.MaxPaymentAmount, o.MaxPaymentAmount)
+	set(&l.DailyTransferLimit, o.DailyTransferLimit)
+	set(&l.MonthlyTransferLimit, o.MonthlyTransferLimit)
+	set(&l.DailyPaymentLimit, o.DailyPaymentLimit)
+	set(&l.MonthlyPaymentLimit, o.MonthlyPaymentLimit)
+	return l
+}
+
+// TransactionLimitConfig is the full transaction limit configuration. The limits of an account
+// are the defaults, then its account type override, then its KYC status override, then its
+// KYC level override.
+type TransactionLimitConfig struct {
+	DefaultLimits        TransactionLimits                         `json:"default_limits"`
+	AccountTypeOverrides map[AccountType]TransactionLimitsOverride `json:"account_type_overrides"`
+	KYCOverrides         map[KYCStatus]TransactionLimitsOverride   `json:"kyc_overrides"`
+	KYCLevelOverrides    map[KYCLevel]TransactionLimitsOverride    `json:"kyc_level_overrides"`
+}
+
+// AccountLimitsUpdateRequest sets the daily and monthly spending caps of one account across
+// transfers, payments and withdrawals. Null removes a cap.
+type AccountLimitsUpdateRequest struct {
+	DailyLimit   *int64 `json:"daily_limit" validate:"omitempty,gt=0"`
+	MonthlyLimit *int64 `json:"monthly_limit" validate:"omitempty,gt=0"`
+}
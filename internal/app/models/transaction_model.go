@@ -19,12 +19,19 @@ const (
 	TransactionTypeGiftIn            TransactionType = "GIFT_IN"
 	TransactionTypeGiftOut           TransactionType = "GIFT_OUT"
 	TransactionTypeVoucherRedemption TransactionType = "VOUCHER_REDEMPTION"
-
-	TransactionStatusPending    TransactionStatus = "PENDING"
-	TransactionStatusProcessing TransactionStatus = "PROCESSING"
-	TransactionStatusCompleted  TransactionStatus = "COMPLETED"
-	TransactionStatusFailed     TransactionStatus = "FAILED"
-	TransactionStatusCancelled  TransactionStatus = "CANCELLED"
+	TransactionTypeRefund            TransactionType = "REFUND"
+	TransactionTypeReversal          TransactionType = "REVERSAL"
+	TransactionTypeAdjustment        TransactionType = "ADJUSTMENT"
+	TransactionTypeDonation          TransactionType = "DONATION"
+
+	TransactionStatusPending           TransactionStatus = "PENDING"
+	TransactionStatusProcessing        TransactionStatus = "PROCESSING"
+	TransactionStatusCompleted         TransactionStatus = "COMPLETED"
+	TransactionStatusFailed            TransactionStatus = "FAILED"
+	TransactionStatusCancelled         TransactionStatus = "CANCELLED"
+	TransactionStatusPartiallyRefunded TransactionStatus = "PARTIALLY_REFUNDED"
+	TransactionStatusRefunded          TransactionStatus = "REFUNDED"
+	TransactionStatusReversed          TransactionStatus = "REVERSED"
 )
 
 type Transaction struct {
@@ -46,6 +53,7 @@ type Transaction struct {
 	PaymentMethod         *string           `json:"payment_method,omitempty" gorm:"type:varchar(50)"`
 	FeeGsaltUnits         int64             `json:"fee_gsalt_units" gorm:"type:bigint;not null"`
 	TotalAmountGsaltUnits int64             `json:"total_amount_gsalt_units" gorm:"type:bigint;not null"`
+	JournalEntryID        *uuid.UUID        `json:"journal_entry_id,omitempty" gorm:"type:uuid"`
 
 	// Payment status fields
 	PaymentStatus            PaymentStatus `json:"payment_status" gorm:"type:varchar(20);default:PENDING"`
@@ -75,10 +83,12 @@ type TopupRequest struct {
 	PaymentDetails      *PaymentDetailsCreateRequest `json:"payment_details,omitempty"`
 }
 
+// TransferRequest sends GSALT to the recipient identified by exactly one TransferRecipient
+// field. The amount may be omitted when paying a dynamic QR, which carries its own.
 type TransferRequest struct {
-	DestinationAccountID string  `json:"destination_account_id" validate:"required,uuid"`
-	AmountGsalt          string  `json:"amount_gsalt" validate:"required,numeric,gt=0"`
-	Description          *string `json:"description,omitempty" validate:"omitempty,max=500"`
+	TransferRecipient
+	AmountGsalt string  `json:"amount_gsalt" validate:"omitempty,numeric"`
+	Description *string `json:"description,omitempty" validate:"omitempty,max=500"`
 }
 
 type PaymentRequest struct {
@@ -93,6 +103,24 @@ type PaymentRequest struct {
 	PaymentDetails   *PaymentDetailsCreateRequest `json:"payment_details,omitempty"`
 }
 
+// RefundRequest refunds a completed TOPUP or PAYMENT. An empty amount refunds the remaining amount.
+type RefundRequest struct {
+	AmountGsalt *string `json:"amount_gsalt,omitempty" validate:"omitempty,numeric,gt=0"`
+	Reason      string  `json:"reason" validate:"required,max=500"`
+}
+
+// RefundPayoutRequest records that a refund was returned to the payer outside the payment
+// provider. Reference identifies the payout, e.g. the bank transfer number.
+type RefundPayoutRequest struct {
+	Reference string  `json:"reference" validate:"required,max=255"`
+	Note      *string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+// ReversalRequest fully undoes a completed transaction
+type ReversalRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
 type WithdrawalRequest struct {
 	AmountGsalt         string  `json:"amount_gsalt" validate:"required,numeric,gt=0"`
 	BankCode            string  `json:"bank_code" validate:"required,max=10"`
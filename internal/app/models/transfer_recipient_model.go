@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TransferQRType string
+
+const (
+	// TransferQRTypeStatic identifies an account; the payer chooses the amount
+	TransferQRTypeStatic TransferQRType = "STATIC"
+	// TransferQRTypeDynamic requests a fixed amount, expires and can be paid once
+	TransferQRTypeDynamic TransferQRType = "DYNAMIC"
+)
+
+// TransferRecipient identifies who receives a transfer. Exactly one field must be set.
+type TransferRecipient struct {
+	DestinationAccountID *string `json:"destination_account_id,omitempty" validate:"omitempty,uuid"`
+	DestinationUsername  *string `json:"destination_username,omitempty" validate:"omitempty,min=1,max=100"`
+	DestinationEmail     *string `json:"destination_email,omitempty" validate:"omitempty,email,max=255"`
+	QRPayload            *string `json:"qr_payload,omitempty" validate:"omitempty,max=2048"`
+}
+
+// RecipientPreview is what a payer sees before confirming a transfer. The name is masked so a
+// username or email lookup does not reveal who owns it.
+type RecipientPreview struct {
+	AccountID  uuid.UUID   `json:"account_id"`
+	MaskedName string      `json:"masked_name"`
+	AvatarURL  *string     `json:"avatar_url,omitempty"`
+	QR         *TransferQR `json:"qr,omitempty"`
+}
+
+type TransferQRRequest struct {
+	Type             TransferQRType `json:"type" validate:"required,oneof=STATIC DYNAMIC"`
+	AmountGsalt      *string        `json:"amount_gsalt,omitempty" validate:"required_if=Type DYNAMIC,omitempty,numeric"`
+	Description      *string        `json:"description,omitempty" validate:"omitempty,max=500"`
+	ExpiresInMinutes *int           `json:"expires_in_minutes,omitempty" validate:"omitempty,min=1,max=1440"`
+}
+
+// TransferQR is a signed "scan to pay" payload. Dynamic QRs carry an ID, an amount and an expiry.
+type TransferQR struct {
+	ID               *uuid.UUID     `json:"id,omitempty"`
+	Type             TransferQRType `json:"type"`
+	AccountID        uuid.UUID      `json:"account_id"`
+	AmountGsaltUnits *int64         `json:"amount_gsalt_units,omitempty"`
+	Description      *string        `json:"description,omitempty"`
+	ExpiresAt        *time.Time     `json:"expires_at,omitempty"`
+	Payload          string         `json:"payload,omitempty"`
+}
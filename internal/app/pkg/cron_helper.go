@@ -0,0 +1,174 @@
+package pkg
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronSchedule is a parsed cron-like schedule.
+// Supported specs are five-field cron expressions ("minute hour day-of-month month day-of-week")
+// with "*", "*/n", "a-b", "a-b/n" and comma-separated lists, plus the descriptors
+// "@hourly", "@daily", "@weekly", "@monthly" and "@every <duration>".
+type CronSchedule struct {
+	Spec string
+
+	every   time.Duration
+	minutes []bool
+	hours   []bool
+	doms    []bool
+	months  []bool
+	dows    []bool
+	domStar bool
+	dowStar bool
+}
+
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+}
+
+// ParseCronSpec parses a cron-like schedule spec
+func ParseCronSpec(spec string) (*CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if strings.HasPrefix(spec, "@every ") {
+		every, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every duration in %q: %w", spec, err)
+		}
+		if every < time.Second {
+			return nil, fmt.Errorf("@every duration must be at least 1s in %q", spec)
+		}
+		return &CronSchedule{Spec: spec, every: every}, nil
+	}
+
+	expr := spec
+	if descriptor, ok := cronDescriptors[spec]; ok {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron spec %q must have 5 fields", spec)
+	}
+
+	schedule := &CronSchedule{Spec: spec}
+	var err error
+	if schedule.minutes, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("invalid minute field in %q: %w", spec, err)
+	}
+	if schedule.hours, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("invalid hour field in %q: %w", spec, err)
+	}
+	if schedule.doms, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("invalid day-of-month field in %q: %w", spec, err)
+	}
+	if schedule.months, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("invalid month field in %q: %w", spec, err)
+	}
+	if schedule.dows, err = parseCronField(fields[4], 0, 6); err != nil {
+		return nil, fmt.Errorf("invalid day-of-week field in %q: %w", spec, err)
+	}
+	schedule.domStar = fields[2] == "*"
+	schedule.dowStar = fields[4] == "*"
+
+	return schedule, nil
+}
+
+// Next returns the first activation time strictly after t. "@every" activations fall on fixed
+// multiples of the duration (as computed by time.Truncate), so every instance computes the same
+// times whenever it started.
+func (s *CronSchedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Truncate(s.every).Add(s.every)
+	}
+
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	// Five years is enough to find any valid combination, including Feb 29
+	limit := next.AddDate(5, 0, 0)
+
+	for next.Before(limit) {
+		if !s.months[int(next.Month())] {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !s.dayMatches(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+			continue
+		}
+		if !s.hours[next.Hour()] {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+			continue
+		}
+		if !s.minutes[next.Minute()] {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+
+	return time.Time{}
+}
+
+// dayMatches follows cron semantics: when both day fields are restricted, either may match
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.doms[t.Day()]
+	dowMatch := s.dows[int(t.Weekday())]
+
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseCronField expands a single cron field into a lookup table indexed by value
+func parseCronField(field string, min, max int) ([]bool, error) {
+	values := make([]bool, max+1)
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[idx+1:])
+			if err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+			part = part[:idx]
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if start, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+			if end, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			value, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			start, end = value, value
+		}
+
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("value out of range %d-%d in %q", min, max, field)
+		}
+
+		for value := start; value <= end; value += step {
+			values[value] = true
+		}
+	}
+
+	return values, nil
+}
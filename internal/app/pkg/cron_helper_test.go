@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronSpecRejectsInvalidSpecs(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 13 *",
+		"* * * * 7",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@yearly",
+		"@every",
+		"@every 500ms",
+		"@every soon",
+	}
+
+	for _, spec := range tests {
+		if _, err := ParseCronSpec(spec); err == nil {
+			t.Errorf("ParseCronSpec(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	// Wednesday
+	base := time.Date(2025, 7, 9, 10, 17, 42, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"* * * * *", base, time.Date(2025, 7, 9, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", base, time.Date(2025, 7, 9, 10, 30, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2025, 7, 9, 10, 30, 0, 0, time.UTC), time.Date(2025, 7, 9, 10, 45, 0, 0, time.UTC)},
+		{"5,20 * * * *", base, time.Date(2025, 7, 9, 10, 20, 0, 0, time.UTC)},
+		{"10-20/5 * * * *", base, time.Date(2025, 7, 9, 10, 20, 0, 0, time.UTC)},
+		{"0 3 * * *", base, time.Date(2025, 7, 10, 3, 0, 0, 0, time.UTC)},
+		{"30 9-17 * * 1-5", time.Date(2025, 7, 11, 17, 45, 0, 0, time.UTC), time.Date(2025, 7, 14, 9, 30, 0, 0, time.UTC)},
+		{"@hourly", base, time.Date(2025, 7, 9, 11, 0, 0, 0, time.UTC)},
+		{"@daily", base, time.Date(2025, 7, 10, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", base, time.Date(2025, 7, 13, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", base, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 10, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", base, time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either may match
+		{"0 0 1 * 1", base, time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 0", base, time.Date(2025, 7, 13, 0, 0, 0, 0, time.UTC)},
+		// One day field restricted: only that field applies
+		{"0 0 * * 1", base, time.Date(2025, 7, 14, 0, 0, 0, 0, time.UTC)},
+		{"@every 30s", base, time.Date(2025, 7, 9, 10, 18, 0, 0, time.UTC)},
+		{"@every 30s", time.Date(2025, 7, 9, 10, 18, 0, 0, time.UTC), time.Date(2025, 7, 9, 10, 18, 30, 0, time.UTC)},
+		{"@every 10m", base, time.Date(2025, 7, 9, 10, 20, 0, 0, time.UTC)},
+		{"@every 1h", base, time.Date(2025, 7, 9, 11, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		schedule, err := ParseCronSpec(tt.spec)
+		if err != nil {
+			t.Errorf("ParseCronSpec(%q): %v", tt.spec, err)
+			continue
+		}
+		if got := schedule.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%s) = %s, want %s", tt.spec, tt.from.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+		}
+	}
+}
+
+func TestCronScheduleEveryIsAlignedAcrossInstances(t *testing.T) {
+	schedule, err := ParseCronSpec("@every 5m")
+	if err != nil {
+		t.Fatalf("ParseCronSpec: %v", err)
+	}
+
+	// Two instances started at different times share their activations
+	first := schedule.Next(time.Date(2025, 7, 9, 10, 1, 13, 0, time.UTC))
+	second := schedule.Next(time.Date(2025, 7, 9, 10, 3, 59, 0, time.UTC))
+	if !first.Equal(second) {
+		t.Fatalf("activations differ: %s and %s", first, second)
+	}
+	if want := time.Date(2025, 7, 9, 10, 5, 0, 0, time.UTC); !first.Equal(want) {
+		t.Fatalf("Next = %s, want %s", first, want)
+	}
+}
@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Error codes for account closure requests
+const (
+	ErrCodeAccountHasOpenTransactions = "ACCOUNT_HAS_OPEN_TRANSACTIONS"
+	ErrCodeAccountBalanceRemaining    = "ACCOUNT_BALANCE_REMAINING"
+	ErrCodeAccountNotClosing          = "ACCOUNT_NOT_CLOSING"
+)
+
+const (
+	// closureBatchSize bounds how many closures one run of the finalize job handles
+	closureBatchSize = 100
+	// redactedValue replaces personal data in rows that must be kept
+	redactedValue = "REDACTED"
+)
+
+// redactedAuditKeys are removed from the old and new data of a closed account's audit logs
+var redactedAuditKeys = []string{
+	"full_name", "date_of_birth", "id_number", "address",
+	"id_document_reference", "selfie_reference", "proof_of_address_reference",
+	"tax_id", "contact_name", "contact_email", "contact_phone",
+	"settlement_account_number", "settlement_account_holder",
+}
+
+// AccountClosureService closes accounts. The owner asks for closure once nothing is pending
+// and the balance is withdrawn or donated; the account then stays CLOSING for a cooling-off
+// period in which the owner can reactivate it. Afterwards the finalize job anonymises the
+// personal data kept in related rows and soft-deletes the account.
+type AccountClosureService struct {
+	db                 *gorm.DB
+	validator          *infrastructures.Validator
+	transactionService *TransactionService
+	ledgerService      *LedgerService
+	auditService       *AuditService
+}
+
+func NewAccountClosureService(
+	db *gorm.DB,
+	validator *infrastructures.Validator,
+	transactionService *TransactionService,
+	ledgerService *LedgerService,
+	auditService *AuditService,
+) *AccountClosureService {
+	return &AccountClosureService{
+		db:                 db,
+		validator:          validator,
+		transactionService: transactionService,
+		ledgerService:      ledgerService,
+		auditService:       auditService,
+	}
+}
+
+// RequestClosure starts the cooling-off period of an active account. It is refused while the
+// account has pending or processing transactions or held balance, and while balance remains
+// unless the owner donates it.
+func (s *AccountClosureService) RequestClosure(accountID uuid.UUID, req *models.AccountClosureRequest) (*models.AccountClosure, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	var account *models.Account
+	var oldAccount models.Account
+	var donation *models.Transaction
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		account, err = s.lockAccount(tx, accountID)
+		if err != nil {
+			return err
+		}
+		oldAccount = *account
+
+		if account.Status != models.AccountStatusActive {
+			return errors.NewAppError(http.StatusConflict, fmt.Sprintf("Only active accounts can be closed (%s) [%s]", account.Status, ErrCodeInvalidAccountStatusTransition))
+		}
+
+		var open int64
+		if err := tx.Model(&models.Transaction{}).
+			Where("account_id = ? AND status IN ?", accountID, []models.TransactionStatus{models.TransactionStatusPending, models.TransactionStatusProcessing}).
+			Count(&open).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to check open transactions")
+		}
+		if open > 0 || account.HeldBalance > 0 {
+			return errors.NewAppError(http.StatusConflict, fmt.Sprintf("Account has %d pending or processing transactions; wait for them to finish [%s]", open, ErrCodeAccountHasOpenTransactions))
+		}
+
+		if account.Balance > 0 {
+			if req.BalanceDisposition == nil || *req.BalanceDisposition != models.BalanceDispositionDonate {
+				return errors.NewAppErrorWithData(http.StatusConflict,
+					"Withdraw the remaining balance or donate it with balance_disposition DONATE ["+ErrCodeAccountBalanceRemaining+"]",
+					map[string]interface{}{"balance": account.Balance})
+			}
+			donation, err = s.donateBalance(tx, account)
+			if err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		scheduledAt := now.Add(infrastructures.Config.AccountClosureCoolingOff)
+		reason := "Closure requested by the account owner"
+		if req.Reason != nil && strings.TrimSpace(*req.Reason) != "" {
+			reason = strings.TrimSpace(*req.Reason)
+		}
+		account.Status = models.AccountStatusClosing
+		account.StatusReason = &reason
+		account.StatusChangedAt = &now
+		account.ClosureRequestedAt = &now
+		account.ClosureScheduledAt = &scheduledAt
+		if err := tx.Model(account).Updates(map[string]interface{}{
+			"status":               account.Status,
+			"status_reason":        account.StatusReason,
+			"status_changed_at":    account.StatusChangedAt,
+			"closure_requested_at": account.ClosureRequestedAt,
+			"closure_scheduled_at": account.ClosureScheduledAt,
+		}).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update account status")
+		}
+
+		return s.auditService.LogAuditTx(tx, "accounts", accountID, models.AuditActionStatusChange, oldAccount, account, &accountID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	closure := accountClosure(account)
+	if donation != nil {
+		closure.DonationID = &donation.ID
+	}
+	return closure, nil
+}
+
+// GetClosure returns the closure state of an account
+func (s *AccountClosureService) GetClosure(accountID uuid.UUID) (*models.AccountClosure, error) {
+	var account models.Account
+	if err := s.db.Where("connect_id = ?", accountID).First(&account).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Account not found [" + ErrCodeAccountNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get account")
+	}
+	return accountClosure(&account), nil
+}
+
+// CancelClosure reactivates an account during its cooling-off period. A donated balance is not
+// returned.
+func (s *AccountClosureService) CancelClosure(accountID uuid.UUID) (*models.Account, error) {
+	var account *models.Account
+	var oldAccount models.Account
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		account, err = s.lockAccount(tx, accountID)
+		if err != nil {
+			return err
+		}
+		oldAccount = *account
+
+		if account.Status != models.AccountStatusClosing {
+			return errors.NewAppError(http.StatusConflict, fmt.Sprintf("Account is not being closed (%s) [%s]", account.Status, ErrCodeAccountNotClosing))
+		}
+
+		now := time.Now()
+		reason := "Closure cancelled by the account owner"
+		account.Status = models.AccountStatusActive
+		account.StatusReason = &reason
+		account.StatusChangedAt = &now
+		account.ClosureRequestedAt = nil
+		account.ClosureScheduledAt = nil
+		if err := tx.Model(account).Updates(map[string]interface{}{
+			"status":               account.Status,
+			"status_reason":        account.StatusReason,
+			"status_changed_at":    account.StatusChangedAt,
+			"closure_requested_at": nil,
+			"closure_scheduled_at": nil,
+		}).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update account status")
+		}
+
+		return s.auditService.LogAuditTx(tx, "accounts", accountID, models.AuditActionStatusChange, oldAccount, account, &accountID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// FinalizeDueClosures closes the accounts whose cooling-off period has passed. Accounts that
+// received money or started a transaction in the meantime are left CLOSING for support to
+// resolve.
+func (s *AccountClosureService) FinalizeDueClosures(ctx context.Context) error {
+	var accountIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).
+		Model(&models.Account{}).
+		Where("status = ? AND closure_scheduled_at <= ?", models.AccountStatusClosing, time.Now()).
+		Order("closure_scheduled_at").
+		Limit(closureBatchSize).
+		Pluck("connect_id", &accountIDs).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to get due account closures")
+	}
+
+	closed := 0
+	for _, accountID := range accountIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		ok, err := s.finalizeClosure(ctx, accountID)
+		if err != nil {
+			logrus.WithField("account_id", accountID).WithError(err).Warn("Failed to close account")
+			continue
+		}
+		if ok {
+			closed++
+		}
+	}
+
+	if len(accountIDs) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"candidates": len(accountIDs),
+			"closed":     closed,
+		}).Info("Finalized account closures")
+	}
+
+	return nil
+}
+
+// finalizeClosure anonymises and soft-deletes one closing account. It returns false when the
+// account can no longer be closed as it is.
+func (s *AccountClosureService) finalizeClosure(ctx context.Context, accountID uuid.UUID) (bool, error) {
+	var oldAccount models.Account
+	var account *models.Account
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		account, err = s.lockAccount(tx, accountID)
+		if err != nil {
+			return err
+		}
+		oldAccount = *account
+
+		if account.Status != models.AccountStatusClosing {
+			account = nil
+			return nil
+		}
+
+		var open int64
+		if err := tx.Model(&models.Transaction{}).
+			Where("account_id = ? AND status IN ?", accountID, []models.TransactionStatus{models.TransactionStatusPending, models.TransactionStatusProcessing}).
+			Count(&open).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to check open transactions")
+		}
+		if open > 0 || account.Balance != 0 || account.HeldBalance != 0 {
+			logrus.WithFields(logrus.Fields{
+				"account_id":        accountID,
+				"open_transactions": open,
+				"balance":           account.Balance,
+				"held_balance":      account.HeldBalance,
+			}).Warn("Closing account has balance or open transactions; leaving it for support")
+			account = nil
+			return nil
+		}
+
+		if err := s.anonymize(tx, accountID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		account.Status = models.AccountStatusClosed
+		account.StatusChangedAt = &now
+		account.ClosedAt = &now
+		if err := tx.Model(account).Updates(map[string]interface{}{
+			"status":            account.Status,
+			"status_changed_at": account.StatusChangedAt,
+			"closed_at":         account.ClosedAt,
+			"daily_limit":       nil,
+			"monthly_limit":     nil,
+		}).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to close account")
+		}
+		if err := tx.Delete(account).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to delete account")
+		}
+
+		return s.auditService.LogAuditTx(tx, "accounts", accountID, models.AuditActionDelete,
+			map[string]interface{}{"status": oldAccount.Status},
+			map[string]interface{}{"status": account.Status, "closed_at": account.ClosedAt},
+			nil)
+	})
+	if err != nil || account == nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// anonymize removes the personal data of a closing account from the rows kept for accounting
+// and compliance, and revokes its merchant credentials
+func (s *AccountClosureService) anonymize(tx *gorm.DB, accountID uuid.UUID) error {
+	now := time.Now()
+
+	if err := tx.Model(&models.KYCSubmission{}).Where("account_id = ?", accountID).Updates(map[string]interface{}{
+		"full_name":                  redactedValue,
+		"date_of_birth":              time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC),
+		"id_number":                  redactedValue,
+		"address":                    nil,
+		"id_document_reference":      redactedValue,
+		"selfie_reference":           nil,
+		"proof_of_address_reference": nil,
+	}).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to anonymise KYC submissions")
+	}
+
+	merchantPII := map[string]interface{}{
+		"tax_id":                    nil,
+		"contact_name":              redactedValue,
+		"contact_email":             redactedValue,
+		"contact_phone":             redactedValue,
+		"settlement_account_number": redactedValue,
+		"settlement_account_holder": redactedValue,
+	}
+	if err := tx.Model(&models.MerchantApplication{}).Where("account_id = ?", accountID).Updates(merchantPII).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to anonymise merchant applications")
+	}
+	if err := tx.Model(&models.MerchantProfile{}).Where("account_id = ?", accountID).Updates(merchantPII).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to anonymise merchant profile")
+	}
+
+	// Withdrawal descriptions and payout responses hold the recipient's bank account
+	withdrawals := tx.Model(&models.Transaction{}).Select("id").Where("account_id = ? AND type = ?", accountID, models.TransactionTypeWithdrawal)
+	if err := tx.Model(&models.PaymentDetails{}).Where("transaction_id IN (?)", withdrawals).Update("raw_provider_response", nil).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to anonymise withdrawal details")
+	}
+	if err := tx.Model(&models.Transaction{}).Where("account_id = ? AND type = ?", accountID, models.TransactionTypeWithdrawal).UpdateColumn("description", nil).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to anonymise withdrawals")
+	}
+
+	quotedKeys := make([]string, len(redactedAuditKeys))
+	for i, key := range redactedAuditKeys {
+		quotedKeys[i] = "'" + key + "'"
+	}
+	keys := "ARRAY[" + strings.Join(quotedKeys, ", ") + "]"
+	if err := tx.Exec(`UPDATE audit_logs SET old_data = old_data - `+keys+`, new_data = new_data - `+keys+`
+		WHERE record_id = ?
+			OR record_id IN (SELECT id FROM kyc_submissions WHERE account_id = ?)
+			OR record_id IN (SELECT id FROM merchant_applications WHERE account_id = ?)`,
+		accountID, accountID, accountID).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to anonymise audit logs")
+	}
+
+	if err := tx.Model(&models.MerchantAPIKey{}).Where("merchant_id = ? AND deleted_at IS NULL", accountID).Update("deleted_at", now).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to revoke API keys")
+	}
+	if err := tx.Model(&models.WebhookEndpoint{}).Where("merchant_id = ?", accountID).Update("is_active", false).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to disable webhook endpoints")
+	}
+	if err := tx.Where("merchant_id = ?", accountID).Delete(&models.WebhookEndpoint{}).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to delete webhook endpoints")
+	}
+
+	return nil
+}
+
+// donateBalance moves the whole balance of a locked account to the donations payable account
+func (s *AccountClosureService) donateBalance(tx *gorm.DB, account *models.Account) (*models.Transaction, error) {
+	now := time.Now()
+	description := "Balance donated on account closure"
+	donation := s.transactionService.createBaseTransaction(account.ConnectID, models.TransactionTypeDonation, account.Balance, models.TransactionStatusCompleted, &description)
+	donation.SourceAccountID = &account.ConnectID
+	donation.PaymentStatus = models.PaymentStatusCompleted
+	donation.CompletedAt = &now
+
+	if err := tx.Create(donation).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to create donation transaction")
+	}
+
+	entry, err := s.ledgerService.RecordDonation(tx, account.ConnectID, account.Balance, donation.ID.String(), &description)
+	if err != nil {
+		return nil, err
+	}
+
+	donation.JournalEntryID = &entry.ID
+	if err := tx.Save(donation).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to update donation transaction")
+	}
+
+	account.Balance = 0
+	account.AvailableBalance = 0
+	return donation, nil
+}
+
+func (s *AccountClosureService) lockAccount(tx *gorm.DB, accountID uuid.UUID) (*models.Account, error) {
+	var account models.Account
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("connect_id = ?", accountID).First(&account).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Account not found [" + ErrCodeAccountNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get account")
+	}
+	return &account, nil
+}
+
+func accountClosure(account *models.Account) *models.AccountClosure {
+	return &models.AccountClosure{
+		Status:             account.Status,
+		Reason:             account.StatusReason,
+		ClosureRequestedAt: account.ClosureRequestedAt,
+		ClosureScheduledAt: account.ClosureScheduledAt,
+		Balance:            account.Balance,
+		HeldBalance:        account.HeldBalance,
+	}
+}
@@ -84,45 +84,17 @@ func (s *AccountService) GetAccountByToken(accessToken string) (*models.Account,
 	return s.GetAccount(connectUser.ID.String())
 }
 
-func (s *AccountService) AddBalance(connectId string, amount int64) (*models.Account, error) {
-	account, err := s.GetAccount(connectId)
-	if err != nil {
-		return nil, err
-	}
-
-	account.Balance += amount
-
-	if err := s.db.Save(account).Error; err != nil {
-		return nil, errors.NewInternalServerError(err, "Failed to update account")
-	}
-
-	return account, nil
-}
-
 func (s *AccountService) AddPoints(connectId string, amount int64) (*models.Account, error) {
 	account, err := s.GetAccount(connectId)
 	if err != nil {
 		return nil, err
 	}
 
-	account.Points += amount
-
-	if err := s.db.Save(account).Error; err != nil {
+	// Only touch points; balances are owned by the ledger
+	if err := s.db.Model(account).UpdateColumn("points", gorm.Expr("points + ?", amount)).Error; err != nil {
 		return nil, errors.NewInternalServerError(err, "Failed to update account")
 	}
+	account.Points += amount
 
 	return account, nil
 }
-
-func (s *AccountService) DeleteAccount(connectId string) error {
-	account, err := s.GetAccount(connectId)
-	if err != nil {
-		return err
-	}
-
-	if err := s.db.Delete(account).Error; err != nil {
-		return errors.NewInternalServerError(err, "Failed to delete account")
-	}
-
-	return nil
-}
@@ -0,0 +1,327 @@
+package services
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrCodeInvalidAccountStatusTransition is returned when an account cannot move to the requested status
+const ErrCodeInvalidAccountStatusTransition = "INVALID_ACCOUNT_STATUS_TRANSITION"
+
+// accountStatusTransitions lists the statuses an admin can move an account to from each status
+var accountStatusTransitions = map[models.AccountStatus][]models.AccountStatus{
+	models.AccountStatusActive:    {models.AccountStatusSuspended, models.AccountStatusBlocked},
+	models.AccountStatusSuspended: {models.AccountStatusActive, models.AccountStatusBlocked},
+	models.AccountStatusBlocked:   {models.AccountStatusActive},
+}
+
+// AdminService backs the account back office: finding accounts, changing their status and
+// correcting balances. Every change is written to the audit log with the admin as ChangedBy.
+type AdminService struct {
+	db                       *gorm.DB
+	validator                *infrastructures.Validator
+	connectService           *ConnectService
+	transactionService       *TransactionService
+	voucherRedemptionService *VoucherRedemptionService
+	ledgerService            *LedgerService
+	auditService             *AuditService
+}
+
+func NewAdminService(
+	db *gorm.DB,
+	validator *infrastructures.Validator,
+	connectService *ConnectService,
+	transactionService *TransactionService,
+	voucherRedemptionService *VoucherRedemptionService,
+	ledgerService *LedgerService,
+	auditService *AuditService,
+) *AdminService {
+	return &AdminService{
+		db:                       db,
+		validator:                validator,
+		connectService:           connectService,
+		transactionService:       transactionService,
+		voucherRedemptionService: voucherRedemptionService,
+		ledgerService:            ledgerService,
+		auditService:             auditService,
+	}
+}
+
+// GetAccounts lists accounts matching the filter, newest first
+func (s *AdminService) GetAccounts(filter *models.AdminAccountFilter, pagination *models.PaginationRequest) (*models.Pagination[[]models.Account], error) {
+	if err := s.validator.Validate(filter); err != nil {
+		return nil, err
+	}
+
+	if pagination.Limit <= 0 || pagination.Limit > 100 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	query := s.db.Model(&models.Account{})
+	if filter.Status != nil {
+		query = query.Where("status = ?", *filter.Status)
+	}
+	if filter.AccountType != nil {
+		query = query.Where("account_type = ?", *filter.AccountType)
+	}
+	if filter.KYCStatus != nil {
+		query = query.Where("kyc_status = ?", *filter.KYCStatus)
+	}
+
+	var totalItems int64
+	if err := query.Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count accounts")
+	}
+
+	var accounts []models.Account
+	if err := query.Order("created_at DESC").
+		Offset(offset).
+		Limit(pagination.Limit).
+		Find(&accounts).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get accounts")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return &models.Pagination[[]models.Account]{
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: totalPages,
+		TotalItems: int(totalItems),
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+		Items:      accounts,
+	}, nil
+}
+
+// SearchAccount finds the account of a Connect ID, email address or username. Emails and
+// usernames are resolved through Connect.
+func (s *AdminService) SearchAccount(query string) (*models.AdminAccountDetail, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.NewBadRequestError("Search query is required")
+	}
+
+	if connectID, err := uuid.Parse(query); err == nil {
+		return s.GetAccountDetail(connectID)
+	}
+
+	var connectUser *models.ConnectUser
+	var err error
+	if strings.Contains(query, "@") {
+		connectUser, err = s.connectService.GetUserByEmail(query)
+	} else {
+		connectUser, err = s.connectService.GetUserByUsername(strings.TrimPrefix(query, "@"))
+	}
+	if err != nil {
+		var appErr *errors.AppError
+		if stderrors.As(err, &appErr) && appErr.StatusCode == http.StatusNotFound {
+			return nil, errors.NewNotFoundError("No Connect user matches " + query)
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to look up Connect user")
+	}
+
+	account, err := s.getAccount(s.db, connectUser.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AdminAccountDetail{Account: account, ConnectUser: connectUser}, nil
+}
+
+// GetAccountDetail returns an account with its Connect user
+func (s *AdminService) GetAccountDetail(accountID uuid.UUID) (*models.AdminAccountDetail, error) {
+	account, err := s.getAccount(s.db, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &models.AdminAccountDetail{Account: account}
+	connectUser, err := s.connectService.GetUser(accountID.String())
+	if err != nil {
+		// The account is still useful to the back office without its Connect profile
+		logrus.WithError(err).WithField("account_id", accountID).Warn("Failed to get Connect user for account")
+		return detail, nil
+	}
+	detail.ConnectUser = connectUser
+
+	return detail, nil
+}
+
+// SuspendAccount temporarily stops an account from using the API
+func (s *AdminService) SuspendAccount(accountID uuid.UUID, req *models.AccountStatusChangeRequest, adminID uuid.UUID) (*models.Account, error) {
+	return s.changeStatus(accountID, models.AccountStatusSuspended, req, adminID)
+}
+
+// BlockAccount stops an account from using the API until an admin reactivates it
+func (s *AdminService) BlockAccount(accountID uuid.UUID, req *models.AccountStatusChangeRequest, adminID uuid.UUID) (*models.Account, error) {
+	return s.changeStatus(accountID, models.AccountStatusBlocked, req, adminID)
+}
+
+// ReactivateAccount returns a suspended or blocked account to ACTIVE
+func (s *AdminService) ReactivateAccount(accountID uuid.UUID, req *models.AccountStatusChangeRequest, adminID uuid.UUID) (*models.Account, error) {
+	return s.changeStatus(accountID, models.AccountStatusActive, req, adminID)
+}
+
+func (s *AdminService) changeStatus(accountID uuid.UUID, status models.AccountStatus, req *models.AccountStatusChangeRequest, adminID uuid.UUID) (*models.Account, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	var account *models.Account
+	var oldAccount models.Account
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		account, err = s.getAccount(tx.Clauses(clause.Locking{Strength: "UPDATE"}), accountID)
+		if err != nil {
+			return err
+		}
+		oldAccount = *account
+
+		allowed := false
+		for _, next := range accountStatusTransitions[account.Status] {
+			if next == status {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return errors.NewAppError(http.StatusConflict, fmt.Sprintf("Account cannot change from %s to %s [%s]", account.Status, status, ErrCodeInvalidAccountStatusTransition))
+		}
+
+		now := time.Now()
+		reason := strings.TrimSpace(req.Reason)
+		account.Status = status
+		account.StatusReason = &reason
+		account.StatusChangedAt = &now
+		if err := tx.Model(account).Updates(map[string]interface{}{
+			"status":            account.Status,
+			"status_reason":     account.StatusReason,
+			"status_changed_at": account.StatusChangedAt,
+		}).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update account status")
+		}
+
+		return s.auditService.LogAuditTx(tx, "accounts", account.ConnectID, models.AuditActionStatusChange, oldAccount, account, &adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// AdjustBalance credits or debits an account through a completed ADJUSTMENT transaction posted
+// against the manual adjustment ledger account. Debits cannot exceed the available balance.
+func (s *AdminService) AdjustBalance(accountID uuid.UUID, req *models.BalanceAdjustmentRequest, adminID uuid.UUID) (*models.Transaction, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	amountGsaltUnits, err := gsaltToUnits(req.AmountGsalt, "amount")
+	if err != nil {
+		return nil, err
+	}
+	reason := strings.TrimSpace(req.Reason)
+
+	var adjustment *models.Transaction
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		account, err := s.getAccount(tx.Clauses(clause.Locking{Strength: "UPDATE"}), accountID)
+		if err != nil {
+			return err
+		}
+		if req.Direction == models.AdjustmentDirectionDebit && !s.transactionService.hasSufficientBalance(account.AvailableBalance, amountGsaltUnits) {
+			return errors.NewBadRequestError("Insufficient available balance for the adjustment [" + ErrCodeInsufficientBalance + "]")
+		}
+
+		now := time.Now()
+		description := fmt.Sprintf("Balance adjustment (%s): %s", strings.ToLower(string(req.Direction)), reason)
+		adjustment = s.transactionService.createBaseTransaction(accountID, models.TransactionTypeAdjustment, amountGsaltUnits, models.TransactionStatusCompleted, &description)
+		adjustment.PaymentStatus = models.PaymentStatusCompleted
+		adjustment.CompletedAt = &now
+		if req.Direction == models.AdjustmentDirectionCredit {
+			adjustment.DestinationAccountID = &accountID
+		} else {
+			adjustment.SourceAccountID = &accountID
+		}
+
+		if err := tx.Create(adjustment).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to create adjustment transaction")
+		}
+
+		entry, err := s.ledgerService.RecordAdjustment(tx, accountID, req.Direction, amountGsaltUnits, adjustment.ID.String(), &description)
+		if err != nil {
+			return err
+		}
+
+		adjustment.JournalEntryID = &entry.ID
+		if err := tx.Save(adjustment).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update adjustment transaction")
+		}
+
+		if err := s.auditService.LogTransactionStatusChangeTx(tx, adjustment.ID, nil, models.TransactionStatusCompleted, reason, map[string]interface{}{
+			"direction":          req.Direction,
+			"amount_gsalt_units": amountGsaltUnits,
+		}, &adminID); err != nil {
+			return err
+		}
+
+		newBalance := account.Balance + amountGsaltUnits
+		if req.Direction == models.AdjustmentDirectionDebit {
+			newBalance = account.Balance - amountGsaltUnits
+		}
+		return s.auditService.LogAuditTx(tx, "accounts", accountID, models.AuditActionUpdate,
+			map[string]interface{}{"balance": account.Balance},
+			map[string]interface{}{"balance": newBalance, "transaction_id": adjustment.ID, "reason": reason},
+			&adminID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// GetAccountTransactions lists an account's transactions, newest first
+func (s *AdminService) GetAccountTransactions(accountID uuid.UUID, pagination *models.PaginationRequest) (*models.Pagination[[]models.Transaction], error) {
+	return s.transactionService.GetTransactionsByAccount(accountID.String(), pagination)
+}
+
+// GetAccountRedemptions lists an account's voucher redemptions, newest first
+func (s *AdminService) GetAccountRedemptions(accountID uuid.UUID, pagination *models.PaginationRequest) (*models.Pagination[[]models.VoucherRedemption], error) {
+	return s.voucherRedemptionService.GetRedemptionsByAccount(accountID.String(), pagination)
+}
+
+// GetAccountAuditLogs lists the audit trail of an account, newest first
+func (s *AdminService) GetAccountAuditLogs(accountID uuid.UUID, pagination *models.PaginationRequest) (*models.Pagination[[]models.AuditLog], error) {
+	return s.auditService.GetRecordAuditLogs(accountID, pagination)
+}
+
+func (s *AdminService) getAccount(db *gorm.DB, accountID uuid.UUID) (*models.Account, error) {
+	var account models.Account
+	if err := db.Where("connect_id = ?", accountID).First(&account).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Account not found [" + ErrCodeAccountNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get account")
+	}
+	return &account, nil
+}
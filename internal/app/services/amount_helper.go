@@ -0,0 +1,26 @@
+package services
+
+import (
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/shopspring/decimal"
+)
+
+// unitsPerGsalt is the number of units in one GSALT
+var unitsPerGsalt = decimal.NewFromInt(100)
+
+// gsaltToUnits converts a GSALT amount string to units (1 GSALT = 100 units).
+// Amounts finer than one unit are rejected rather than truncated.
+func gsaltToUnits(amount, field string) (int64, error) {
+	amountGsalt, err := decimal.NewFromString(amount)
+	if err != nil {
+		return 0, errors.NewBadRequestError("Invalid " + field)
+	}
+	units := amountGsalt.Mul(unitsPerGsalt)
+	if !units.IsInteger() {
+		return 0, errors.NewBadRequestError("Invalid " + field + ": at most 2 decimal places are allowed")
+	}
+	if !units.IsPositive() {
+		return 0, errors.NewBadRequestError("Invalid " + field + ": must be greater than zero")
+	}
+	return units.IntPart(), nil
+}
@@ -0,0 +1,36 @@
+package services
+
+import "testing"
+
+func TestGsaltToUnits(t *testing.T) {
+	tests := []struct {
+		amount string
+		units  int64
+		valid  bool
+	}{
+		{"1", 100, true},
+		{"12.5", 1250, true},
+		{"0.01", 1, true},
+		{"100.10", 10010, true},
+		{"1.230", 123, true},
+		{"0.015", 0, false},
+		{"0.001", 0, false},
+		{"1.999", 0, false},
+		{"0", 0, false},
+		{"0.00", 0, false},
+		{"-1", 0, false},
+		{"abc", 0, false},
+		{"", 0, false},
+	}
+
+	for _, tt := range tests {
+		units, err := gsaltToUnits(tt.amount, "amount")
+		if (err == nil) != tt.valid {
+			t.Errorf("gsaltToUnits(%q) error = %v, want valid %v", tt.amount, err, tt.valid)
+			continue
+		}
+		if units != tt.units {
+			t.Errorf("gsaltToUnits(%q) = %d, want %d", tt.amount, units, tt.units)
+		}
+	}
+}
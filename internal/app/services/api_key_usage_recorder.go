@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// apiKeyUsageBufferSize bounds the usage records waiting to be written; beyond it they are dropped
+	apiKeyUsageBufferSize = 10000
+	// apiKeyUsageBatchSize is the most records written by one bulk insert
+	apiKeyUsageBatchSize = 500
+	// apiKeyUsageFlushInterval is the longest a record waits before being written
+	apiKeyUsageFlushInterval = 2 * time.Second
+	// apiKeyUsageFlushTimeout bounds a single flush, including the final one on shutdown
+	apiKeyUsageFlushTimeout = 30 * time.Second
+)
+
+// APIKeyUsageRecorder buffers API key usage records and writes them in batches, so recording
+// never blocks a request. When the buffer is full new records are dropped and counted.
+type APIKeyUsageRecorder struct {
+	apiKeyService *MerchantAPIKeyService
+	records       chan models.MerchantAPIKeyUsage
+	dropped       atomic.Int64
+
+	mu      sync.Mutex
+	stop    chan struct{}
+	wg      sync.WaitGroup
+	started bool
+}
+
+func NewAPIKeyUsageRecorder(apiKeyService *MerchantAPIKeyService) *APIKeyUsageRecorder {
+	return &APIKeyUsageRecorder{
+		apiKeyService: apiKeyService,
+		records:       make(chan models.MerchantAPIKeyUsage, apiKeyUsageBufferSize),
+	}
+}
+
+// Record queues a usage record without blocking
+func (r *APIKeyUsageRecorder) Record(usage models.MerchantAPIKeyUsage) {
+	select {
+	case r.records <- usage:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Start begins writing queued records in the background
+func (r *APIKeyUsageRecorder) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		return
+	}
+	r.started = true
+	r.stop = make(chan struct{})
+
+	r.wg.Add(1)
+	go r.run(r.stop)
+
+	logrus.Info("API key usage recorder started")
+}
+
+// Stop writes every queued record and stops the background writer. Call it after the HTTP
+// server has shut down so no more records arrive.
+func (r *APIKeyUsageRecorder) Stop() {
+	r.mu.Lock()
+	if !r.started {
+		r.mu.Unlock()
+		return
+	}
+	r.started = false
+	close(r.stop)
+	r.mu.Unlock()
+
+	r.wg.Wait()
+	logrus.Info("API key usage recorder stopped")
+}
+
+// run collects records into batches, flushing when a batch is full or the interval passes
+func (r *APIKeyUsageRecorder) run(stop <-chan struct{}) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(apiKeyUsageFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.MerchantAPIKeyUsage, 0, apiKeyUsageBatchSize)
+	for {
+		select {
+		case usage := <-r.records:
+			batch = append(batch, usage)
+			if len(batch) >= apiKeyUsageBatchSize {
+				batch = r.flush(batch)
+			}
+		case <-ticker.C:
+			batch = r.flush(batch)
+		case <-stop:
+			// Drain what is left in the buffer
+			for {
+				select {
+				case usage := <-r.records:
+					batch = append(batch, usage)
+					if len(batch) >= apiKeyUsageBatchSize {
+						batch = r.flush(batch)
+					}
+				default:
+					r.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes a batch and returns the emptied slice for reuse. Failed batches are logged and
+// discarded so a database outage cannot grow memory without bound.
+func (r *APIKeyUsageRecorder) flush(batch []models.MerchantAPIKeyUsage) []models.MerchantAPIKeyUsage {
+	if dropped := r.dropped.Swap(0); dropped > 0 {
+		logrus.WithField("dropped", dropped).Warn("API key usage buffer was full; records were dropped")
+	}
+
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyUsageFlushTimeout)
+	defer cancel()
+
+	if err := r.apiKeyService.RecordUsageBatch(ctx, batch); err != nil {
+		logrus.WithError(err).WithField("records", len(batch)).Error("Failed to write API key usage")
+	}
+
+	return batch[:0]
+}
@@ -23,6 +23,12 @@ func NewAuditService(db *gorm.DB) *AuditService {
 
 // LogAudit creates an audit log entry for any change in the system
 func (s *AuditService) LogAudit(tableName string, recordID uuid.UUID, action models.AuditAction, oldData, newData interface{}, changedBy *uuid.UUID) error {
+	return s.LogAuditTx(s.db, tableName, recordID, action, oldData, newData, changedBy)
+}
+
+// LogAuditTx creates an audit log entry within tx, so the entry commits or rolls back with
+// the change it describes
+func (s *AuditService) LogAuditTx(tx *gorm.DB, tableName string, recordID uuid.UUID, action models.AuditAction, oldData, newData interface{}, changedBy *uuid.UUID) error {
 	var oldDataJSON, newDataJSON *string
 
 	if oldData != nil {
@@ -53,7 +59,7 @@ func (s *AuditService) LogAudit(tableName string, recordID uuid.UUID, action mod
 		ChangedAt: time.Now(),
 	}
 
-	if err := s.db.Create(auditLog).Error; err != nil {
+	if err := tx.Create(auditLog).Error; err != nil {
 		return errors.NewInternalServerError(err, "Failed to create audit log")
 	}
 
@@ -67,6 +73,20 @@ func (s *AuditService) LogTransactionStatusChange(
 	reason string,
 	metadata map[string]interface{},
 	createdBy *uuid.UUID,
+) error {
+	return s.LogTransactionStatusChangeTx(s.db, transactionID, &fromStatus, toStatus, reason, metadata, createdBy)
+}
+
+// LogTransactionStatusChangeTx creates a status history entry within tx.
+// fromStatus is nil for transactions that are created in their final status.
+func (s *AuditService) LogTransactionStatusChangeTx(
+	tx *gorm.DB,
+	transactionID uuid.UUID,
+	fromStatus *models.TransactionStatus,
+	toStatus models.TransactionStatus,
+	reason string,
+	metadata map[string]interface{},
+	createdBy *uuid.UUID,
 ) error {
 	var metadataJSON *string
 	if metadata != nil {
@@ -80,7 +100,7 @@ func (s *AuditService) LogTransactionStatusChange(
 
 	history := &models.TransactionStatusHistory{
 		TransactionID: transactionID,
-		FromStatus:    &fromStatus,
+		FromStatus:    fromStatus,
 		ToStatus:      toStatus,
 		Reason:        &reason,
 		Metadata:      metadataJSON,
@@ -88,7 +108,7 @@ func (s *AuditService) LogTransactionStatusChange(
 		CreatedAt:     time.Now(),
 	}
 
-	if err := s.db.Create(history).Error; err != nil {
+	if err := tx.Create(history).Error; err != nil {
 		return errors.NewInternalServerError(err, "Failed to create transaction status history")
 	}
 
@@ -158,3 +178,42 @@ func (s *AuditService) GetAuditLogs(pagination *models.PaginationRequest) (*mode
 
 	return result, nil
 }
+
+// GetRecordAuditLogs retrieves the audit logs of one record, newest first. Records keyed by an
+// account ID, such as accounts and merchant_profiles, share the account's audit trail.
+func (s *AuditService) GetRecordAuditLogs(recordID uuid.UUID, pagination *models.PaginationRequest) (*models.Pagination[[]models.AuditLog], error) {
+	if pagination.Limit <= 0 || pagination.Limit > 100 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	var totalItems int64
+	if err := s.db.Model(&models.AuditLog{}).Where("record_id = ?", recordID).Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count audit logs")
+	}
+
+	var logs []models.AuditLog
+	if err := s.db.Where("record_id = ?", recordID).
+		Order("changed_at DESC").
+		Offset(offset).
+		Limit(pagination.Limit).
+		Find(&logs).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get audit logs")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return &models.Pagination[[]models.AuditLog]{
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: totalPages,
+		TotalItems: int(totalItems),
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+		Items:      logs,
+	}, nil
+}
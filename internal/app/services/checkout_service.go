@@ -0,0 +1,860 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// defaultCheckoutSessionTTL applies when a session is created without expires_in_minutes
+	defaultCheckoutSessionTTL = 30 * time.Minute
+	// checkoutAuthorizationTTL is how long an approved manual capture session holds the payer's balance
+	checkoutAuthorizationTTL = 7 * 24 * time.Hour
+	// checkoutAuthorizationBatchSize bounds how many lapsed authorizations one expiry run voids
+	checkoutAuthorizationBatchSize = 100
+	// ErrCodeCheckoutSessionClosed is returned when a session is no longer open
+	ErrCodeCheckoutSessionClosed = "CHECKOUT_SESSION_CLOSED"
+	// ErrCodeCheckoutSessionNotAuthorized is returned when capturing or voiding a session that holds no authorization
+	ErrCodeCheckoutSessionNotAuthorized = "CHECKOUT_SESSION_NOT_AUTHORIZED"
+)
+
+// checkoutFeeRate is the share of a checkout amount kept as the merchant fee (0.7%)
+var checkoutFeeRate = decimal.RequireFromString("0.007")
+
+// CheckoutService handles merchant checkout sessions paid with GSALT balance
+type CheckoutService struct {
+	db                 *gorm.DB
+	validator          *infrastructures.Validator
+	transactionService *TransactionService
+	ledgerService      *LedgerService
+	auditService       *AuditService
+	webhookService     *MerchantWebhookService
+	merchantService    *MerchantService
+}
+
+func NewCheckoutService(
+	db *gorm.DB,
+	validator *infrastructures.Validator,
+	transactionService *TransactionService,
+	ledgerService *LedgerService,
+	auditService *AuditService,
+	webhookService *MerchantWebhookService,
+	merchantService *MerchantService,
+) *CheckoutService {
+	return &CheckoutService{
+		db:                 db,
+		validator:          validator,
+		transactionService: transactionService,
+		ledgerService:      ledgerService,
+		auditService:       auditService,
+		webhookService:     webhookService,
+		merchantService:    merchantService,
+	}
+}
+
+// CreateSession opens a checkout session for the merchant that owns apiKey
+func (s *CheckoutService) CreateSession(apiKey *models.MerchantAPIKey, req *models.CheckoutSessionCreateRequest) (*models.CheckoutSessionCreateResponse, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	amountGsaltUnits, err := gsaltToUnits(req.AmountGsalt, "amount")
+	if err != nil {
+		return nil, err
+	}
+
+	// The payer's own limits are checked again when the session is approved
+	limits, err := s.transactionService.limitService.GetDefaultLimits(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTransactionAmount(limits, models.TransactionTypePayment, amountGsaltUnits); err != nil {
+		return nil, err
+	}
+
+	items := make([]models.CheckoutSessionItem, 0, len(req.Items))
+	var itemsTotal int64
+	for _, item := range req.Items {
+		unitAmount, err := gsaltToUnits(item.UnitAmountGsalt, "item amount")
+		if err != nil {
+			return nil, err
+		}
+		itemsTotal += unitAmount * int64(item.Quantity)
+		items = append(items, models.CheckoutSessionItem{
+			Name:                 item.Name,
+			Quantity:             item.Quantity,
+			UnitAmountGsaltUnits: unitAmount,
+			ImageURL:             item.ImageURL,
+		})
+	}
+	if len(items) > 0 && itemsTotal != amountGsaltUnits {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Items total of %d units does not match the amount of %d units", itemsTotal, amountGsaltUnits))
+	}
+
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to encode checkout items")
+	}
+
+	merchant, err := s.getAccount(s.db, apiKey.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+	if merchant.Status != models.AccountStatusActive {
+		return nil, errors.NewForbiddenError(fmt.Sprintf("Merchant account is not active (%s)", merchant.Status))
+	}
+
+	if req.ReferenceID != nil {
+		var count int64
+		if err := s.db.Model(&models.CheckoutSession{}).
+			Where("merchant_id = ? AND environment = ? AND reference_id = ?", apiKey.MerchantID, apiKey.Environment, *req.ReferenceID).
+			Count(&count).Error; err != nil {
+			return nil, errors.NewInternalServerError(err, "Failed to check checkout session reference")
+		}
+		if count > 0 {
+			return nil, errors.NewAppError(http.StatusConflict, "A checkout session with this reference_id already exists ["+ErrCodeDuplicateTransaction+"]")
+		}
+	}
+
+	secret, err := generateSigningSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := defaultCheckoutSessionTTL
+	if req.ExpiresInMinutes != nil {
+		ttl = time.Duration(*req.ExpiresInMinutes) * time.Minute
+	}
+
+	captureMethod := models.CheckoutCaptureMethodAutomatic
+	if req.CaptureMethod != "" {
+		captureMethod = req.CaptureMethod
+	}
+
+	feeGsaltUnits := s.calculateFee(amountGsaltUnits)
+	session := &models.CheckoutSession{
+		MerchantID:          apiKey.MerchantID,
+		APIKeyID:            apiKey.ID,
+		Environment:         apiKey.Environment,
+		ReferenceID:         req.ReferenceID,
+		AmountGsaltUnits:    amountGsaltUnits,
+		FeeGsaltUnits:       feeGsaltUnits,
+		NetAmountGsaltUnits: amountGsaltUnits - feeGsaltUnits,
+		Currency:            "GSALT",
+		Description:         req.Description,
+		Items:               itemsJSON,
+		RedirectURL:         req.RedirectURL,
+		SigningSecret:       secret,
+		Status:              models.CheckoutSessionStatusOpen,
+		CaptureMethod:       captureMethod,
+		ExpiresAt:           time.Now().Add(ttl),
+	}
+
+	if err := s.db.Create(session).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to create checkout session")
+	}
+
+	return &models.CheckoutSessionCreateResponse{
+		CheckoutSession: *session,
+		SigningSecret:   secret,
+	}, nil
+}
+
+// GetMerchantSession returns a session owned by the merchant of apiKey in the key's environment
+func (s *CheckoutService) GetMerchantSession(apiKey *models.MerchantAPIKey, sessionId string) (*models.CheckoutSession, error) {
+	session, err := s.getSession(s.db, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if !ownsSession(apiKey, session) {
+		return nil, errors.NewNotFoundError("Checkout session not found")
+	}
+	return session, nil
+}
+
+// GetMerchantSessions lists the sessions of the merchant of apiKey in the key's environment,
+// newest first
+func (s *CheckoutService) GetMerchantSessions(apiKey *models.MerchantAPIKey, status *models.CheckoutSessionStatus, pagination *models.PaginationRequest) (*models.Pagination[[]models.CheckoutSession], error) {
+	if pagination.Limit <= 0 || pagination.Limit > 100 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("merchant_id = ? AND environment = ?", apiKey.MerchantID, apiKey.Environment)
+		if status != nil {
+			db = db.Where("status = ?", *status)
+		}
+		return db
+	}
+
+	var totalItems int64
+	if err := s.db.Model(&models.CheckoutSession{}).Scopes(scope).Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count checkout sessions")
+	}
+
+	var sessions []models.CheckoutSession
+	if err := s.db.Scopes(scope).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pagination.Limit).
+		Find(&sessions).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get checkout sessions")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return &models.Pagination[[]models.CheckoutSession]{
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: totalPages,
+		TotalItems: int(totalItems),
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+		Items:      sessions,
+	}, nil
+}
+
+// CancelSession lets the merchant close an open session
+func (s *CheckoutService) CancelSession(apiKey *models.MerchantAPIKey, sessionId string) (*models.CheckoutSession, error) {
+	var session *models.CheckoutSession
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		session, err = s.lockOpenSession(tx, sessionId)
+		if err != nil {
+			return err
+		}
+		if !ownsSession(apiKey, session) {
+			return errors.NewNotFoundError("Checkout session not found")
+		}
+		return s.closeSession(tx, session, models.CheckoutSessionStatusCancelled)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetPayerSession returns what a payer needs to review a session before approving it
+func (s *CheckoutService) GetPayerSession(sessionId string) (*models.CheckoutSessionPayerView, error) {
+	session, err := s.getSession(s.db, sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	status := session.Status
+	if status == models.CheckoutSessionStatusOpen && session.IsExpired() {
+		status = models.CheckoutSessionStatusExpired
+	}
+
+	merchant, err := s.merchantService.GetPublicProfile(session.MerchantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CheckoutSessionPayerView{
+		ID:               session.ID,
+		MerchantID:       session.MerchantID,
+		Merchant:         merchant,
+		ReferenceID:      session.ReferenceID,
+		AmountGsaltUnits: session.AmountGsaltUnits,
+		Currency:         session.Currency,
+		Description:      session.Description,
+		Items:            session.Items,
+		Status:           status,
+		Environment:      session.Environment,
+		ExpiresAt:        session.ExpiresAt,
+	}, nil
+}
+
+// ApproveSession pays an open session from the payer's available balance. It creates a completed
+// PAYMENT transaction to the merchant and returns the signed result for the merchant redirect.
+// Test mode sessions are paid between sandbox wallets instead, without a transaction. Manual
+// capture sessions are only authorized: the amount is held until the merchant captures it.
+func (s *CheckoutService) ApproveSession(sessionId string, payerAccountID uuid.UUID) (*models.CheckoutResult, error) {
+	var session *models.CheckoutSession
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		session, err = s.lockOpenSession(tx, sessionId)
+		if err != nil {
+			return err
+		}
+
+		if session.MerchantID == payerAccountID {
+			return errors.NewBadRequestError("Cannot pay your own checkout session [" + ErrCodeSelfTransfer + "]")
+		}
+
+		merchant, err := s.getAccount(tx, session.MerchantID)
+		if err != nil {
+			return err
+		}
+		if merchant.Status != models.AccountStatusActive {
+			return errors.NewBadRequestError(fmt.Sprintf("Merchant account is not active (%s)", merchant.Status))
+		}
+
+		description := checkoutDescription(session)
+
+		if session.Environment == models.APIKeyEnvironmentTest {
+			if session.CaptureMethod == models.CheckoutCaptureMethodManual {
+				return s.authorizeSession(tx, session, payerAccountID, description)
+			}
+			return s.approveSandboxSession(tx, session, payerAccountID, description)
+		}
+
+		var payer models.Account
+		if err := tx.Where("connect_id = ?", payerAccountID).First(&payer).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NewNotFoundError("Account not found [" + ErrCodeAccountNotFound + "]")
+			}
+			return errors.NewInternalServerError(err, "Failed to get account")
+		}
+		if err := s.transactionService.checkTransactionLimits(&payer, models.TransactionTypePayment, session.AmountGsaltUnits); err != nil {
+			return err
+		}
+
+		if session.CaptureMethod == models.CheckoutCaptureMethodManual {
+			return s.authorizeSession(tx, session, payerAccountID, description)
+		}
+
+		now := time.Now()
+		payment := s.transactionService.createBaseTransaction(payerAccountID, models.TransactionTypePayment, session.AmountGsaltUnits, models.TransactionStatusCompleted, &description)
+		checkoutRef := "CHECKOUT-" + session.ID.String()
+		payment.DestinationAccountID = &session.MerchantID
+		payment.ExternalReferenceID = &checkoutRef
+		payment.PaymentStatus = models.PaymentStatusCompleted
+		payment.CompletedAt = &now
+
+		if err := tx.Create(payment).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to create payment transaction")
+		}
+
+		entry, err := s.ledgerService.RecordMerchantPayment(tx, payerAccountID, session.MerchantID, session.AmountGsaltUnits, session.FeeGsaltUnits, payment.ID.String(), &description)
+		if err != nil {
+			return err
+		}
+
+		payment.JournalEntryID = &entry.ID
+		if err := tx.Save(payment).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update payment transaction")
+		}
+
+		session.PayerAccountID = &payerAccountID
+		session.TransactionID = &payment.ID
+		session.JournalEntryID = &entry.ID
+		if err := s.closeSession(tx, session, models.CheckoutSessionStatusCompleted); err != nil {
+			return err
+		}
+
+		if err := s.auditService.LogTransactionStatusChangeTx(tx, payment.ID, nil, models.TransactionStatusCompleted, "Checkout session approved", map[string]interface{}{
+			"checkout_session_id":    session.ID,
+			"merchant_id":            session.MerchantID,
+			"fee_gsalt_units":        session.FeeGsaltUnits,
+			"net_amount_gsalt_units": session.NetAmountGsaltUnits,
+		}, &payerAccountID); err != nil {
+			return err
+		}
+
+		return s.webhookService.Enqueue(tx, session.MerchantID, models.WebhookEventPaymentCompleted, map[string]interface{}{
+			"checkout_session": session,
+			"transaction":      payment,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.signResult(session)
+}
+
+// approveSandboxSession pays a test mode session on the sandbox ledger. Real balances, daily
+// limits and transactions are left untouched.
+func (s *CheckoutService) approveSandboxSession(tx *gorm.DB, session *models.CheckoutSession, payerAccountID uuid.UUID, description string) error {
+	entry, err := s.ledgerService.RecordSandboxPayment(tx, payerAccountID, session.MerchantID, session.AmountGsaltUnits, session.FeeGsaltUnits, "CHECKOUT-"+session.ID.String(), &description)
+	if err != nil {
+		return err
+	}
+
+	session.PayerAccountID = &payerAccountID
+	session.JournalEntryID = &entry.ID
+	if err := s.closeSession(tx, session, models.CheckoutSessionStatusCompleted); err != nil {
+		return err
+	}
+
+	return s.webhookService.Enqueue(tx, session.MerchantID, models.WebhookEventPaymentCompleted, map[string]interface{}{
+		"checkout_session": session,
+	})
+}
+
+// authorizeSession approves a manual capture session by holding its amount on the payer's
+// balance against a PENDING payment. Test mode authorizations hold nothing; the sandbox wallet
+// is only debited on capture.
+func (s *CheckoutService) authorizeSession(tx *gorm.DB, session *models.CheckoutSession, payerAccountID uuid.UUID, description string) error {
+	now := time.Now()
+	authorizedAmount := session.AmountGsaltUnits
+	authorizationExpiresAt := now.Add(checkoutAuthorizationTTL)
+
+	var payment *models.Transaction
+	if session.Environment == models.APIKeyEnvironmentLive {
+		payment = s.transactionService.createBaseTransaction(payerAccountID, models.TransactionTypePayment, session.AmountGsaltUnits, models.TransactionStatusPending, &description)
+		checkoutRef := "CHECKOUT-" + session.ID.String()
+		payment.DestinationAccountID = &session.MerchantID
+		payment.ExternalReferenceID = &checkoutRef
+
+		if err := tx.Create(payment).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to create payment transaction")
+		}
+
+		hold, err := s.ledgerService.PlaceHold(tx, payerAccountID, session.AmountGsaltUnits, payment.ID.String(), &payment.ID, &description)
+		if err != nil {
+			return err
+		}
+
+		session.TransactionID = &payment.ID
+		session.HoldID = &hold.ID
+
+		if err := s.auditService.LogTransactionStatusChangeTx(tx, payment.ID, nil, models.TransactionStatusPending, "Checkout session authorized", map[string]interface{}{
+			"checkout_session_id": session.ID,
+			"merchant_id":         session.MerchantID,
+			"hold_id":             hold.ID,
+		}, &payerAccountID); err != nil {
+			return err
+		}
+	}
+
+	session.PayerAccountID = &payerAccountID
+	session.AuthorizedAmountGsaltUnits = &authorizedAmount
+	session.AuthorizedAt = &now
+	session.AuthorizationExpiresAt = &authorizationExpiresAt
+	session.Status = models.CheckoutSessionStatusAuthorized
+	if err := tx.Save(session).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to update checkout session")
+	}
+
+	data := map[string]interface{}{"checkout_session": session}
+	if payment != nil {
+		data["transaction"] = payment
+	}
+	return s.webhookService.Enqueue(tx, session.MerchantID, models.WebhookEventPaymentAuthorized, data)
+}
+
+// CaptureSession lets the merchant collect an authorized session. Capturing less than the
+// authorized amount releases the rest of the hold; the merchant fee is taken from the captured
+// amount.
+func (s *CheckoutService) CaptureSession(apiKey *models.MerchantAPIKey, sessionId string, req *models.CheckoutCaptureRequest) (*models.CheckoutSession, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	var session *models.CheckoutSession
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		session, err = s.lockAuthorizedSession(tx, sessionId)
+		if err != nil {
+			return err
+		}
+		if !ownsSession(apiKey, session) {
+			return errors.NewNotFoundError("Checkout session not found")
+		}
+		if time.Now().After(*session.AuthorizationExpiresAt) {
+			return errors.NewAppError(http.StatusConflict, "Checkout authorization has expired ["+ErrCodeCheckoutSessionNotAuthorized+"]")
+		}
+
+		amountGsaltUnits := *session.AuthorizedAmountGsaltUnits
+		if req.AmountGsalt != nil {
+			amountGsaltUnits, err = gsaltToUnits(*req.AmountGsalt, "amount")
+			if err != nil {
+				return err
+			}
+			if amountGsaltUnits > *session.AuthorizedAmountGsaltUnits {
+				return errors.NewBadRequestError(fmt.Sprintf("Capture amount of %d units exceeds the authorized %d units", amountGsaltUnits, *session.AuthorizedAmountGsaltUnits))
+			}
+		}
+		feeGsaltUnits := s.calculateFee(amountGsaltUnits)
+		description := checkoutDescription(session)
+
+		data := map[string]interface{}{"checkout_session": session}
+		if session.Environment == models.APIKeyEnvironmentTest {
+			entry, err := s.ledgerService.RecordSandboxPayment(tx, *session.PayerAccountID, session.MerchantID, amountGsaltUnits, feeGsaltUnits, "CHECKOUT-"+session.ID.String(), &description)
+			if err != nil {
+				return err
+			}
+			session.JournalEntryID = &entry.ID
+		} else {
+			payment, err := s.lockSessionPayment(tx, session)
+			if err != nil {
+				return err
+			}
+
+			if _, err := s.ledgerService.CaptureHold(tx, *session.HoldID, amountGsaltUnits); err != nil {
+				return err
+			}
+			entry, err := s.ledgerService.RecordMerchantPayment(tx, payment.AccountID, session.MerchantID, amountGsaltUnits, feeGsaltUnits, payment.ID.String(), &description)
+			if err != nil {
+				return err
+			}
+
+			now := time.Now()
+			oldStatus := payment.Status
+			payment.AmountGsaltUnits = amountGsaltUnits
+			payment.TotalAmountGsaltUnits = amountGsaltUnits
+			payment.Status = models.TransactionStatusCompleted
+			payment.PaymentStatus = models.PaymentStatusCompleted
+			payment.CompletedAt = &now
+			payment.JournalEntryID = &entry.ID
+			if err := tx.Save(payment).Error; err != nil {
+				return errors.NewInternalServerError(err, "Failed to update payment transaction")
+			}
+
+			if err := s.auditService.LogTransactionStatusChangeTx(tx, payment.ID, &oldStatus, models.TransactionStatusCompleted, "Checkout session captured", map[string]interface{}{
+				"checkout_session_id":    session.ID,
+				"merchant_id":            session.MerchantID,
+				"authorized_gsalt_units": *session.AuthorizedAmountGsaltUnits,
+				"fee_gsalt_units":        feeGsaltUnits,
+				"net_amount_gsalt_units": amountGsaltUnits - feeGsaltUnits,
+			}, &apiKey.MerchantID); err != nil {
+				return err
+			}
+
+			session.JournalEntryID = &entry.ID
+			data["transaction"] = payment
+		}
+
+		session.AmountGsaltUnits = amountGsaltUnits
+		session.FeeGsaltUnits = feeGsaltUnits
+		session.NetAmountGsaltUnits = amountGsaltUnits - feeGsaltUnits
+		if err := s.closeSession(tx, session, models.CheckoutSessionStatusCompleted); err != nil {
+			return err
+		}
+
+		return s.webhookService.Enqueue(tx, session.MerchantID, models.WebhookEventPaymentCompleted, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// VoidSession lets the merchant cancel an authorized session and release the payer's hold
+func (s *CheckoutService) VoidSession(apiKey *models.MerchantAPIKey, sessionId string) (*models.CheckoutSession, error) {
+	var session *models.CheckoutSession
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		session, err = s.lockAuthorizedSession(tx, sessionId)
+		if err != nil {
+			return err
+		}
+		if !ownsSession(apiKey, session) {
+			return errors.NewNotFoundError("Checkout session not found")
+		}
+		return s.voidAuthorization(tx, session, models.CheckoutSessionStatusCancelled, "Checkout authorization voided", &apiKey.MerchantID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// voidAuthorization releases the hold of an authorized session, cancels its payment and moves
+// the session to status
+func (s *CheckoutService) voidAuthorization(tx *gorm.DB, session *models.CheckoutSession, status models.CheckoutSessionStatus, reason string, changedBy *uuid.UUID) error {
+	data := map[string]interface{}{"checkout_session": session}
+	if session.Environment == models.APIKeyEnvironmentLive {
+		payment, err := s.lockSessionPayment(tx, session)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.ledgerService.ReleaseHold(tx, *session.HoldID); err != nil {
+			return err
+		}
+
+		oldStatus := payment.Status
+		payment.Status = models.TransactionStatusCancelled
+		payment.PaymentStatus = models.PaymentStatusCancelled
+		if err := tx.Save(payment).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update payment transaction")
+		}
+
+		if err := s.auditService.LogTransactionStatusChangeTx(tx, payment.ID, &oldStatus, models.TransactionStatusCancelled, reason, map[string]interface{}{
+			"checkout_session_id": session.ID,
+			"merchant_id":         session.MerchantID,
+		}, changedBy); err != nil {
+			return err
+		}
+
+		data["transaction"] = payment
+	}
+
+	if err := s.closeSession(tx, session, status); err != nil {
+		return err
+	}
+
+	return s.webhookService.Enqueue(tx, session.MerchantID, models.WebhookEventPaymentVoided, data)
+}
+
+// DeclineSession lets the payer cancel an open session and return to the merchant.
+// A session that already has a payer can only be declined by that payer, and declining
+// does not record the caller as the payer.
+func (s *CheckoutService) DeclineSession(sessionId string, payerAccountID uuid.UUID) (*models.CheckoutResult, error) {
+	var session *models.CheckoutSession
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		session, err = s.lockOpenSession(tx, sessionId)
+		if err != nil {
+			return err
+		}
+		if session.PayerAccountID != nil && *session.PayerAccountID != payerAccountID {
+			return errors.NewForbiddenError("Checkout session belongs to another payer")
+		}
+		return s.closeSession(tx, session, models.CheckoutSessionStatusCancelled)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.signResult(session)
+}
+
+// ExpireSessions marks open sessions past their expiry as expired and voids authorizations
+// that were not captured in time
+func (s *CheckoutService) ExpireSessions(ctx context.Context) error {
+	now := time.Now()
+
+	if err := s.db.WithContext(ctx).Model(&models.CheckoutSession{}).
+		Where("status = ? AND expires_at < ?", models.CheckoutSessionStatusOpen, now).
+		Updates(map[string]interface{}{
+			"status":     models.CheckoutSessionStatusExpired,
+			"updated_at": now,
+		}).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to expire checkout sessions")
+	}
+
+	var sessionIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).
+		Model(&models.CheckoutSession{}).
+		Where("status = ? AND authorization_expires_at < ?", models.CheckoutSessionStatusAuthorized, now).
+		Order("authorization_expires_at").
+		Limit(checkoutAuthorizationBatchSize).
+		Pluck("id", &sessionIDs).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to get lapsed checkout authorizations")
+	}
+
+	voided := 0
+	for _, sessionID := range sessionIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			session, err := s.lockAuthorizedSession(tx, sessionID.String())
+			if err != nil {
+				return err
+			}
+			return s.voidAuthorization(tx, session, models.CheckoutSessionStatusExpired, "Checkout authorization expired", nil)
+		})
+		if err != nil {
+			logrus.WithField("checkout_session_id", sessionID).WithError(err).Warn("Failed to void lapsed checkout authorization")
+			continue
+		}
+		voided++
+	}
+
+	if len(sessionIDs) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"candidates": len(sessionIDs),
+			"voided":     voided,
+		}).Info("Voided lapsed checkout authorizations")
+	}
+
+	return nil
+}
+
+// signResult builds the signed result of a closed session
+func (s *CheckoutService) signResult(session *models.CheckoutSession) (*models.CheckoutResult, error) {
+	timestamp := time.Now().Unix()
+	transactionID := ""
+	if session.TransactionID != nil {
+		transactionID = session.TransactionID.String()
+	}
+
+	message := fmt.Sprintf("%s.%s.%s.%d.%d", session.ID, session.Status, transactionID, session.AmountGsaltUnits, timestamp)
+	mac := hmac.New(sha256.New, []byte(session.SigningSecret))
+	mac.Write([]byte(message))
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	redirectURL, err := url.Parse(session.RedirectURL)
+	if err != nil {
+		return nil, errors.NewInternalServerError(err, "Invalid checkout redirect URL")
+	}
+	query := redirectURL.Query()
+	query.Set("checkout_session_id", session.ID.String())
+	query.Set("status", string(session.Status))
+	if transactionID != "" {
+		query.Set("transaction_id", transactionID)
+	}
+	query.Set("amount_gsalt_units", strconv.FormatInt(session.AmountGsaltUnits, 10))
+	query.Set("timestamp", strconv.FormatInt(timestamp, 10))
+	query.Set("signature", signature)
+	redirectURL.RawQuery = query.Encode()
+
+	return &models.CheckoutResult{
+		SessionID:        session.ID,
+		ReferenceID:      session.ReferenceID,
+		Status:           session.Status,
+		TransactionID:    session.TransactionID,
+		AmountGsaltUnits: session.AmountGsaltUnits,
+		Timestamp:        timestamp,
+		Signature:        signature,
+		RedirectURL:      redirectURL.String(),
+	}, nil
+}
+
+// closeSession moves an open session to its final status
+func (s *CheckoutService) closeSession(tx *gorm.DB, session *models.CheckoutSession, status models.CheckoutSessionStatus) error {
+	now := time.Now()
+	session.Status = status
+	switch status {
+	case models.CheckoutSessionStatusCompleted:
+		session.CompletedAt = &now
+	case models.CheckoutSessionStatusCancelled:
+		session.CancelledAt = &now
+	}
+
+	if err := tx.Save(session).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to update checkout session")
+	}
+	return nil
+}
+
+// lockOpenSession locks a session that can still be approved or cancelled
+func (s *CheckoutService) lockOpenSession(tx *gorm.DB, sessionId string) (*models.CheckoutSession, error) {
+	session, err := s.getSession(tx.Clauses(clause.Locking{Strength: "UPDATE"}), sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status != models.CheckoutSessionStatusOpen {
+		return nil, errors.NewAppError(http.StatusConflict, fmt.Sprintf("Checkout session is %s [%s]", session.Status, ErrCodeCheckoutSessionClosed))
+	}
+	if session.IsExpired() {
+		return nil, errors.NewAppError(http.StatusConflict, "Checkout session has expired ["+ErrCodeCheckoutSessionClosed+"]")
+	}
+
+	return session, nil
+}
+
+// lockAuthorizedSession locks a session that holds an authorization to capture or void
+func (s *CheckoutService) lockAuthorizedSession(tx *gorm.DB, sessionId string) (*models.CheckoutSession, error) {
+	session, err := s.getSession(tx.Clauses(clause.Locking{Strength: "UPDATE"}), sessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Status != models.CheckoutSessionStatusAuthorized {
+		return nil, errors.NewAppError(http.StatusConflict, fmt.Sprintf("Checkout session is %s [%s]", session.Status, ErrCodeCheckoutSessionNotAuthorized))
+	}
+
+	return session, nil
+}
+
+// lockSessionPayment locks the PENDING payment of an authorized live session
+func (s *CheckoutService) lockSessionPayment(tx *gorm.DB, session *models.CheckoutSession) (*models.Transaction, error) {
+	if session.TransactionID == nil || session.HoldID == nil {
+		return nil, errors.NewInternalServerError(fmt.Errorf("checkout session %s has no payment hold", session.ID), "Authorized checkout session has no payment hold")
+	}
+
+	var payment models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", *session.TransactionID).First(&payment).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get payment transaction")
+	}
+	if payment.Status != models.TransactionStatusPending {
+		return nil, errors.NewAppError(http.StatusConflict, fmt.Sprintf("Checkout payment is already %s [%s]", payment.Status, ErrCodeCheckoutSessionNotAuthorized))
+	}
+
+	return &payment, nil
+}
+
+// checkoutDescription is the description of the payment made for a session
+func checkoutDescription(session *models.CheckoutSession) string {
+	if session.Description != nil {
+		return *session.Description
+	}
+	return fmt.Sprintf("Checkout payment to merchant %s", session.MerchantID)
+}
+
+// ownsSession reports whether a session belongs to the merchant and environment of apiKey
+func ownsSession(apiKey *models.MerchantAPIKey, session *models.CheckoutSession) bool {
+	return session.MerchantID == apiKey.MerchantID && session.Environment == apiKey.Environment
+}
+
+func (s *CheckoutService) getSession(db *gorm.DB, sessionId string) (*models.CheckoutSession, error) {
+	sessionUUID, err := uuid.Parse(sessionId)
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid checkout session ID")
+	}
+
+	var session models.CheckoutSession
+	if err := db.Where("id = ?", sessionUUID).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Checkout session not found")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get checkout session")
+	}
+
+	return &session, nil
+}
+
+func (s *CheckoutService) getAccount(db *gorm.DB, accountID uuid.UUID) (*models.Account, error) {
+	var account models.Account
+	if err := db.Where("connect_id = ?", accountID).First(&account).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Merchant account not found [" + ErrCodeAccountNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get merchant account")
+	}
+	return &account, nil
+}
+
+// calculateFee returns the merchant fee for an amount, rounded to whole units
+func (s *CheckoutService) calculateFee(amountGsaltUnits int64) int64 {
+	return decimal.NewFromInt(amountGsaltUnits).Mul(checkoutFeeRate).Round(0).IntPart()
+}
+
+// generateSigningSecret returns a random 32 byte hex secret
+func generateSigningSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.NewInternalServerError(err, "Failed to generate signing secret")
+	}
+	return hex.EncodeToString(b), nil
+}
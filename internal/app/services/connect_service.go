@@ -3,6 +3,7 @@ package services
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strings"
 
 	"github.com/safatanc/gsalt-core/internal/app/errors"
@@ -55,7 +56,21 @@ func (s *ConnectService) GetCurrentUser(accessToken string) (*models.ConnectUser
 }
 
 func (s *ConnectService) GetUser(connectId string) (*models.ConnectUser, error) {
-	req, err := http.NewRequest("GET", infrastructures.Config.CONNECT_BASE_URL+"/users/"+connectId, nil)
+	return s.getUser("/users/" + url.PathEscape(connectId))
+}
+
+// GetUserByUsername looks up a Connect user by username
+func (s *ConnectService) GetUserByUsername(username string) (*models.ConnectUser, error) {
+	return s.getUser("/users/username/" + url.PathEscape(username))
+}
+
+// GetUserByEmail looks up a Connect user by email address
+func (s *ConnectService) GetUserByEmail(email string) (*models.ConnectUser, error) {
+	return s.getUser("/users/email/" + url.PathEscape(email))
+}
+
+func (s *ConnectService) getUser(path string) (*models.ConnectUser, error) {
+	req, err := http.NewRequest("GET", infrastructures.Config.CONNECT_BASE_URL+path, nil)
 	if err != nil {
 		return nil, err
 	}
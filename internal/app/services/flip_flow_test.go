@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"github.com/safatanc/gsalt-core/internal/infrastructures/flipsim"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const flipFlowWebhookToken = "flip-flow-test-token"
+
+// flipFlowEnv wires the services used by topups and withdrawals against a migrated
+// database, a fake Connect API and the Flip simulator
+type flipFlowEnv struct {
+	db                 *gorm.DB
+	sim                *flipsim.Server
+	transactionService *TransactionService
+}
+
+// newFlipFlowEnv skips the test unless TEST_DATABASE_URL points at a migrated database
+func newFlipFlowEnv(t *testing.T) *flipFlowEnv {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to connect database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to get database handle: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	connect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.WebResponse[models.ConnectUser]{
+			Success: true,
+			Data: models.ConnectUser{
+				FullName: "Flip Flow Test",
+				Email:    "flip-flow@example.com",
+			},
+		})
+	}))
+	t.Cleanup(connect.Close)
+
+	flipConfig := &infrastructures.FlipConfig{
+		Environment:  "simulator",
+		WebhookToken: flipFlowWebhookToken,
+	}
+	previousConfig := infrastructures.Config
+	infrastructures.Config = &infrastructures.AppConfig{
+		CONNECT_BASE_URL: connect.URL,
+		FlipConfig:       flipConfig,
+	}
+	t.Cleanup(func() { infrastructures.Config = previousConfig })
+
+	flipClient := infrastructures.NewFlipClientWithConfig(flipConfig)
+	t.Cleanup(flipClient.Simulator.Close)
+
+	validator := infrastructures.NewValidator()
+	connectService := NewConnectService()
+	accountService := NewAccountService(db, validator, connectService)
+	ledgerService := NewLedgerService(db)
+	flipService := NewFlipService(flipClient)
+	paymentMethodService := NewPaymentMethodService(db, validator)
+	paymentService := NewPaymentService(db, validator)
+	auditService := NewAuditService(db)
+	providerRegistry := NewPaymentProviderRegistry(flipService)
+	merchantWebhookService := NewMerchantWebhookService(db, validator)
+	transactionLimitService := NewTransactionLimitService(db, validator, auditService)
+	transactionService := NewTransactionService(db, validator, accountService, flipService, connectService, paymentMethodService, paymentService, auditService, ledgerService, providerRegistry, merchantWebhookService, transactionLimitService)
+	inboundWebhookService := NewInboundWebhookService(db, providerRegistry, paymentService, transactionService)
+
+	// Deliver simulator callbacks the way the webhook handlers do, failing the callback on error
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhook/flip", flipFlowCallback(inboundWebhookService.HandlePaymentWebhook))
+	mux.HandleFunc("POST /webhook/flip/disbursement", flipFlowCallback(inboundWebhookService.HandleDisbursementWebhook))
+	callbacks := httptest.NewServer(mux)
+	t.Cleanup(callbacks.Close)
+	flipClient.Simulator.SetCallbackURLs(callbacks.URL+"/webhook/flip", callbacks.URL+"/webhook/flip/disbursement")
+
+	return &flipFlowEnv{
+		db:                 db,
+		sim:                flipClient.Simulator,
+		transactionService: transactionService,
+	}
+}
+
+func flipFlowCallback(handle func(context.Context, string, models.ProviderWebhookRequest) (*models.InboundWebhookEvent, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		req := models.ProviderWebhookRequest{
+			ContentType: r.Header.Get("Content-Type"),
+			Headers:     make(map[string]string),
+			Body:        body,
+		}
+		for key := range r.Header {
+			req.Headers[key] = r.Header.Get(key)
+		}
+
+		if _, err := handle(r.Context(), models.PaymentProviderFlip, req); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// createAccount inserts an active, fully verified personal account
+func (e *flipFlowEnv) createAccount(t *testing.T) uuid.UUID {
+	t.Helper()
+
+	account := models.Account{
+		ConnectID:   uuid.New(),
+		AccountType: models.AccountTypePersonal,
+		Status:      models.AccountStatusActive,
+		KYCStatus:   models.KYCStatusVerified,
+		KYCLevel:    models.KYCLevelFull,
+	}
+	if err := e.db.Create(&account).Error; err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	return account.ConnectID
+}
+
+// topup creates a topup and pays its Flip bill through the simulator
+func (e *flipFlowEnv) topup(t *testing.T, accountID uuid.UUID, amountGsaltUnits int64) *models.Transaction {
+	t.Helper()
+
+	transaction, err := e.transactionService.ProcessTopup(accountID.String(), amountGsaltUnits, "VA_MANDIRI", nil)
+	if err != nil {
+		t.Fatalf("ProcessTopup: %v", err)
+	}
+	if transaction.Status != models.TransactionStatusPending {
+		t.Fatalf("topup status = %s, want %s", transaction.Status, models.TransactionStatusPending)
+	}
+
+	linkID, err := strconv.Atoi(e.providerPaymentID(t, transaction.ID))
+	if err != nil {
+		t.Fatalf("bill link ID is not numeric: %v", err)
+	}
+	if _, err := e.sim.PayBill(linkID); err != nil {
+		t.Fatalf("PayBill: %v", err)
+	}
+
+	return e.transaction(t, transaction.ID)
+}
+
+func (e *flipFlowEnv) providerPaymentID(t *testing.T, transactionID uuid.UUID) string {
+	t.Helper()
+
+	var paymentDetails models.PaymentDetails
+	if err := e.db.Where("transaction_id = ?", transactionID).First(&paymentDetails).Error; err != nil {
+		t.Fatalf("failed to get payment details: %v", err)
+	}
+	if paymentDetails.ProviderPaymentID == nil {
+		t.Fatalf("payment details of %s have no provider payment ID", transactionID)
+	}
+	return *paymentDetails.ProviderPaymentID
+}
+
+// disbursementID returns the Flip disbursement created for a withdrawal
+func (e *flipFlowEnv) disbursementID(t *testing.T, transactionID uuid.UUID) int {
+	t.Helper()
+
+	disbursementID, err := strconv.Atoi(e.providerPaymentID(t, transactionID))
+	if err != nil {
+		t.Fatalf("disbursement ID is not numeric: %v", err)
+	}
+	return disbursementID
+}
+
+func (e *flipFlowEnv) transaction(t *testing.T, transactionID uuid.UUID) *models.Transaction {
+	t.Helper()
+
+	var transaction models.Transaction
+	if err := e.db.Where("id = ?", transactionID).First(&transaction).Error; err != nil {
+		t.Fatalf("failed to get transaction: %v", err)
+	}
+	return &transaction
+}
+
+func (e *flipFlowEnv) assertBalance(t *testing.T, accountID uuid.UUID, balance, heldBalance int64) {
+	t.Helper()
+
+	var account models.Account
+	if err := e.db.Where("connect_id = ?", accountID).First(&account).Error; err != nil {
+		t.Fatalf("failed to get account: %v", err)
+	}
+	if account.Balance != balance || account.HeldBalance != heldBalance {
+		t.Fatalf("balance = %d held %d, want %d held %d", account.Balance, account.HeldBalance, balance, heldBalance)
+	}
+}
+
+func TestFlipTopupSettlesOnBillPayment(t *testing.T) {
+	env := newFlipFlowEnv(t)
+	accountID := env.createAccount(t)
+
+	transaction := env.topup(t, accountID, 10000)
+
+	if transaction.Status != models.TransactionStatusCompleted {
+		t.Errorf("topup status = %s, want %s", transaction.Status, models.TransactionStatusCompleted)
+	}
+	if transaction.PaymentStatus != models.PaymentStatusCompleted {
+		t.Errorf("topup payment status = %s, want %s", transaction.PaymentStatus, models.PaymentStatusCompleted)
+	}
+	if transaction.JournalEntryID == nil {
+		t.Error("topup has no journal entry")
+	}
+	env.assertBalance(t, accountID, 10000, 0)
+}
+
+func TestFlipWithdrawalSettlesOnDisbursementCallback(t *testing.T) {
+	env := newFlipFlowEnv(t)
+	accountID := env.createAccount(t)
+	env.topup(t, accountID, 10000)
+
+	withdrawal, err := env.transactionService.ProcessWithdrawal(accountID.String(), 4000, "bca", "1234567890", "Flip Flow Test", nil, nil)
+	if err != nil {
+		t.Fatalf("ProcessWithdrawal: %v", err)
+	}
+	if withdrawal.Status != models.TransactionStatusPending {
+		t.Fatalf("withdrawal status = %s, want %s", withdrawal.Status, models.TransactionStatusPending)
+	}
+	env.assertBalance(t, accountID, 10000, 4000)
+
+	disbursementID := env.disbursementID(t, withdrawal.ID)
+	if _, err := env.sim.CompleteDisbursement(disbursementID, models.DisbursementStatusDone); err != nil {
+		t.Fatalf("CompleteDisbursement: %v", err)
+	}
+
+	withdrawal = env.transaction(t, withdrawal.ID)
+	if withdrawal.Status != models.TransactionStatusCompleted {
+		t.Errorf("withdrawal status = %s, want %s", withdrawal.Status, models.TransactionStatusCompleted)
+	}
+	if withdrawal.PaymentStatus != models.PaymentStatusCompleted {
+		t.Errorf("withdrawal payment status = %s, want %s", withdrawal.PaymentStatus, models.PaymentStatusCompleted)
+	}
+	if withdrawal.JournalEntryID == nil {
+		t.Error("withdrawal has no journal entry")
+	}
+	env.assertBalance(t, accountID, 6000, 0)
+}
+
+func TestFlipWithdrawalReleasesHoldOnCancelledDisbursement(t *testing.T) {
+	env := newFlipFlowEnv(t)
+	accountID := env.createAccount(t)
+	env.topup(t, accountID, 10000)
+
+	withdrawal, err := env.transactionService.ProcessWithdrawal(accountID.String(), 4000, "bca", "1234567890", "Flip Flow Test", nil, nil)
+	if err != nil {
+		t.Fatalf("ProcessWithdrawal: %v", err)
+	}
+	env.assertBalance(t, accountID, 10000, 4000)
+
+	disbursementID := env.disbursementID(t, withdrawal.ID)
+	if _, err := env.sim.CompleteDisbursement(disbursementID, models.DisbursementStatusCancelled); err != nil {
+		t.Fatalf("CompleteDisbursement: %v", err)
+	}
+
+	withdrawal = env.transaction(t, withdrawal.ID)
+	if withdrawal.Status != models.TransactionStatusCancelled {
+		t.Errorf("withdrawal status = %s, want %s", withdrawal.Status, models.TransactionStatusCancelled)
+	}
+	if withdrawal.JournalEntryID != nil {
+		t.Error("cancelled withdrawal was posted to the ledger")
+	}
+	env.assertBalance(t, accountID, 10000, 0)
+}
+
+func TestReconcilePayoutsRecordsUnrecordedDisbursement(t *testing.T) {
+	env := newFlipFlowEnv(t)
+	accountID := env.createAccount(t)
+	env.topup(t, accountID, 10000)
+
+	withdrawal, err := env.transactionService.ProcessWithdrawal(accountID.String(), 4000, "bca", "1234567890", "Flip Flow Test", nil, nil)
+	if err != nil {
+		t.Fatalf("ProcessWithdrawal: %v", err)
+	}
+	disbursementID := env.disbursementID(t, withdrawal.ID)
+
+	// Simulate a process that stopped after Flip created the disbursement
+	if err := env.db.Model(&models.PaymentDetails{}).Where("transaction_id = ?", withdrawal.ID).Update("provider_payment_id", nil).Error; err != nil {
+		t.Fatalf("failed to clear payout ID: %v", err)
+	}
+	if err := env.db.Model(&models.Transaction{}).Where("id = ?", withdrawal.ID).Update("created_at", time.Now().Add(-time.Hour)).Error; err != nil {
+		t.Fatalf("failed to backdate withdrawal: %v", err)
+	}
+
+	if err := env.transactionService.ReconcilePayouts(context.Background()); err != nil {
+		t.Fatalf("ReconcilePayouts: %v", err)
+	}
+	if got := env.disbursementID(t, withdrawal.ID); got != disbursementID {
+		t.Fatalf("recorded disbursement = %d, want %d", got, disbursementID)
+	}
+	env.assertBalance(t, accountID, 10000, 4000)
+
+	if _, err := env.sim.CompleteDisbursement(disbursementID, models.DisbursementStatusDone); err != nil {
+		t.Fatalf("CompleteDisbursement: %v", err)
+	}
+	if status := env.transaction(t, withdrawal.ID).Status; status != models.TransactionStatusCompleted {
+		t.Errorf("withdrawal status = %s, want %s", status, models.TransactionStatusCompleted)
+	}
+	env.assertBalance(t, accountID, 6000, 0)
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,7 +10,9 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/safatanc/gsalt-core/internal/app/errors"
 	"github.com/safatanc/gsalt-core/internal/app/models"
 	"github.com/safatanc/gsalt-core/internal/infrastructures"
 )
@@ -204,6 +207,57 @@ func (s *FlipService) GetBill(ctx context.Context, billID int) (*models.GetBillR
 	return &billStatus, nil
 }
 
+// EditBill updates an existing bill, e.g. to deactivate it
+// PUT {{base_url_v2}}/pwf/:bill_id/bill
+func (s *FlipService) EditBill(ctx context.Context, billID int, req models.EditBillRequest) (*models.GetBillResponse, error) {
+	formData := url.Values{}
+	if req.Title != "" {
+		formData.Set("title", req.Title)
+	}
+	if req.Amount > 0 {
+		formData.Set("amount", strconv.FormatInt(req.Amount, 10))
+	}
+	if req.ExpiredDate != "" {
+		formData.Set("expired_date", req.ExpiredDate)
+	}
+	if req.RedirectURL != "" {
+		formData.Set("redirect_url", req.RedirectURL)
+	}
+	if req.Status != "" {
+		formData.Set("status", req.Status)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "PUT", s.client.GetFullURL(fmt.Sprintf("/v2/pwf/%d/bill", billID)), strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Authorization", s.client.GetAuthHeader())
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, s.handleAPIError(resp.StatusCode, body)
+	}
+
+	var bill models.GetBillResponse
+	if err := json.Unmarshal(body, &bill); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &bill, nil
+}
+
 // GetAllBills retrieves all bills
 // GET {{base_url_v2}}/pwf/bill
 func (s *FlipService) GetAllBills(ctx context.Context) ([]models.GetBillResponse, error) {
@@ -425,7 +479,8 @@ func (s *FlipService) GetDisbursementByID(ctx context.Context, id string) (*mode
 	return &disbursement, nil
 }
 
-// GetDisbursementByIdempotencyKey retrieves a disbursement by idempotency key
+// GetDisbursementByIdempotencyKey retrieves a disbursement by idempotency key. It returns nil
+// without an error when no disbursement was created with the key.
 // GET {{base_url_v2}}/get-disbursement
 func (s *FlipService) GetDisbursementByIdempotencyKey(ctx context.Context, idempotencyKey string) (*models.DisbursementResponse, error) {
 	params := url.Values{}
@@ -451,6 +506,9 @@ func (s *FlipService) GetDisbursementByIdempotencyKey(ctx context.Context, idemp
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, s.handleAPIError(resp.StatusCode, body)
 	}
@@ -463,6 +521,306 @@ func (s *FlipService) GetDisbursementByIdempotencyKey(ctx context.Context, idemp
 	return &disbursement, nil
 }
 
+// ========== PAYMENT PROVIDER IMPLEMENTATION ==========
+
+// flipTimeLayout is the datetime format used by Flip for bill expiry and callbacks
+const flipTimeLayout = "2006-01-02 15:04"
+
+// Code returns the provider code for Flip
+func (s *FlipService) Code() string {
+	return models.PaymentProviderFlip
+}
+
+// CreateCharge creates a single-use Flip bill for the charge
+func (s *FlipService) CreateCharge(ctx context.Context, req models.ProviderChargeRequest) (*models.ProviderChargeResponse, error) {
+	redirectURL := req.RedirectURL
+	if redirectURL == "" {
+		redirectURL = s.client.GetDefaultRedirectURL()
+	}
+
+	billResp, err := s.CreateBill(ctx, models.CreateBillRequest{
+		Title:             req.Title,
+		Amount:            req.Amount,
+		ReferenceID:       req.ReferenceID,
+		ChargeFee:         req.ChargeFee,
+		ExpiredDate:       req.ExpiresAt.Format(flipTimeLayout),
+		SenderPhoneNumber: req.CustomerPhone,
+		Type:              "SINGLE",
+		Step:              "3",
+		SenderName:        req.CustomerName,
+		SenderEmail:       req.CustomerEmail,
+		SenderBank:        req.MethodCode,
+		SenderBankType:    req.MethodType,
+		RedirectURL:       redirectURL,
+		ItemDetails:       req.Items,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chargeResp := &models.ProviderChargeResponse{
+		ProviderPaymentID: strconv.Itoa(billResp.LinkID),
+	}
+
+	if billResp.LinkURL != "" {
+		chargeResp.PaymentURL = &billResp.LinkURL
+	}
+
+	if billResp.ExpiredDate != nil {
+		// The expiry was formatted from a local time, so parse it back in the same zone
+		expiryTime, err := time.ParseInLocation(flipTimeLayout, *billResp.ExpiredDate, req.ExpiresAt.Location())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse expiry time: %w", err)
+		}
+		chargeResp.ExpiryTime = &expiryTime
+	}
+
+	if billResp.BillPayment != nil && billResp.BillPayment.ReceiverBankAccount != nil {
+		chargeResp.VirtualAccountNumber = &billResp.BillPayment.ReceiverBankAccount.AccountNumber
+	}
+
+	if raw, err := json.Marshal(billResp); err == nil {
+		chargeResp.RawResponse = raw
+	}
+
+	return chargeResp, nil
+}
+
+// GetChargeStatus maps the bill and its payments to a payment status
+func (s *FlipService) GetChargeStatus(ctx context.Context, providerPaymentID string) (*models.ProviderChargeStatus, error) {
+	billID, err := strconv.Atoi(providerPaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Flip bill ID %q: %w", providerPaymentID, err)
+	}
+
+	bill, err := s.GetBill(ctx, billID)
+	if err != nil {
+		return nil, err
+	}
+
+	chargeStatus := &models.ProviderChargeStatus{
+		ProviderPaymentID: providerPaymentID,
+		Status:            models.PaymentStatusPending,
+		Amount:            bill.Amount,
+	}
+
+	for _, payment := range bill.BillPayments {
+		status := mapFlipPaymentStatus(payment.Status)
+		chargeStatus.Status = status
+		if status == models.PaymentStatusCompleted {
+			paidAt := payment.Updated
+			chargeStatus.PaidAt = &paidAt
+			chargeStatus.Amount = payment.Amount
+			break
+		}
+	}
+
+	if raw, err := json.Marshal(bill); err == nil {
+		chargeStatus.RawResponse = raw
+	}
+
+	return chargeStatus, nil
+}
+
+// CancelCharge deactivates the bill so it no longer accepts payments
+func (s *FlipService) CancelCharge(ctx context.Context, providerPaymentID string) error {
+	billID, err := strconv.Atoi(providerPaymentID)
+	if err != nil {
+		return fmt.Errorf("invalid Flip bill ID %q: %w", providerPaymentID, err)
+	}
+
+	_, err = s.EditBill(ctx, billID, models.EditBillRequest{Status: "INACTIVE"})
+	return err
+}
+
+// ParseWebhook verifies and parses an accept-payment callback. Flip posts form data
+// with the JSON payload in "data" and the validation token in "token". A payment can be
+// reported more than once as its status changes, so the event ID combines the payment ID
+// and its status.
+func (s *FlipService) ParseWebhook(req models.ProviderWebhookRequest) (*models.ProviderWebhookEvent, error) {
+	data, err := s.verifyCallback(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var payload models.FlipWebhookPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, errors.NewBadRequestError("Invalid webhook payload")
+	}
+
+	if payload.ID == 0 || payload.BillLinkID == 0 {
+		return nil, errors.NewBadRequestError("Webhook payload is missing payment identifiers")
+	}
+
+	event := &models.ProviderWebhookEvent{
+		EventID:           fmt.Sprintf("%d:%s", payload.ID, payload.Status),
+		ProviderPaymentID: strconv.Itoa(payload.BillLinkID),
+		Status:            mapFlipPaymentStatus(payload.Status),
+		Amount:            payload.Amount,
+		RawPayload:        data,
+	}
+
+	if occurredAt, err := time.Parse("2006-01-02 15:04:05", payload.Updated); err == nil {
+		event.OccurredAt = &occurredAt
+	}
+
+	return event, nil
+}
+
+// verifyCallback checks the callback token against FlipConfig.WebhookToken and returns
+// the JSON payload. Requests are rejected when no token is configured.
+func (s *FlipService) verifyCallback(req models.ProviderWebhookRequest) ([]byte, error) {
+	expected := s.client.Config.WebhookToken
+	if expected == "" {
+		return nil, errors.NewUnauthorizedError("Webhook token is not configured")
+	}
+
+	if !strings.HasPrefix(req.ContentType, "application/x-www-form-urlencoded") {
+		return nil, errors.NewBadRequestError("Webhook must be form encoded")
+	}
+
+	form, err := url.ParseQuery(string(req.Body))
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid webhook form body")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(form.Get("token")), []byte(expected)) != 1 {
+		return nil, errors.NewUnauthorizedError("Invalid webhook token")
+	}
+
+	data := form.Get("data")
+	if data == "" {
+		return nil, errors.NewBadRequestError("Webhook payload is empty")
+	}
+
+	return []byte(data), nil
+}
+
+// CreatePayout creates a Flip disbursement
+func (s *FlipService) CreatePayout(ctx context.Context, req models.ProviderPayoutRequest) (*models.ProviderPayoutResponse, error) {
+	disbursement, err := s.CreateDisbursement(ctx, models.DisbursementRequest{
+		AccountNumber:  req.AccountNumber,
+		BankCode:       req.BankCode,
+		Amount:         req.Amount,
+		Remark:         req.Remark,
+		IdempotencyKey: req.IdempotencyKey,
+		Timestamp:      time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return disbursementToPayoutResponse(disbursement), nil
+}
+
+// GetPayoutStatus retrieves a Flip disbursement
+func (s *FlipService) GetPayoutStatus(ctx context.Context, providerPayoutID string) (*models.ProviderPayoutResponse, error) {
+	disbursement, err := s.GetDisbursementByID(ctx, providerPayoutID)
+	if err != nil {
+		return nil, err
+	}
+
+	return disbursementToPayoutResponse(disbursement), nil
+}
+
+// FindPayout looks up a Flip disbursement by its idempotency key
+func (s *FlipService) FindPayout(ctx context.Context, idempotencyKey string) (*models.ProviderPayoutResponse, error) {
+	disbursement, err := s.GetDisbursementByIdempotencyKey(ctx, idempotencyKey)
+	if err != nil || disbursement == nil {
+		return nil, err
+	}
+
+	return disbursementToPayoutResponse(disbursement), nil
+}
+
+// ParsePayoutWebhook verifies and parses a disbursement callback. Flip sends one callback
+// per status change, so the event ID combines the disbursement ID and its status.
+func (s *FlipService) ParsePayoutWebhook(req models.ProviderWebhookRequest) (*models.ProviderPayoutEvent, error) {
+	data, err := s.verifyCallback(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var disbursement models.DisbursementResponse
+	if err := json.Unmarshal(data, &disbursement); err != nil {
+		return nil, errors.NewBadRequestError("Invalid webhook payload")
+	}
+
+	if disbursement.ID == 0 || disbursement.Status == "" {
+		return nil, errors.NewBadRequestError("Webhook payload is missing disbursement identifiers")
+	}
+
+	payout := disbursementToPayoutResponse(&disbursement)
+	payout.RawResponse = data
+
+	return &models.ProviderPayoutEvent{
+		EventID: fmt.Sprintf("%d:%s", disbursement.ID, disbursement.Status),
+		Amount:  disbursement.Amount,
+		Payout:  *payout,
+	}, nil
+}
+
+// InquireBankAccount validates a bank account through Flip
+func (s *FlipService) InquireBankAccount(ctx context.Context, bankCode, accountNumber string) (*models.BankAccountInquiryResponse, error) {
+	return s.BankAccountInquiry(ctx, models.BankAccountInquiryRequest{
+		AccountNumber: accountNumber,
+		BankCode:      bankCode,
+	})
+}
+
+// mapFlipPaymentStatus maps a Flip bill payment status to our payment status
+func mapFlipPaymentStatus(status models.FlipPaymentStatus) models.PaymentStatus {
+	switch status {
+	case models.FlipStatusSuccessful:
+		return models.PaymentStatusCompleted
+	case models.FlipStatusFailed:
+		return models.PaymentStatusFailed
+	case models.FlipStatusCancelled:
+		return models.PaymentStatusCancelled
+	case models.FlipStatusExpired:
+		return models.PaymentStatusExpired
+	default:
+		return models.PaymentStatusPending
+	}
+}
+
+// mapFlipDisbursementStatus maps a Flip disbursement status to our payment status
+func mapFlipDisbursementStatus(status string) models.PaymentStatus {
+	switch models.DisbursementStatus(status) {
+	case models.DisbursementStatusDone:
+		return models.PaymentStatusCompleted
+	case models.DisbursementStatusCancelled:
+		return models.PaymentStatusCancelled
+	case models.DisbursementStatusPending:
+		return models.PaymentStatusPending
+	default:
+		return models.PaymentStatusProcessing
+	}
+}
+
+// disbursementToPayoutResponse converts a Flip disbursement to a payout response
+func disbursementToPayoutResponse(disbursement *models.DisbursementResponse) *models.ProviderPayoutResponse {
+	payout := &models.ProviderPayoutResponse{
+		ProviderPayoutID: strconv.Itoa(disbursement.ID),
+		IdempotencyKey:   disbursement.IdempotencyKey,
+		Status:           mapFlipDisbursementStatus(disbursement.Status),
+		ProviderStatus:   disbursement.Status,
+		Fee:              disbursement.Fee,
+	}
+
+	if disbursement.Receipt != "" {
+		payout.ReceiptURL = &disbursement.Receipt
+	}
+	if disbursement.Reason != "" {
+		payout.Reason = &disbursement.Reason
+	}
+	if raw, err := json.Marshal(disbursement); err == nil {
+		payout.RawResponse = raw
+	}
+
+	return payout
+}
+
 // ========== HELPER METHODS ==========
 
 // createBillRequestToFormData converts CreateBillRequest to form data
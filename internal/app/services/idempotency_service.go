@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// IdempotencyKeyTTL is how long a stored response can be replayed
+	IdempotencyKeyTTL = 24 * time.Hour
+	// IdempotencyKeyLease is how long a PROCESSING key stays claimed. It outlasts the server's
+	// request timeouts, so a key still processing after it belongs to a request that died and
+	// can be taken over by a retry.
+	IdempotencyKeyLease = 2 * time.Minute
+)
+
+// IdempotencyService stores Idempotency-Key requests and their responses
+type IdempotencyService struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyService(db *gorm.DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+// Begin claims a key for a request. It returns the new record and true when the caller
+// should process the request, or the existing record and false when the key was used before.
+// A reused key whose request hash differs is rejected with 422. A key left PROCESSING past its
+// lease is claimed again by the retry.
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key, method, path, requestHash string) (*models.IdempotencyKey, bool, error) {
+	now := time.Now()
+	lockedUntil := now.Add(IdempotencyKeyLease)
+
+	// Expired keys can be reused
+	if err := s.db.WithContext(ctx).
+		Where("scope = ? AND key = ? AND expires_at < ?", scope, key, now).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return nil, false, errors.NewInternalServerError(err, "Failed to clean up idempotency key")
+	}
+
+	record := &models.IdempotencyKey{
+		Scope:         scope,
+		Key:           key,
+		RequestMethod: method,
+		RequestPath:   path,
+		RequestHash:   requestHash,
+		Status:        models.IdempotencyKeyStatusProcessing,
+		LockedUntil:   &lockedUntil,
+		ExpiresAt:     now.Add(IdempotencyKeyTTL),
+	}
+
+	result := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(record)
+	if result.Error != nil {
+		return nil, false, errors.NewInternalServerError(result.Error, "Failed to store idempotency key")
+	}
+
+	if result.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := s.db.WithContext(ctx).Where("scope = ? AND key = ?", scope, key).First(&existing).Error; err != nil {
+		return nil, false, errors.NewInternalServerError(err, "Failed to get idempotency key")
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, false, errors.NewAppError(http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+	}
+
+	if existing.Status == models.IdempotencyKeyStatusProcessing {
+		// Take over the key of a request that died before completing or releasing it
+		takeover := s.db.WithContext(ctx).
+			Model(&models.IdempotencyKey{}).
+			Where("id = ? AND status = ? AND (locked_until IS NULL OR locked_until < ?)", existing.ID, models.IdempotencyKeyStatusProcessing, now).
+			Update("locked_until", lockedUntil)
+		if takeover.Error != nil {
+			return nil, false, errors.NewInternalServerError(takeover.Error, "Failed to claim idempotency key")
+		}
+		if takeover.RowsAffected == 1 {
+			existing.LockedUntil = &lockedUntil
+			return &existing, true, nil
+		}
+		return nil, false, errors.NewAppError(http.StatusConflict, "A request with this Idempotency-Key is still being processed")
+	}
+
+	return &existing, false, nil
+}
+
+// Complete stores the response of a claimed key
+func (s *IdempotencyService) Complete(ctx context.Context, record *models.IdempotencyKey, status int, contentType string, body []byte) error {
+	now := time.Now()
+	record.Status = models.IdempotencyKeyStatusCompleted
+	record.ResponseStatus = &status
+	record.ResponseContentType = &contentType
+	record.ResponseBody = body
+	record.CompletedAt = &now
+	record.LockedUntil = nil
+
+	if err := s.db.WithContext(ctx).Model(record).Updates(map[string]interface{}{
+		"status":                record.Status,
+		"response_status":       status,
+		"response_content_type": contentType,
+		"response_body":         body,
+		"completed_at":          now,
+		"locked_until":          nil,
+	}).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to store idempotent response")
+	}
+
+	return nil
+}
+
+// Release drops a claimed key so the request can be retried, e.g. after a server error
+func (s *IdempotencyService) Release(ctx context.Context, record *models.IdempotencyKey) error {
+	if err := s.db.WithContext(ctx).Delete(record).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to release idempotency key")
+	}
+
+	return nil
+}
+
+// PurgeExpired deletes keys past their retention
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) error {
+	if err := s.db.WithContext(ctx).
+		Where("expires_at < ?", time.Now()).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to purge idempotency keys")
+	}
+
+	return nil
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InboundWebhookService verifies, deduplicates and applies payment provider callbacks
+type InboundWebhookService struct {
+	db                 *gorm.DB
+	providerRegistry   *PaymentProviderRegistry
+	paymentService     *PaymentService
+	transactionService *TransactionService
+}
+
+func NewInboundWebhookService(db *gorm.DB, providerRegistry *PaymentProviderRegistry, paymentService *PaymentService, transactionService *TransactionService) *InboundWebhookService {
+	return &InboundWebhookService{
+		db:                 db,
+		providerRegistry:   providerRegistry,
+		paymentService:     paymentService,
+		transactionService: transactionService,
+	}
+}
+
+// HandlePaymentWebhook verifies an accept-payment callback and applies it to the matching
+// transaction. Callbacks already processed or rejected are returned as-is without side effects.
+func (s *InboundWebhookService) HandlePaymentWebhook(ctx context.Context, providerCode string, req models.ProviderWebhookRequest) (*models.InboundWebhookEvent, error) {
+	provider, err := s.providerRegistry.Get(providerCode)
+	if err != nil {
+		return nil, err
+	}
+
+	providerEvent, err := provider.ParseWebhook(req)
+	if err != nil {
+		return nil, err
+	}
+
+	event, isNew, err := s.recordEvent(ctx, &models.InboundWebhookEvent{
+		Provider:          provider.Code(),
+		EventType:         models.InboundWebhookEventTypePayment,
+		ExternalID:        providerEvent.EventID,
+		ProviderPaymentID: &providerEvent.ProviderPaymentID,
+		PaymentStatus:     providerEvent.Status,
+		Amount:            providerEvent.Amount,
+		Payload:           providerEvent.RawPayload,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !isNew && isEventFinal(event) {
+		logrus.WithFields(logrus.Fields{
+			"provider":    event.Provider,
+			"external_id": event.ExternalID,
+		}).Info("Ignoring duplicate payment webhook")
+		return event, nil
+	}
+
+	// A redelivery retries with what the provider reports now, not the stored attempt
+	event.PaymentStatus = providerEvent.Status
+	event.Amount = providerEvent.Amount
+
+	if err := s.applyPaymentEvent(ctx, event); err != nil {
+		s.markEvent(s.db.WithContext(ctx), event, models.InboundWebhookEventStatusFailed, pkg.StringPtr(err.Error()))
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// HandleDisbursementWebhook verifies a payout callback and applies it to the matching withdrawal.
+// Each payout status change is processed at most once.
+func (s *InboundWebhookService) HandleDisbursementWebhook(ctx context.Context, providerCode string, req models.ProviderWebhookRequest) (*models.InboundWebhookEvent, error) {
+	provider, err := s.providerRegistry.Get(providerCode)
+	if err != nil {
+		return nil, err
+	}
+
+	payoutEvent, err := provider.ParsePayoutWebhook(req)
+	if err != nil {
+		return nil, err
+	}
+
+	event, isNew, err := s.recordEvent(ctx, &models.InboundWebhookEvent{
+		Provider:          provider.Code(),
+		EventType:         models.InboundWebhookEventTypeDisbursement,
+		ExternalID:        payoutEvent.EventID,
+		ProviderPaymentID: &payoutEvent.Payout.ProviderPayoutID,
+		PaymentStatus:     payoutEvent.Payout.Status,
+		Amount:            payoutEvent.Amount,
+		Payload:           payoutEvent.Payout.RawResponse,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !isNew && isEventFinal(event) {
+		logrus.WithFields(logrus.Fields{
+			"provider":    event.Provider,
+			"external_id": event.ExternalID,
+		}).Info("Ignoring duplicate disbursement webhook")
+		return event, nil
+	}
+
+	if err := s.applyPayoutEvent(ctx, event, &payoutEvent.Payout); err != nil {
+		s.markEvent(s.db.WithContext(ctx), event, models.InboundWebhookEventStatusFailed, pkg.StringPtr(err.Error()))
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// recordEvent inserts the event, or loads the existing row when the provider has already sent it
+func (s *InboundWebhookService) recordEvent(ctx context.Context, event *models.InboundWebhookEvent) (*models.InboundWebhookEvent, bool, error) {
+	event.Status = models.InboundWebhookEventStatusReceived
+	event.ReceivedAt = time.Now()
+
+	result := s.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event)
+	if result.Error != nil {
+		return nil, false, errors.NewInternalServerError(result.Error, "Failed to record webhook event")
+	}
+
+	if result.RowsAffected == 1 {
+		return event, true, nil
+	}
+
+	var existing models.InboundWebhookEvent
+	if err := s.db.WithContext(ctx).
+		Where("provider = ? AND event_type = ? AND external_id = ?", event.Provider, event.EventType, event.ExternalID).
+		First(&existing).Error; err != nil {
+		return nil, false, errors.NewInternalServerError(err, "Failed to get webhook event")
+	}
+
+	return &existing, false, nil
+}
+
+// applyPaymentEvent resolves the transaction through its payment details, validates the amount
+// and settles final statuses. Balance changes and the event outcome are committed atomically.
+func (s *InboundWebhookService) applyPaymentEvent(ctx context.Context, event *models.InboundWebhookEvent) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if done, err := s.lockEvent(tx, event); err != nil || done {
+			return err
+		}
+
+		var paymentDetails models.PaymentDetails
+		if err := tx.
+			Where("provider = ? AND provider_payment_id = ?", event.Provider, *event.ProviderPaymentID).
+			Order("created_at DESC").
+			First(&paymentDetails).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return s.markEvent(tx, event, models.InboundWebhookEventStatusRejected, pkg.StringPtr("No payment matches provider payment ID"))
+			}
+			return errors.NewInternalServerError(err, "Failed to get payment details")
+		}
+
+		var transaction models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", paymentDetails.TransactionID).First(&transaction).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return s.markEvent(tx, event, models.InboundWebhookEventStatusRejected, pkg.StringPtr("Transaction not found"))
+			}
+			return errors.NewInternalServerError(err, "Failed to get transaction")
+		}
+		event.TransactionID = &transaction.ID
+
+		// Never act on a successful payment whose amount differs from what we billed
+		if event.PaymentStatus == models.PaymentStatusCompleted {
+			if transaction.PaymentAmount == nil || *transaction.PaymentAmount != event.Amount {
+				expected := int64(0)
+				if transaction.PaymentAmount != nil {
+					expected = *transaction.PaymentAmount
+				}
+				logrus.WithFields(logrus.Fields{
+					"transaction_id": transaction.ID,
+					"expected":       expected,
+					"received":       event.Amount,
+				}).Warn("Payment webhook amount mismatch")
+				return s.markEvent(tx, event, models.InboundWebhookEventStatusRejected, pkg.StringPtr(fmt.Sprintf("Amount mismatch: expected %d, received %d", expected, event.Amount)))
+			}
+		}
+
+		description := pkg.StringPtr(fmt.Sprintf("%s callback: %s", event.Provider, event.PaymentStatus))
+
+		switch event.PaymentStatus {
+		case models.PaymentStatusCompleted, models.PaymentStatusFailed, models.PaymentStatusExpired, models.PaymentStatusCancelled:
+			// A payment confirmed after the topup expired is still credited
+			if event.PaymentStatus == models.PaymentStatusCompleted && isLatePayable(&transaction) {
+				if _, err := s.transactionService.SettleLatePayment(tx, transaction.ID, event.ProviderPaymentID, description); err != nil {
+					return err
+				}
+				break
+			}
+
+			// A transaction is settled once; later final callbacks are recorded but ignored
+			if transaction.Status != models.TransactionStatusPending {
+				return s.markEvent(tx, event, models.InboundWebhookEventStatusRejected, pkg.StringPtr(fmt.Sprintf("Transaction already %s", transaction.Status)))
+			}
+
+			if _, err := s.transactionService.SettlePayment(tx, transaction.ID, event.PaymentStatus, event.ProviderPaymentID, description); err != nil {
+				return err
+			}
+
+		default:
+			now := time.Now()
+			statusUpdateReq := &models.PaymentStatusUpdateRequest{
+				Status:            event.PaymentStatus,
+				StatusDescription: description,
+				ProviderPaymentID: event.ProviderPaymentID,
+				PaymentTime:       &now,
+			}
+			if err := s.paymentService.UpdatePaymentStatusTx(tx, transaction.ID, statusUpdateReq); err != nil {
+				return err
+			}
+
+			if err := tx.Model(&transaction).Updates(map[string]interface{}{
+				"payment_status":             event.PaymentStatus,
+				"payment_status_description": description,
+			}).Error; err != nil {
+				return errors.NewInternalServerError(err, "Failed to update transaction")
+			}
+		}
+
+		return s.markEvent(tx, event, models.InboundWebhookEventStatusProcessed, nil)
+	})
+}
+
+// applyPayoutEvent resolves the withdrawal through its payment details, validates the amount
+// and applies the payout status. Refunds and the event outcome are committed atomically.
+func (s *InboundWebhookService) applyPayoutEvent(ctx context.Context, event *models.InboundWebhookEvent, payout *models.ProviderPayoutResponse) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if done, err := s.lockEvent(tx, event); err != nil || done {
+			return err
+		}
+
+		paymentDetails, err := s.findPayoutDetails(tx, event, payout)
+		if err != nil {
+			return err
+		}
+		if paymentDetails == nil {
+			return s.markEvent(tx, event, models.InboundWebhookEventStatusRejected, pkg.StringPtr("No withdrawal matches provider payout ID"))
+		}
+
+		var transaction models.Transaction
+		if err := tx.Where("id = ?", paymentDetails.TransactionID).First(&transaction).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return s.markEvent(tx, event, models.InboundWebhookEventStatusRejected, pkg.StringPtr("Transaction not found"))
+			}
+			return errors.NewInternalServerError(err, "Failed to get transaction")
+		}
+		event.TransactionID = &transaction.ID
+
+		if transaction.Type != models.TransactionTypeWithdrawal {
+			return s.markEvent(tx, event, models.InboundWebhookEventStatusRejected, pkg.StringPtr("Transaction is not a withdrawal"))
+		}
+
+		expected := s.transactionService.ConvertGSALTToIDR(transaction.AmountGsaltUnits)
+		if event.Amount != expected {
+			logrus.WithFields(logrus.Fields{
+				"transaction_id": transaction.ID,
+				"expected":       expected,
+				"received":       event.Amount,
+			}).Warn("Disbursement webhook amount mismatch")
+			return s.markEvent(tx, event, models.InboundWebhookEventStatusRejected, pkg.StringPtr(fmt.Sprintf("Amount mismatch: expected %d, received %d", expected, event.Amount)))
+		}
+
+		if transaction.Status != models.TransactionStatusPending && transaction.Status != models.TransactionStatusProcessing {
+			return s.markEvent(tx, event, models.InboundWebhookEventStatusRejected, pkg.StringPtr(fmt.Sprintf("Transaction already %s", transaction.Status)))
+		}
+
+		if _, err := s.transactionService.SettlePayout(tx, transaction.ID, payout); err != nil {
+			return err
+		}
+
+		return s.markEvent(tx, event, models.InboundWebhookEventStatusProcessed, nil)
+	})
+}
+
+// findPayoutDetails returns the payment details of the withdrawal a payout belongs to, or nil.
+// A callback can arrive before ProcessWithdrawal has stored the payout ID; the withdrawal is then
+// found through the payout's idempotency key, which is the transaction ID, and the ID recorded.
+func (s *InboundWebhookService) findPayoutDetails(tx *gorm.DB, event *models.InboundWebhookEvent, payout *models.ProviderPayoutResponse) (*models.PaymentDetails, error) {
+	var paymentDetails models.PaymentDetails
+	err := tx.
+		Where("provider = ? AND provider_payment_id = ?", event.Provider, *event.ProviderPaymentID).
+		Order("created_at DESC").
+		First(&paymentDetails).Error
+	if err == nil {
+		return &paymentDetails, nil
+	}
+	if err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalServerError(err, "Failed to get payment details")
+	}
+
+	transactionID, err := uuid.Parse(payout.IdempotencyKey)
+	if err != nil {
+		return nil, nil
+	}
+	if err := tx.
+		Where("transaction_id = ? AND provider = ? AND provider_payment_id IS NULL", transactionID, event.Provider).
+		First(&paymentDetails).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get payment details")
+	}
+
+	if err := s.transactionService.attachPayout(tx, transactionID, payout); err != nil {
+		return nil, err
+	}
+	paymentDetails.ProviderPaymentID = &payout.ProviderPayoutID
+
+	return &paymentDetails, nil
+}
+
+// lockEvent locks the event row to serialize concurrent deliveries and reports whether
+// another delivery has already finished it
+func (s *InboundWebhookService) lockEvent(tx *gorm.DB, event *models.InboundWebhookEvent) (bool, error) {
+	var locked models.InboundWebhookEvent
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", event.ID).First(&locked).Error; err != nil {
+		return false, errors.NewInternalServerError(err, "Failed to lock webhook event")
+	}
+
+	if isEventFinal(&locked) {
+		*event = locked
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// isEventFinal reports whether an event needs no further processing
+func isEventFinal(event *models.InboundWebhookEvent) bool {
+	return event.Status == models.InboundWebhookEventStatusProcessed || event.Status == models.InboundWebhookEventStatusRejected
+}
+
+// markEvent stores the processing outcome of an event
+func (s *InboundWebhookService) markEvent(db *gorm.DB, event *models.InboundWebhookEvent, status models.InboundWebhookEventStatus, errorMessage *string) error {
+	now := time.Now()
+	event.Status = status
+	event.ErrorMessage = errorMessage
+	event.ProcessedAt = &now
+
+	if err := db.Model(event).Updates(map[string]interface{}{
+		"status":         event.Status,
+		"error_message":  event.ErrorMessage,
+		"transaction_id": event.TransactionID,
+		"processed_at":   event.ProcessedAt,
+		"updated_at":     now,
+	}).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to update webhook event")
+	}
+
+	return nil
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Error codes for KYC requests
+const (
+	ErrCodeKYCSubmissionPending  = "KYC_SUBMISSION_PENDING"
+	ErrCodeKYCAlreadyVerified    = "KYC_ALREADY_VERIFIED"
+	ErrCodeKYCSubmissionReviewed = "KYC_SUBMISSION_REVIEWED"
+)
+
+// KYCService handles identity verification. An account submits identity data for a KYC level,
+// an admin approves or rejects it, and approval sets the account's KYC status and level, which
+// select its transaction limits.
+type KYCService struct {
+	db           *gorm.DB
+	validator    *infrastructures.Validator
+	auditService *AuditService
+}
+
+func NewKYCService(db *gorm.DB, validator *infrastructures.Validator, auditService *AuditService) *KYCService {
+	return &KYCService{
+		db:           db,
+		validator:    validator,
+		auditService: auditService,
+	}
+}
+
+// Submit queues identity data for review. An account can have one pending submission, and a
+// verified account can only apply for a higher level.
+func (s *KYCService) Submit(accountID uuid.UUID, req *models.KYCSubmitRequest) (*models.KYCSubmission, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	dateOfBirth, err := time.Parse("2006-01-02", req.DateOfBirth)
+	if err != nil || !dateOfBirth.Before(time.Now()) {
+		return nil, errors.NewBadRequestError("Invalid date_of_birth")
+	}
+
+	submission := &models.KYCSubmission{
+		AccountID:               accountID,
+		Level:                   req.Level,
+		Status:                  models.KYCSubmissionStatusPending,
+		FullName:                strings.TrimSpace(req.FullName),
+		DateOfBirth:             dateOfBirth,
+		Nationality:             req.Nationality,
+		IDType:                  req.IDType,
+		IDNumber:                req.IDNumber,
+		Address:                 req.Address,
+		IDDocumentReference:     req.IDDocumentReference,
+		SelfieReference:         req.SelfieReference,
+		ProofOfAddressReference: req.ProofOfAddressReference,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		account, err := s.lockAccount(tx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if account.KYCStatus == models.KYCStatusVerified && req.Level.Rank() <= account.KYCLevel.Rank() {
+			return errors.NewBadRequestError(fmt.Sprintf("Account is already verified at level %s [%s]", account.KYCLevel, ErrCodeKYCAlreadyVerified))
+		}
+
+		var pending int64
+		if err := tx.Model(&models.KYCSubmission{}).
+			Where("account_id = ? AND status = ?", accountID, models.KYCSubmissionStatusPending).
+			Count(&pending).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to check KYC submissions")
+		}
+		if pending > 0 {
+			return errors.NewAppError(http.StatusConflict, "A KYC submission is already waiting for review ["+ErrCodeKYCSubmissionPending+"]")
+		}
+
+		if err := tx.Create(submission).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to create KYC submission")
+		}
+
+		// A verified account applying for a higher level keeps its status while under review
+		if account.KYCStatus == models.KYCStatusVerified {
+			return nil
+		}
+		return s.changeStatus(tx, account, models.KYCStatusPending, account.KYCLevel, &submission.ID, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return submission, nil
+}
+
+// GetOverview returns an account's KYC status and level, its latest submission and its status
+// history, newest first
+func (s *KYCService) GetOverview(account *models.Account) (*models.KYCOverview, error) {
+	overview := &models.KYCOverview{
+		KYCStatus: account.KYCStatus,
+		KYCLevel:  account.KYCLevel,
+		History:   []models.KYCStatusHistory{},
+	}
+
+	var submission models.KYCSubmission
+	err := s.db.Where("account_id = ?", account.ConnectID).Order("created_at DESC").First(&submission).Error
+	switch {
+	case err == nil:
+		overview.LatestSubmission = &submission
+	case !stderrors.Is(err, gorm.ErrRecordNotFound):
+		return nil, errors.NewInternalServerError(err, "Failed to get KYC submission")
+	}
+
+	if err := s.db.Where("account_id = ?", account.ConnectID).Order("created_at DESC").Find(&overview.History).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get KYC status history")
+	}
+
+	return overview, nil
+}
+
+// GetSubmissions lists submissions with the given status. Pending submissions come oldest first,
+// as a review queue; other statuses newest first.
+func (s *KYCService) GetSubmissions(status models.KYCSubmissionStatus, pagination *models.PaginationRequest) (*models.Pagination[[]models.KYCSubmission], error) {
+	if pagination.Limit <= 0 || pagination.Limit > 100 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	var totalItems int64
+	if err := s.db.Model(&models.KYCSubmission{}).Where("status = ?", status).Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count KYC submissions")
+	}
+
+	order := "created_at DESC"
+	if status == models.KYCSubmissionStatusPending {
+		order = "created_at ASC"
+	}
+
+	var submissions []models.KYCSubmission
+	if err := s.db.Where("status = ?", status).
+		Order(order).
+		Offset(offset).
+		Limit(pagination.Limit).
+		Find(&submissions).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get KYC submissions")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return &models.Pagination[[]models.KYCSubmission]{
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: totalPages,
+		TotalItems: int(totalItems),
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+		Items:      submissions,
+	}, nil
+}
+
+// GetSubmission returns a submission by ID
+func (s *KYCService) GetSubmission(id uuid.UUID) (*models.KYCSubmission, error) {
+	return s.getSubmission(s.db, id)
+}
+
+// ApproveSubmission verifies the account at the submission's level
+func (s *KYCService) ApproveSubmission(id uuid.UUID, reviewerID uuid.UUID, req *models.KYCReviewRequest) (*models.KYCSubmission, error) {
+	return s.review(id, reviewerID, req, models.KYCSubmissionStatusApproved)
+}
+
+// RejectSubmission rejects a submission. An account that was not verified yet becomes REJECTED
+// and can submit again; a verified account keeps its current level.
+func (s *KYCService) RejectSubmission(id uuid.UUID, reviewerID uuid.UUID, req *models.KYCReviewRequest) (*models.KYCSubmission, error) {
+	if req.Reason == nil || strings.TrimSpace(*req.Reason) == "" {
+		return nil, errors.NewBadRequestError("A reason is required to reject a KYC submission")
+	}
+	return s.review(id, reviewerID, req, models.KYCSubmissionStatusRejected)
+}
+
+// review closes a pending submission and updates the account's KYC status and level
+func (s *KYCService) review(id uuid.UUID, reviewerID uuid.UUID, req *models.KYCReviewRequest, status models.KYCSubmissionStatus) (*models.KYCSubmission, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	var submission *models.KYCSubmission
+	var oldSubmission models.KYCSubmission
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		submission, err = s.getSubmission(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+		if err != nil {
+			return err
+		}
+		if submission.Status != models.KYCSubmissionStatusPending {
+			return errors.NewAppError(http.StatusConflict, fmt.Sprintf("KYC submission is already %s [%s]", submission.Status, ErrCodeKYCSubmissionReviewed))
+		}
+		oldSubmission = *submission
+
+		now := time.Now()
+		submission.Status = status
+		submission.ReviewedBy = &reviewerID
+		submission.ReviewReason = req.Reason
+		submission.ReviewedAt = &now
+		if err := tx.Model(submission).Updates(map[string]interface{}{
+			"status":        submission.Status,
+			"reviewed_by":   submission.ReviewedBy,
+			"review_reason": submission.ReviewReason,
+			"reviewed_at":   submission.ReviewedAt,
+		}).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update KYC submission")
+		}
+
+		if err := s.auditService.LogAuditTx(tx, "kyc_submissions", submission.ID, models.AuditActionStatusChange, oldSubmission, submission, &reviewerID); err != nil {
+			return err
+		}
+
+		account, err := s.lockAccount(tx, submission.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if status == models.KYCSubmissionStatusApproved {
+			return s.changeStatus(tx, account, models.KYCStatusVerified, submission.Level, &submission.ID, req.Reason, &reviewerID)
+		}
+		if account.KYCStatus == models.KYCStatusVerified {
+			return nil
+		}
+		return s.changeStatus(tx, account, models.KYCStatusRejected, account.KYCLevel, &submission.ID, req.Reason, &reviewerID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return submission, nil
+}
+
+// changeStatus sets the KYC status and level of a locked account and records the change
+func (s *KYCService) changeStatus(tx *gorm.DB, account *models.Account, status models.KYCStatus, level models.KYCLevel, submissionID *uuid.UUID, reason *string, changedBy *uuid.UUID) error {
+	if err := tx.Model(account).Updates(map[string]interface{}{
+		"kyc_status": status,
+		"kyc_level":  level,
+	}).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to update account KYC status")
+	}
+
+	history := &models.KYCStatusHistory{
+		AccountID:    account.ConnectID,
+		SubmissionID: submissionID,
+		FromStatus:   account.KYCStatus,
+		ToStatus:     status,
+		FromLevel:    account.KYCLevel,
+		ToLevel:      level,
+		Reason:       reason,
+		ChangedBy:    changedBy,
+	}
+	if err := tx.Create(history).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to create KYC status history")
+	}
+
+	account.KYCStatus = status
+	account.KYCLevel = level
+	return nil
+}
+
+func (s *KYCService) lockAccount(tx *gorm.DB, accountID uuid.UUID) (*models.Account, error) {
+	var account models.Account
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("connect_id = ?", accountID).First(&account).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Account not found [" + ErrCodeAccountNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get account")
+	}
+	return &account, nil
+}
+
+func (s *KYCService) getSubmission(db *gorm.DB, id uuid.UUID) (*models.KYCSubmission, error) {
+	var submission models.KYCSubmission
+	if err := db.Where("id = ?", id).First(&submission).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("KYC submission not found")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get KYC submission")
+	}
+	return &submission, nil
+}
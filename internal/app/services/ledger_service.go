@@ -0,0 +1,721 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LedgerService records every balance movement as a balanced double-entry journal entry.
+// Account.Balance is a projection of the account's wallet ledger account and Account.HeldBalance
+// the sum of its active balance holds; both must only be changed through this service.
+type LedgerService struct {
+	db *gorm.DB
+}
+
+// NewLedgerService creates a new LedgerService
+func NewLedgerService(db *gorm.DB) *LedgerService {
+	return &LedgerService{
+		db: db,
+	}
+}
+
+// Debit builds a debit journal line
+func Debit(ledgerAccountID uuid.UUID, amount int64) models.JournalLine {
+	return models.JournalLine{LedgerAccountID: ledgerAccountID, Direction: models.PostingDirectionDebit, Amount: amount}
+}
+
+// Credit builds a credit journal line
+func Credit(ledgerAccountID uuid.UUID, amount int64) models.JournalLine {
+	return models.JournalLine{LedgerAccountID: ledgerAccountID, Direction: models.PostingDirectionCredit, Amount: amount}
+}
+
+// PostJournalEntry validates that the lines balance and writes the entry, its postings and
+// the resulting ledger balances using tx. Wallet balances are mirrored to accounts.balance.
+func (s *LedgerService) PostJournalEntry(tx *gorm.DB, reference string, description *string, lines []models.JournalLine) (*models.JournalEntry, error) {
+	var debits, credits int64
+	nonZero := make([]models.JournalLine, 0, len(lines))
+	for _, line := range lines {
+		if line.Amount < 0 {
+			return nil, errors.NewInternalServerError(fmt.Errorf("negative posting amount %d", line.Amount), "Invalid journal entry")
+		}
+		if line.Amount == 0 {
+			continue
+		}
+		switch line.Direction {
+		case models.PostingDirectionDebit:
+			debits += line.Amount
+		case models.PostingDirectionCredit:
+			credits += line.Amount
+		default:
+			return nil, errors.NewInternalServerError(fmt.Errorf("unknown posting direction %q", line.Direction), "Invalid journal entry")
+		}
+		nonZero = append(nonZero, line)
+	}
+
+	if len(nonZero) < 2 || debits != credits {
+		return nil, errors.NewInternalServerError(fmt.Errorf("unbalanced journal entry %s: debits=%d credits=%d", reference, debits, credits), "Invalid journal entry")
+	}
+
+	entry := &models.JournalEntry{
+		Reference:   reference,
+		Description: description,
+		PostedAt:    time.Now(),
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to create journal entry")
+	}
+
+	for _, line := range nonZero {
+		posting, err := s.applyPosting(tx, entry.ID, line)
+		if err != nil {
+			return nil, err
+		}
+		entry.Postings = append(entry.Postings, *posting)
+	}
+
+	return entry, nil
+}
+
+// applyPosting moves the ledger account balance and records the posting
+func (s *LedgerService) applyPosting(tx *gorm.DB, entryID uuid.UUID, line models.JournalLine) (*models.Posting, error) {
+	var ledgerAccount models.LedgerAccount
+	if err := tx.Where("id = ?", line.LedgerAccountID).First(&ledgerAccount).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Ledger account not found")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get ledger account")
+	}
+
+	delta := line.Amount
+	if ledgerAccount.IsDebitNormal() != (line.Direction == models.PostingDirectionDebit) {
+		delta = -delta
+	}
+
+	// Atomic increment so concurrent postings to shared system accounts never lose updates
+	if err := tx.Model(&models.LedgerAccount{}).
+		Where("id = ?", ledgerAccount.ID).
+		Updates(map[string]interface{}{
+			"balance":    gorm.Expr("balance + ?", delta),
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to update ledger balance")
+	}
+
+	var balanceAfter int64
+	if err := tx.Model(&models.LedgerAccount{}).Where("id = ?", ledgerAccount.ID).Select("balance").Scan(&balanceAfter).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to read ledger balance")
+	}
+
+	if balanceAfter < 0 && !ledgerAccount.AllowNegative {
+		return nil, errors.NewBadRequestError("Insufficient balance [" + ErrCodeInsufficientBalance + "]")
+	}
+
+	posting := &models.Posting{
+		JournalEntryID:  entryID,
+		LedgerAccountID: ledgerAccount.ID,
+		Direction:       line.Direction,
+		Amount:          line.Amount,
+		BalanceAfter:    balanceAfter,
+	}
+	if err := tx.Create(posting).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to create posting")
+	}
+
+	// Keep the account balance projection in sync with its wallet.
+	// A debit may not dip into funds reserved by active balance holds.
+	if ledgerAccount.OwnerAccountID != nil {
+		query := tx.Unscoped().Model(&models.Account{}).Where("connect_id = ?", *ledgerAccount.OwnerAccountID)
+		if delta < 0 {
+			query = query.Where("held_balance <= ?", balanceAfter)
+		}
+		result := query.Update("balance", balanceAfter)
+		if result.Error != nil {
+			return nil, errors.NewInternalServerError(result.Error, "Failed to update account balance")
+		}
+		if delta < 0 && result.RowsAffected == 0 {
+			return nil, errors.NewBadRequestError("Insufficient available balance [" + ErrCodeInsufficientBalance + "]")
+		}
+	}
+
+	return posting, nil
+}
+
+// GetWalletAccount returns the wallet ledger account of a GSALT account, creating it on first use
+func (s *LedgerService) GetWalletAccount(tx *gorm.DB, accountID uuid.UUID) (*models.LedgerAccount, error) {
+	ledgerAccount := models.LedgerAccount{
+		Code:           models.LedgerAccountCodeWalletPrefix + accountID.String(),
+		Name:           "Wallet " + accountID.String(),
+		Type:           models.LedgerAccountTypeLiability,
+		OwnerAccountID: &accountID,
+		Currency:       "GSALT",
+	}
+	if err := tx.Where("code = ?", ledgerAccount.Code).FirstOrCreate(&ledgerAccount).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get wallet ledger account")
+	}
+	return &ledgerAccount, nil
+}
+
+// GetProviderClearingAccount returns the clearing account that tracks money held at a payment provider
+func (s *LedgerService) GetProviderClearingAccount(tx *gorm.DB, providerCode string) (*models.LedgerAccount, error) {
+	ledgerAccount := models.LedgerAccount{
+		Code:          models.LedgerAccountCodeProviderClearingPrefix + providerCode,
+		Name:          providerCode + " Clearing",
+		Type:          models.LedgerAccountTypeAsset,
+		Currency:      "GSALT",
+		AllowNegative: true,
+	}
+	if err := tx.Where("code = ?", ledgerAccount.Code).FirstOrCreate(&ledgerAccount).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get provider clearing ledger account")
+	}
+	return &ledgerAccount, nil
+}
+
+// GetSandboxWalletAccount returns the test mode wallet of a GSALT account, creating it on first
+// use. It has no owner, so postings never touch the account's real balance, and it may go
+// negative so test payers never run out of funds.
+func (s *LedgerService) GetSandboxWalletAccount(tx *gorm.DB, accountID uuid.UUID) (*models.LedgerAccount, error) {
+	ledgerAccount := models.LedgerAccount{
+		Code:          models.LedgerAccountCodeSandboxWalletPrefix + accountID.String(),
+		Name:          "Sandbox Wallet " + accountID.String(),
+		Type:          models.LedgerAccountTypeLiability,
+		Currency:      "GSALT",
+		AllowNegative: true,
+	}
+	if err := tx.Where("code = ?", ledgerAccount.Code).FirstOrCreate(&ledgerAccount).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get sandbox wallet ledger account")
+	}
+	return &ledgerAccount, nil
+}
+
+// GetSystemAccount returns one of the seeded system ledger accounts by code
+func (s *LedgerService) GetSystemAccount(tx *gorm.DB, code string) (*models.LedgerAccount, error) {
+	var ledgerAccount models.LedgerAccount
+	if err := tx.Where("code = ?", code).First(&ledgerAccount).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewInternalServerError(err, fmt.Sprintf("System ledger account %s is missing", code))
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get system ledger account")
+	}
+	return &ledgerAccount, nil
+}
+
+// RecordWalletTransfer moves balance between two GSALT wallets
+func (s *LedgerService) RecordWalletTransfer(tx *gorm.DB, sourceAccountID, destAccountID uuid.UUID, amountGsaltUnits int64, reference string, description *string) (*models.JournalEntry, error) {
+	source, err := s.GetWalletAccount(tx, sourceAccountID)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := s.GetWalletAccount(tx, destAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PostJournalEntry(tx, reference, description, []models.JournalLine{
+		Debit(source.ID, amountGsaltUnits),
+		Credit(dest.ID, amountGsaltUnits),
+	})
+}
+
+// RecordTopup credits a wallet with funds collected by a payment provider.
+// The provider clearing account is debited with the amount plus the fee we earned.
+func (s *LedgerService) RecordTopup(tx *gorm.DB, accountID uuid.UUID, providerCode string, amountGsaltUnits, feeGsaltUnits int64, reference string, description *string) (*models.JournalEntry, error) {
+	wallet, err := s.GetWalletAccount(tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	clearing, err := s.GetProviderClearingAccount(tx, providerCode)
+	if err != nil {
+		return nil, err
+	}
+	feeRevenue, err := s.GetSystemAccount(tx, models.LedgerAccountCodeFeeRevenue)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PostJournalEntry(tx, reference, description, []models.JournalLine{
+		Debit(clearing.ID, amountGsaltUnits+feeGsaltUnits),
+		Credit(wallet.ID, amountGsaltUnits),
+		Credit(feeRevenue.ID, feeGsaltUnits),
+	})
+}
+
+// RecordWithdrawal debits a wallet for a payout sent through a payment provider
+func (s *LedgerService) RecordWithdrawal(tx *gorm.DB, accountID uuid.UUID, providerCode string, amountGsaltUnits, feeGsaltUnits int64, reference string, description *string) (*models.JournalEntry, error) {
+	wallet, err := s.GetWalletAccount(tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	clearing, err := s.GetProviderClearingAccount(tx, providerCode)
+	if err != nil {
+		return nil, err
+	}
+	feeRevenue, err := s.GetSystemAccount(tx, models.LedgerAccountCodeFeeRevenue)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PostJournalEntry(tx, reference, description, []models.JournalLine{
+		Debit(wallet.ID, amountGsaltUnits+feeGsaltUnits),
+		Credit(clearing.ID, amountGsaltUnits),
+		Credit(feeRevenue.ID, feeGsaltUnits),
+	})
+}
+
+// RecordTopupRefund debits a wallet for money returned to the payer through the provider that
+// collected it. Topup fees are not refunded.
+func (s *LedgerService) RecordTopupRefund(tx *gorm.DB, accountID uuid.UUID, providerCode string, amountGsaltUnits int64, reference string, description *string) (*models.JournalEntry, error) {
+	wallet, err := s.GetWalletAccount(tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	clearing, err := s.GetProviderClearingAccount(tx, providerCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PostJournalEntry(tx, reference, description, []models.JournalLine{
+		Debit(wallet.ID, amountGsaltUnits),
+		Credit(clearing.ID, amountGsaltUnits),
+	})
+}
+
+// RecordMerchantPayment moves a payment from the payer's wallet to the merchant's wallet.
+// The merchant fee is taken out of the amount the merchant receives.
+func (s *LedgerService) RecordMerchantPayment(tx *gorm.DB, payerAccountID, merchantAccountID uuid.UUID, amountGsaltUnits, feeGsaltUnits int64, reference string, description *string) (*models.JournalEntry, error) {
+	payer, err := s.GetWalletAccount(tx, payerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	merchant, err := s.GetWalletAccount(tx, merchantAccountID)
+	if err != nil {
+		return nil, err
+	}
+	feeRevenue, err := s.GetSystemAccount(tx, models.LedgerAccountCodeFeeRevenue)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PostJournalEntry(tx, reference, description, []models.JournalLine{
+		Debit(payer.ID, amountGsaltUnits),
+		Credit(merchant.ID, amountGsaltUnits-feeGsaltUnits),
+		Credit(feeRevenue.ID, feeGsaltUnits),
+	})
+}
+
+// RecordSandboxPayment records a test mode merchant payment between sandbox wallets, with the
+// same fee split as RecordMerchantPayment
+func (s *LedgerService) RecordSandboxPayment(tx *gorm.DB, payerAccountID, merchantAccountID uuid.UUID, amountGsaltUnits, feeGsaltUnits int64, reference string, description *string) (*models.JournalEntry, error) {
+	payer, err := s.GetSandboxWalletAccount(tx, payerAccountID)
+	if err != nil {
+		return nil, err
+	}
+	merchant, err := s.GetSandboxWalletAccount(tx, merchantAccountID)
+	if err != nil {
+		return nil, err
+	}
+	feeRevenue := models.LedgerAccount{
+		Code:     models.LedgerAccountCodeSandboxFeeRevenue,
+		Name:     "Sandbox Fee Revenue",
+		Type:     models.LedgerAccountTypeRevenue,
+		Currency: "GSALT",
+	}
+	if err := tx.Where("code = ?", feeRevenue.Code).FirstOrCreate(&feeRevenue).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get sandbox fee revenue ledger account")
+	}
+
+	return s.PostJournalEntry(tx, reference, description, []models.JournalLine{
+		Debit(payer.ID, amountGsaltUnits),
+		Credit(merchant.ID, amountGsaltUnits-feeGsaltUnits),
+		Credit(feeRevenue.ID, feeGsaltUnits),
+	})
+}
+
+// RecordPromoCredit credits a wallet with promotional funds (gifts, vouchers)
+func (s *LedgerService) RecordPromoCredit(tx *gorm.DB, accountID uuid.UUID, amountGsaltUnits int64, reference string, description *string) (*models.JournalEntry, error) {
+	wallet, err := s.GetWalletAccount(tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	promo, err := s.GetSystemAccount(tx, models.LedgerAccountCodePromoExpense)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PostJournalEntry(tx, reference, description, []models.JournalLine{
+		Debit(promo.ID, amountGsaltUnits),
+		Credit(wallet.ID, amountGsaltUnits),
+	})
+}
+
+// RecordAdjustment credits or debits a wallet for a manual correction made by an admin
+func (s *LedgerService) RecordAdjustment(tx *gorm.DB, accountID uuid.UUID, direction models.AdjustmentDirection, amountGsaltUnits int64, reference string, description *string) (*models.JournalEntry, error) {
+	wallet, err := s.GetWalletAccount(tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	adjustment, err := s.GetSystemAccount(tx, models.LedgerAccountCodeManualAdjustment)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []models.JournalLine{
+		Debit(adjustment.ID, amountGsaltUnits),
+		Credit(wallet.ID, amountGsaltUnits),
+	}
+	if direction == models.AdjustmentDirectionDebit {
+		lines = []models.JournalLine{
+			Debit(wallet.ID, amountGsaltUnits),
+			Credit(adjustment.ID, amountGsaltUnits),
+		}
+	}
+
+	return s.PostJournalEntry(tx, reference, description, lines)
+}
+
+// RecordDonation debits a wallet for a balance given away when its account is closed
+func (s *LedgerService) RecordDonation(tx *gorm.DB, accountID uuid.UUID, amountGsaltUnits int64, reference string, description *string) (*models.JournalEntry, error) {
+	wallet, err := s.GetWalletAccount(tx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	donations, err := s.GetSystemAccount(tx, models.LedgerAccountCodeDonationsPayable)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.PostJournalEntry(tx, reference, description, []models.JournalLine{
+		Debit(wallet.ID, amountGsaltUnits),
+		Credit(donations.ID, amountGsaltUnits),
+	})
+}
+
+// ReverseJournalEntry posts a new entry that mirrors the original with every direction flipped
+func (s *LedgerService) ReverseJournalEntry(tx *gorm.DB, entryID uuid.UUID, reference string, description *string) (*models.JournalEntry, error) {
+	var postings []models.Posting
+	if err := tx.Where("journal_entry_id = ?", entryID).Find(&postings).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get postings")
+	}
+	if len(postings) == 0 {
+		return nil, errors.NewNotFoundError("Journal entry not found")
+	}
+
+	lines := make([]models.JournalLine, 0, len(postings))
+	for _, posting := range postings {
+		direction := models.PostingDirectionDebit
+		if posting.Direction == models.PostingDirectionDebit {
+			direction = models.PostingDirectionCredit
+		}
+		lines = append(lines, models.JournalLine{
+			LedgerAccountID: posting.LedgerAccountID,
+			Direction:       direction,
+			Amount:          posting.Amount,
+		})
+	}
+
+	return s.PostJournalEntry(tx, reference, description, lines)
+}
+
+// PlaceHold reserves amount of an account's available balance within tx.
+// The held amount cannot be spent until the hold is captured or released.
+func (s *LedgerService) PlaceHold(tx *gorm.DB, accountID uuid.UUID, amount int64, reference string, transactionID *uuid.UUID, description *string) (*models.BalanceHold, error) {
+	if amount <= 0 {
+		return nil, errors.NewBadRequestError("Hold amount must be greater than zero")
+	}
+
+	// Conditional increment so concurrent holds and debits can never over-commit the balance
+	result := tx.Model(&models.Account{}).
+		Where("connect_id = ? AND balance - held_balance >= ?", accountID, amount).
+		Update("held_balance", gorm.Expr("held_balance + ?", amount))
+	if result.Error != nil {
+		return nil, errors.NewInternalServerError(result.Error, "Failed to hold balance")
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := tx.Model(&models.Account{}).Where("connect_id = ?", accountID).Count(&count).Error; err != nil {
+			return nil, errors.NewInternalServerError(err, "Failed to get account")
+		}
+		if count == 0 {
+			return nil, errors.NewNotFoundError("Account not found")
+		}
+		return nil, errors.NewBadRequestError("Insufficient available balance [" + ErrCodeInsufficientBalance + "]")
+	}
+
+	hold := &models.BalanceHold{
+		AccountID:     accountID,
+		TransactionID: transactionID,
+		Reference:     reference,
+		Description:   description,
+		Amount:        amount,
+		Status:        models.BalanceHoldStatusActive,
+	}
+	if err := tx.Create(hold).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to create balance hold")
+	}
+
+	return hold, nil
+}
+
+// CaptureHold finalizes an active hold for amount, which may be less than the held amount
+// (partial capture); the remainder becomes available again. Capturing only lifts the
+// reservation: the caller must post the journal entry that debits the captured amount in tx.
+func (s *LedgerService) CaptureHold(tx *gorm.DB, holdID uuid.UUID, amount int64) (*models.BalanceHold, error) {
+	hold, err := s.lockActiveHold(tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if amount <= 0 || amount > hold.Amount {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Capture amount must be between 1 and %d", hold.Amount))
+	}
+
+	if err := s.liftHold(tx, hold); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	hold.Status = models.BalanceHoldStatusCaptured
+	hold.CapturedAmount = amount
+	hold.CapturedAt = &now
+	if err := tx.Save(hold).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to update balance hold")
+	}
+
+	return hold, nil
+}
+
+// ReleaseHold cancels an active hold and makes its full amount available again
+func (s *LedgerService) ReleaseHold(tx *gorm.DB, holdID uuid.UUID) (*models.BalanceHold, error) {
+	hold, err := s.lockActiveHold(tx, holdID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.liftHold(tx, hold); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	hold.Status = models.BalanceHoldStatusReleased
+	hold.ReleasedAt = &now
+	if err := tx.Save(hold).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to update balance hold")
+	}
+
+	return hold, nil
+}
+
+// GetTransactionHold returns the latest hold placed for a transaction, or nil when it has none
+func (s *LedgerService) GetTransactionHold(tx *gorm.DB, transactionID uuid.UUID) (*models.BalanceHold, error) {
+	var hold models.BalanceHold
+	if err := tx.Where("transaction_id = ?", transactionID).Order("created_at DESC").First(&hold).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get balance hold")
+	}
+	return &hold, nil
+}
+
+// lockActiveHold loads a hold with a row lock and checks that it is still active
+func (s *LedgerService) lockActiveHold(tx *gorm.DB, holdID uuid.UUID) (*models.BalanceHold, error) {
+	var hold models.BalanceHold
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", holdID).First(&hold).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Balance hold not found")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get balance hold")
+	}
+
+	if hold.Status != models.BalanceHoldStatusActive {
+		return nil, errors.NewBadRequestError("Balance hold is already " + string(hold.Status))
+	}
+
+	return &hold, nil
+}
+
+// liftHold removes an active hold's amount from the account's held balance
+func (s *LedgerService) liftHold(tx *gorm.DB, hold *models.BalanceHold) error {
+	if err := tx.Unscoped().Model(&models.Account{}).
+		Where("connect_id = ?", hold.AccountID).
+		Update("held_balance", gorm.Expr("held_balance - ?", hold.Amount)).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to release held balance")
+	}
+	return nil
+}
+
+// GetJournalEntry retrieves a journal entry with its postings
+func (s *LedgerService) GetJournalEntry(entryId string) (*models.JournalEntry, error) {
+	entryUUID, err := uuid.Parse(entryId)
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid journal entry ID format")
+	}
+
+	var entry models.JournalEntry
+	if err := s.db.Preload("Postings").Where("id = ?", entryUUID).First(&entry).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Journal entry not found")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get journal entry")
+	}
+
+	return &entry, nil
+}
+
+// GetWalletPostings retrieves the postings of an account's wallet with pagination
+func (s *LedgerService) GetWalletPostings(accountId string, pagination *models.PaginationRequest) (*models.Pagination[[]models.Posting], error) {
+	accountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid account ID format")
+	}
+
+	wallet, err := s.GetWalletAccount(s.db, accountUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pagination.Limit <= 0 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	var totalItems int64
+	if err := s.db.Model(&models.Posting{}).Where("ledger_account_id = ?", wallet.ID).Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count postings")
+	}
+
+	var postings []models.Posting
+	query := s.db.Where("ledger_account_id = ?", wallet.ID).Order("created_at DESC")
+
+	if pagination.Limit > 0 {
+		query = query.Limit(pagination.Limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Find(&postings).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get postings")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+	hasNext := pagination.Page < totalPages
+	hasPrev := pagination.Page > 1
+
+	result := &models.Pagination[[]models.Posting]{
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: totalPages,
+		TotalItems: int(totalItems),
+		HasNext:    hasNext,
+		HasPrev:    hasPrev,
+		Items:      postings,
+	}
+
+	return result, nil
+}
+
+// GetHolds retrieves the balance holds of an account with pagination
+func (s *LedgerService) GetHolds(accountId string, pagination *models.PaginationRequest) (*models.Pagination[[]models.BalanceHold], error) {
+	accountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid account ID format")
+	}
+
+	if pagination.Limit <= 0 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	var totalItems int64
+	if err := s.db.Model(&models.BalanceHold{}).Where("account_id = ?", accountUUID).Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count balance holds")
+	}
+
+	var holds []models.BalanceHold
+	query := s.db.Where("account_id = ?", accountUUID).Order("created_at DESC")
+
+	if pagination.Limit > 0 {
+		query = query.Limit(pagination.Limit)
+	}
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Find(&holds).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get balance holds")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return &models.Pagination[[]models.BalanceHold]{
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: totalPages,
+		TotalItems: int(totalItems),
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+		Items:      holds,
+	}, nil
+}
+
+// ReconcileWallet compares accounts.balance with the wallet ledger balance and the sum of its postings
+func (s *LedgerService) ReconcileWallet(accountId string) (*models.LedgerReconciliation, error) {
+	accountUUID, err := uuid.Parse(accountId)
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid account ID format")
+	}
+
+	var account models.Account
+	if err := s.db.Where("connect_id = ?", accountUUID).First(&account).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Account not found")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get account")
+	}
+
+	wallet, err := s.GetWalletAccount(s.db, accountUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var postingsBalance int64
+	if err := s.db.Model(&models.Posting{}).
+		Where("ledger_account_id = ?", wallet.ID).
+		Select("COALESCE(SUM(CASE WHEN direction = ? THEN amount ELSE -amount END), 0)", models.PostingDirectionCredit).
+		Scan(&postingsBalance).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to sum postings")
+	}
+
+	var activeHolds int64
+	if err := s.db.Model(&models.BalanceHold{}).
+		Where("account_id = ? AND status = ?", accountUUID, models.BalanceHoldStatusActive).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&activeHolds).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to sum balance holds")
+	}
+
+	return &models.LedgerReconciliation{
+		AccountID:          account.ConnectID,
+		LedgerAccountID:    wallet.ID,
+		AccountBalance:     account.Balance,
+		LedgerBalance:      wallet.Balance,
+		PostingsBalance:    postingsBalance,
+		HeldBalance:        account.HeldBalance,
+		ActiveHoldsBalance: activeHolds,
+		Balanced:           account.Balance == wallet.Balance && wallet.Balance == postingsBalance && account.HeldBalance == activeHolds,
+	}, nil
+}
@@ -2,74 +2,144 @@ package services
 
 import (
 	"context"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base32"
-	"errors"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"net/netip"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
 	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
 	"gorm.io/gorm"
 )
 
+const (
+	// maxActiveAPIKeysPerMerchant bounds how many unrevoked keys a merchant can hold
+	maxActiveAPIKeysPerMerchant = 10
+	// apiKeyIDLength is the number of characters after the prefix that form the public key ID
+	apiKeyIDLength = 12
+)
+
 var (
-	ErrInvalidAPIKey = errors.New("invalid API key")
-	ErrAPIKeyExpired = errors.New("API key expired")
-	ErrInvalidScope  = errors.New("invalid scope")
+	ErrInvalidAPIKey = errors.NewNotFoundError("API key not found")
+	ErrAPIKeyExpired = errors.NewUnauthorizedError("API key is revoked or expired")
+	ErrInvalidScope  = errors.NewBadRequestError("Invalid API key scope")
 )
 
 // MerchantAPIKeyService handles merchant API key operations
 type MerchantAPIKeyService struct {
-	db *gorm.DB
+	db        *gorm.DB
+	validator *infrastructures.Validator
 }
 
 // NewMerchantAPIKeyService creates a new MerchantAPIKeyService
-func NewMerchantAPIKeyService(db *gorm.DB) *MerchantAPIKeyService {
-	return &MerchantAPIKeyService{db: db}
+func NewMerchantAPIKeyService(db *gorm.DB, validator *infrastructures.Validator) *MerchantAPIKeyService {
+	return &MerchantAPIKeyService{db: db, validator: validator}
 }
 
-// CreateAPIKey creates a new API key for a merchant
-func (s *MerchantAPIKeyService) CreateAPIKey(ctx context.Context, merchantID uuid.UUID, keyName string, scopes []models.APIKeyScope, rateLimit int, expiresAt *time.Time) (*models.MerchantAPIKey, error) {
+// CreateAPIKey creates a new API key for a merchant and returns it with its secret value
+func (s *MerchantAPIKeyService) CreateAPIKey(ctx context.Context, merchantID uuid.UUID, req *models.APIKeyCreateRequest) (*models.MerchantAPIKeySecretResponse, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
 	// Validate scopes
-	for _, scope := range scopes {
+	for _, scope := range req.Scopes {
 		if !s.isValidScope(scope) {
 			return nil, ErrInvalidScope
 		}
 	}
 
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.NewBadRequestError("expires_at must be in the future")
+	}
+
+	allowedCIDRs, err := normalizeCIDRs(req.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	environment := models.APIKeyEnvironmentLive
+	if req.Environment != nil {
+		environment = *req.Environment
+	}
+
+	var activeKeys int64
+	if err := s.db.WithContext(ctx).Model(&models.MerchantAPIKey{}).
+		Where("merchant_id = ? AND deleted_at IS NULL", merchantID).
+		Count(&activeKeys).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count API keys")
+	}
+	if activeKeys >= maxActiveAPIKeysPerMerchant {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("A merchant can have at most %d active API keys", maxActiveAPIKeysPerMerchant))
+	}
+
 	// Generate API key
-	apiKey, prefix, err := s.generateAPIKey()
+	apiKey, prefix, err := s.generateAPIKey(environment)
+	if err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to generate API key")
+	}
+	keyID, _ := parseAPIKeyID(apiKey)
+
+	signingSecret, err := generateRequestSigningSecret()
 	if err != nil {
 		return nil, err
 	}
 
+	rateLimit := 100
+	if req.RateLimit != nil {
+		rateLimit = *req.RateLimit
+	}
+
 	// Create API key record
 	key := &models.MerchantAPIKey{
-		MerchantID: merchantID,
-		KeyName:    keyName,
-		APIKey:     apiKey,
-		Prefix:     prefix,
-		Scopes:     scopes,
-		RateLimit:  rateLimit,
-		ExpiresAt:  expiresAt,
+		MerchantID:       merchantID,
+		KeyName:          req.KeyName,
+		KeyID:            keyID,
+		KeyHash:          hashAPIKey(apiKey),
+		Prefix:           prefix,
+		Environment:      environment,
+		AllowedCIDRs:     allowedCIDRs,
+		SigningSecret:    &signingSecret,
+		RequireSignature: req.RequireSignature != nil && *req.RequireSignature,
+		Scopes:           req.Scopes,
+		RateLimit:        rateLimit,
+		ExpiresAt:        req.ExpiresAt,
 	}
 
 	if err := s.db.WithContext(ctx).Create(key).Error; err != nil {
-		return nil, err
+		return nil, errors.NewInternalServerError(err, "Failed to create API key")
 	}
 
-	return key, nil
+	return &models.MerchantAPIKeySecretResponse{MerchantAPIKey: *key, APIKey: apiKey, SigningSecret: signingSecret}, nil
 }
 
-// GetAPIKey gets an API key by its value
+// GetAPIKey gets an API key by its value. The key is looked up by its public key ID and the
+// secret is compared against the stored hash in constant time.
 func (s *MerchantAPIKeyService) GetAPIKey(ctx context.Context, apiKey string) (*models.MerchantAPIKey, error) {
+	keyID, ok := parseAPIKeyID(apiKey)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
 	var key models.MerchantAPIKey
-	if err := s.db.WithContext(ctx).Where("api_key = ?", apiKey).First(&key).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	if err := s.db.WithContext(ctx).Where("key_id = ?", keyID).First(&key).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrInvalidAPIKey
 		}
-		return nil, err
+		return nil, errors.NewInternalServerError(err, "Failed to get API key")
+	}
+
+	if !verifyAPIKey(&key, apiKey) {
+		return nil, ErrInvalidAPIKey
 	}
 
 	if !key.IsActive() {
@@ -79,30 +149,90 @@ func (s *MerchantAPIKeyService) GetAPIKey(ctx context.Context, apiKey string) (*
 	return &key, nil
 }
 
-// LogAPIKeyUsage logs API key usage
-func (s *MerchantAPIKeyService) LogAPIKeyUsage(ctx context.Context, usage *models.MerchantAPIKeyUsage) error {
-	return s.db.WithContext(ctx).Create(usage).Error
+// HashLegacyAPIKeys hashes the plaintext keys stored before hashing was introduced and clears
+// the plaintext. It is safe to run repeatedly and returns the number of keys hashed.
+func (s *MerchantAPIKeyService) HashLegacyAPIKeys(ctx context.Context) (int, error) {
+	var keys []models.MerchantAPIKey
+	if err := s.db.WithContext(ctx).
+		Where("key_hash IS NULL AND api_key IS NOT NULL").
+		Find(&keys).Error; err != nil {
+		return 0, errors.NewInternalServerError(err, "Failed to get legacy API keys")
+	}
+
+	for _, key := range keys {
+		if err := s.db.WithContext(ctx).Model(&models.MerchantAPIKey{}).
+			Where("id = ? AND key_hash IS NULL", key.ID).
+			Updates(map[string]interface{}{
+				"key_hash": hashAPIKey(*key.LegacyAPIKey),
+				"api_key":  nil,
+			}).Error; err != nil {
+			return 0, errors.NewInternalServerError(err, "Failed to hash legacy API key")
+		}
+	}
+
+	return len(keys), nil
+}
+
+// RecordUsageBatch bulk inserts usage records and moves last_used_at of the keys involved forward
+func (s *MerchantAPIKeyService) RecordUsageBatch(ctx context.Context, usage []models.MerchantAPIKeyUsage) error {
+	lastUsed := make(map[uuid.UUID]time.Time)
+	for _, record := range usage {
+		if record.CreatedAt.After(lastUsed[record.APIKeyID]) {
+			lastUsed[record.APIKeyID] = record.CreatedAt
+		}
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(usage, len(usage)).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to record API key usage")
+		}
+
+		for keyID, usedAt := range lastUsed {
+			if err := tx.Model(&models.MerchantAPIKey{}).
+				Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, usedAt).
+				UpdateColumn("last_used_at", usedAt).Error; err != nil {
+				return errors.NewInternalServerError(err, "Failed to update API key last used time")
+			}
+		}
+
+		return nil
+	})
 }
 
 // ListAPIKeys lists all API keys for a merchant
 func (s *MerchantAPIKeyService) ListAPIKeys(ctx context.Context, merchantID uuid.UUID) ([]models.MerchantAPIKey, error) {
 	var keys []models.MerchantAPIKey
-	if err := s.db.WithContext(ctx).Where("merchant_id = ?", merchantID).Find(&keys).Error; err != nil {
-		return nil, err
+	if err := s.db.WithContext(ctx).Where("merchant_id = ?", merchantID).Order("created_at DESC").Find(&keys).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to list API keys")
 	}
 	return keys, nil
 }
 
+// GetMerchantAPIKey gets one of a merchant's API keys by ID, including revoked keys
+func (s *MerchantAPIKeyService) GetMerchantAPIKey(ctx context.Context, id uuid.UUID, merchantID uuid.UUID) (*models.MerchantAPIKey, error) {
+	var key models.MerchantAPIKey
+	if err := s.db.WithContext(ctx).Where("id = ? AND merchant_id = ?", id, merchantID).First(&key).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get API key")
+	}
+	return &key, nil
+}
+
 // RevokeAPIKey revokes an API key
 func (s *MerchantAPIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID, merchantID uuid.UUID) error {
 	now := time.Now()
 	result := s.db.WithContext(ctx).
 		Model(&models.MerchantAPIKey{}).
-		Where("id = ? AND merchant_id = ?", id, merchantID).
-		Update("deleted_at", now)
+		Where("id = ? AND merchant_id = ? AND deleted_at IS NULL", id, merchantID).
+		Updates(map[string]interface{}{
+			"deleted_at": now,
+			"updated_at": now,
+		})
 
 	if result.Error != nil {
-		return result.Error
+		return errors.NewInternalServerError(result.Error, "Failed to revoke API key")
 	}
 	if result.RowsAffected == 0 {
 		return ErrInvalidAPIKey
@@ -110,41 +240,193 @@ func (s *MerchantAPIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID,
 	return nil
 }
 
-// UpdateAPIKey updates an API key's properties
-func (s *MerchantAPIKeyService) UpdateAPIKey(ctx context.Context, id uuid.UUID, merchantID uuid.UUID, keyName string, scopes []models.APIKeyScope, rateLimit int, expiresAt *time.Time) (*models.MerchantAPIKey, error) {
+// UpdateAPIKey updates an API key's name, scopes, rate limit or expiry
+func (s *MerchantAPIKeyService) UpdateAPIKey(ctx context.Context, id uuid.UUID, merchantID uuid.UUID, req *models.APIKeyUpdateRequest) (*models.MerchantAPIKey, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
 	// Validate scopes
-	for _, scope := range scopes {
+	for _, scope := range req.Scopes {
 		if !s.isValidScope(scope) {
 			return nil, ErrInvalidScope
 		}
 	}
 
-	var key models.MerchantAPIKey
-	if err := s.db.WithContext(ctx).Where("id = ? AND merchant_id = ?", id, merchantID).First(&key).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrInvalidAPIKey
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.NewBadRequestError("expires_at must be in the future")
+	}
+
+	var allowedCIDRs []string
+	if req.AllowedCIDRs != nil {
+		var err error
+		allowedCIDRs, err = normalizeCIDRs(req.AllowedCIDRs)
+		if err != nil {
+			return nil, err
 		}
+	}
+
+	key, err := s.getActiveAPIKey(ctx, id, merchantID)
+	if err != nil {
 		return nil, err
 	}
 
 	// Update fields
-	key.KeyName = keyName
-	key.Scopes = scopes
-	key.RateLimit = rateLimit
-	key.ExpiresAt = expiresAt
+	if req.KeyName != nil {
+		key.KeyName = *req.KeyName
+	}
+	if req.Scopes != nil {
+		key.Scopes = req.Scopes
+	}
+	if req.RateLimit != nil {
+		key.RateLimit = *req.RateLimit
+	}
+	if req.AllowedCIDRs != nil {
+		key.AllowedCIDRs = allowedCIDRs
+	}
+	if req.ExpiresAt != nil {
+		key.ExpiresAt = req.ExpiresAt
+	}
+	if req.RequireSignature != nil {
+		if *req.RequireSignature && key.SigningSecret == nil {
+			return nil, errors.NewBadRequestError("API key has no signing secret; rotate it before requiring signatures")
+		}
+		key.RequireSignature = *req.RequireSignature
+	}
 	key.UpdatedAt = time.Now()
 
-	if err := s.db.WithContext(ctx).Save(&key).Error; err != nil {
+	if err := s.db.WithContext(ctx).Save(key).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to update API key")
+	}
+
+	return key, nil
+}
+
+// RotateAPIKey replaces the secret value and signing secret of an API key, keeping its scopes
+// and settings. The previous values stop working immediately.
+func (s *MerchantAPIKeyService) RotateAPIKey(ctx context.Context, id uuid.UUID, merchantID uuid.UUID) (*models.MerchantAPIKeySecretResponse, error) {
+	key, err := s.getActiveAPIKey(ctx, id, merchantID)
+	if err != nil {
 		return nil, err
 	}
 
-	return &key, nil
+	apiKey, prefix, err := s.generateAPIKey(key.Environment)
+	if err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to generate API key")
+	}
+
+	signingSecret, err := generateRequestSigningSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	key.KeyID, _ = parseAPIKeyID(apiKey)
+	key.KeyHash = hashAPIKey(apiKey)
+	key.LegacyAPIKey = nil
+	key.Prefix = prefix
+	key.SigningSecret = &signingSecret
+	key.UpdatedAt = time.Now()
+
+	if err := s.db.WithContext(ctx).Model(key).Updates(map[string]interface{}{
+		"key_id":         key.KeyID,
+		"key_hash":       key.KeyHash,
+		"api_key":        nil,
+		"prefix":         key.Prefix,
+		"signing_secret": signingSecret,
+		"updated_at":     key.UpdatedAt,
+	}).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to rotate API key")
+	}
+
+	return &models.MerchantAPIKeySecretResponse{MerchantAPIKey: *key, APIKey: apiKey, SigningSecret: signingSecret}, nil
+}
+
+// GetAPIKeyUsage returns the usage summary of a key from mv_merchant_api_key_analytics and its
+// most recent requests from merchant_api_key_usage. The summary is nil until the view is
+// refreshed after the key is created.
+func (s *MerchantAPIKeyService) GetAPIKeyUsage(ctx context.Context, id uuid.UUID, merchantID uuid.UUID, pagination *models.PaginationRequest) (*models.APIKeyUsageResponse, error) {
+	if _, err := s.GetMerchantAPIKey(ctx, id, merchantID); err != nil {
+		return nil, err
+	}
+
+	if pagination.Limit <= 0 || pagination.Limit > 100 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	var summary *models.MerchantAPIKeyAnalytics
+	var analytics models.MerchantAPIKeyAnalytics
+	if err := s.db.WithContext(ctx).Where("api_key_id = ?", id).First(&analytics).Error; err == nil {
+		summary = &analytics
+	} else if !stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NewInternalServerError(err, "Failed to get API key analytics")
+	}
+
+	var totalItems int64
+	if err := s.db.WithContext(ctx).Model(&models.MerchantAPIKeyUsage{}).Where("api_key_id = ?", id).Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count API key usage")
+	}
+
+	var usage []models.MerchantAPIKeyUsage
+	if err := s.db.WithContext(ctx).
+		Where("api_key_id = ?", id).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pagination.Limit).
+		Find(&usage).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get API key usage")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return &models.APIKeyUsageResponse{
+		Summary: summary,
+		Requests: &models.Pagination[[]models.MerchantAPIKeyUsage]{
+			Page:       pagination.Page,
+			Limit:      pagination.Limit,
+			TotalPages: totalPages,
+			TotalItems: int(totalItems),
+			HasNext:    pagination.Page < totalPages,
+			HasPrev:    pagination.Page > 1,
+			Items:      usage,
+		},
+	}, nil
 }
 
-// generateAPIKey generates a new API key with prefix
-func (s *MerchantAPIKeyService) generateAPIKey() (string, string, error) {
+// GetAPIKeyAnalytics returns the usage summary of every active key of a merchant
+func (s *MerchantAPIKeyService) GetAPIKeyAnalytics(ctx context.Context, merchantID uuid.UUID) ([]models.MerchantAPIKeyAnalytics, error) {
+	var analytics []models.MerchantAPIKeyAnalytics
+	if err := s.db.WithContext(ctx).Where("merchant_id = ?", merchantID).Order("total_requests DESC").Find(&analytics).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get API key analytics")
+	}
+	return analytics, nil
+}
+
+// getActiveAPIKey gets a merchant's API key that has not been revoked
+func (s *MerchantAPIKeyService) getActiveAPIKey(ctx context.Context, id uuid.UUID, merchantID uuid.UUID) (*models.MerchantAPIKey, error) {
+	key, err := s.GetMerchantAPIKey(ctx, id, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if key.DeletedAt != nil {
+		return nil, errors.NewBadRequestError("API key has been revoked")
+	}
+	return key, nil
+}
+
+// generateAPIKey generates a new API key with prefix, mk_ for live keys and mk_test_ for test
+// keys. The first apiKeyIDLength characters after the prefix are the public key ID, the
+// remaining 200 bits are the secret.
+func (s *MerchantAPIKeyService) generateAPIKey(environment models.APIKeyEnvironment) (string, string, error) {
 	prefix := "mk"
-	bytes := make([]byte, 20)
+	if environment == models.APIKeyEnvironmentTest {
+		prefix = "mk_test"
+	}
+	bytes := make([]byte, 32)
 	if _, err := rand.Read(bytes); err != nil {
 		return "", "", err
 	}
@@ -155,6 +437,83 @@ func (s *MerchantAPIKeyService) generateAPIKey() (string, string, error) {
 	return prefix + "_" + encoded, prefix, nil
 }
 
+// SignRequest returns the hex HMAC-SHA256 merchants send in the X-GSALT-Signature header. The
+// signed string is the method, request path with query string, timestamp, nonce and hex SHA-256
+// of the body, joined by newlines.
+func SignRequest(secret, method, path, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		timestamp,
+		nonce,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateRequestSigningSecret generates the secret an API key signs requests with
+func generateRequestSigningSecret() (string, error) {
+	secret, err := generateSigningSecret()
+	if err != nil {
+		return "", err
+	}
+	return "mks_" + secret, nil
+}
+
+// parseAPIKeyID extracts the public key ID from an API key value
+func parseAPIKeyID(apiKey string) (string, bool) {
+	separator := strings.LastIndex(apiKey, "_")
+	if separator < 0 {
+		return "", false
+	}
+
+	body := apiKey[separator+1:]
+	if len(body) <= apiKeyIDLength {
+		return "", false
+	}
+
+	return body[:apiKeyIDLength], true
+}
+
+// normalizeCIDRs parses an IP allowlist, turning single addresses into /32 or /128 ranges
+func normalizeCIDRs(entries []string) ([]string, error) {
+	cidrs := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(entry)
+			if addrErr != nil {
+				return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid CIDR range or IP address: %s", entry))
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		cidrs = append(cidrs, prefix.Masked().String())
+	}
+	return cidrs, nil
+}
+
+// hashAPIKey returns the hex HMAC-SHA256 of an API key keyed with the server pepper
+func hashAPIKey(apiKey string) string {
+	mac := hmac.New(sha256.New, []byte(infrastructures.Config.APIKeyPepper))
+	mac.Write([]byte(apiKey))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyAPIKey compares an API key value against a stored key in constant time. Keys that have
+// not been hashed yet are compared against their plaintext.
+func verifyAPIKey(key *models.MerchantAPIKey, apiKey string) bool {
+	if key.KeyHash != "" {
+		return hmac.Equal([]byte(hashAPIKey(apiKey)), []byte(key.KeyHash))
+	}
+	if key.LegacyAPIKey != nil {
+		return subtle.ConstantTimeCompare([]byte(apiKey), []byte(*key.LegacyAPIKey)) == 1
+	}
+	return false
+}
+
 // isValidScope checks if a scope is valid
 func (s *MerchantAPIKeyService) isValidScope(scope models.APIKeyScope) bool {
 	switch scope {
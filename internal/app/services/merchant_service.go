@@ -0,0 +1,388 @@
+package services
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Error codes for merchant onboarding requests
+const (
+	ErrCodeKYCNotVerified              = "KYC_NOT_VERIFIED"
+	ErrCodeMerchantApplicationPending  = "MERCHANT_APPLICATION_PENDING"
+	ErrCodeMerchantApplicationReviewed = "MERCHANT_APPLICATION_REVIEWED"
+	ErrCodeMerchantProfileNotFound     = "MERCHANT_PROFILE_NOT_FOUND"
+)
+
+// MerchantService handles merchant onboarding. A KYC-verified account applies with its business
+// profile and settlement bank account, and approval by an admin makes it a MERCHANT account
+// with a merchant profile. Merchants apply again to change reviewed details such as the
+// settlement bank account.
+type MerchantService struct {
+	db           *gorm.DB
+	validator    *infrastructures.Validator
+	auditService *AuditService
+}
+
+func NewMerchantService(db *gorm.DB, validator *infrastructures.Validator, auditService *AuditService) *MerchantService {
+	return &MerchantService{
+		db:           db,
+		validator:    validator,
+		auditService: auditService,
+	}
+}
+
+// Apply queues a merchant application for review. An account can have one pending application.
+func (s *MerchantService) Apply(accountID uuid.UUID, req *models.MerchantApplicationRequest) (*models.MerchantApplication, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	application := &models.MerchantApplication{
+		AccountID:               accountID,
+		BusinessName:            strings.TrimSpace(req.BusinessName),
+		BusinessType:            req.BusinessType,
+		Category:                req.Category,
+		Description:             req.Description,
+		TaxID:                   req.TaxID,
+		WebsiteURL:              req.WebsiteURL,
+		LogoURL:                 req.LogoURL,
+		ContactName:             strings.TrimSpace(req.ContactName),
+		ContactEmail:            strings.ToLower(req.ContactEmail),
+		ContactPhone:            req.ContactPhone,
+		SettlementBankCode:      strings.ToLower(req.SettlementBankCode),
+		SettlementAccountNumber: req.SettlementAccountNumber,
+		SettlementAccountHolder: strings.TrimSpace(req.SettlementAccountHolder),
+		Status:                  models.MerchantApplicationStatusPending,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		account, err := s.lockAccount(tx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if account.KYCStatus != models.KYCStatusVerified {
+			return errors.NewForbiddenError(fmt.Sprintf("KYC must be verified to apply as a merchant (%s) [%s]", account.KYCStatus, ErrCodeKYCNotVerified))
+		}
+
+		var pending int64
+		if err := tx.Model(&models.MerchantApplication{}).
+			Where("account_id = ? AND status = ?", accountID, models.MerchantApplicationStatusPending).
+			Count(&pending).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to check merchant applications")
+		}
+		if pending > 0 {
+			return errors.NewAppError(http.StatusConflict, "A merchant application is already waiting for review ["+ErrCodeMerchantApplicationPending+"]")
+		}
+
+		if err := tx.Create(application).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to create merchant application")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return application, nil
+}
+
+// GetAccountApplications lists an account's merchant applications, newest first
+func (s *MerchantService) GetAccountApplications(accountID uuid.UUID) ([]models.MerchantApplication, error) {
+	applications := []models.MerchantApplication{}
+	if err := s.db.Where("account_id = ?", accountID).Order("created_at DESC").Find(&applications).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get merchant applications")
+	}
+	return applications, nil
+}
+
+// GetApplications lists applications with the given status. Pending applications come oldest
+// first, as a review queue; other statuses newest first.
+func (s *MerchantService) GetApplications(status models.MerchantApplicationStatus, pagination *models.PaginationRequest) (*models.Pagination[[]models.MerchantApplication], error) {
+	if pagination.Limit <= 0 || pagination.Limit > 100 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	var totalItems int64
+	if err := s.db.Model(&models.MerchantApplication{}).Where("status = ?", status).Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count merchant applications")
+	}
+
+	order := "created_at DESC"
+	if status == models.MerchantApplicationStatusPending {
+		order = "created_at ASC"
+	}
+
+	var applications []models.MerchantApplication
+	if err := s.db.Where("status = ?", status).
+		Order(order).
+		Offset(offset).
+		Limit(pagination.Limit).
+		Find(&applications).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get merchant applications")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return &models.Pagination[[]models.MerchantApplication]{
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: totalPages,
+		TotalItems: int(totalItems),
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+		Items:      applications,
+	}, nil
+}
+
+// GetApplication returns an application by ID
+func (s *MerchantService) GetApplication(id uuid.UUID) (*models.MerchantApplication, error) {
+	return s.getApplication(s.db, id)
+}
+
+// ApproveApplication makes the account a merchant and replaces its merchant profile with the
+// application
+func (s *MerchantService) ApproveApplication(id uuid.UUID, reviewerID uuid.UUID, req *models.MerchantApplicationReviewRequest) (*models.MerchantApplication, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	var application *models.MerchantApplication
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		application, err = s.closeApplication(tx, id, reviewerID, req.Reason, models.MerchantApplicationStatusApproved)
+		if err != nil {
+			return err
+		}
+
+		account, err := s.lockAccount(tx, application.AccountID)
+		if err != nil {
+			return err
+		}
+
+		profile := &models.MerchantProfile{
+			AccountID:               application.AccountID,
+			ApplicationID:           application.ID,
+			BusinessName:            application.BusinessName,
+			BusinessType:            application.BusinessType,
+			Category:                application.Category,
+			Description:             application.Description,
+			TaxID:                   application.TaxID,
+			WebsiteURL:              application.WebsiteURL,
+			LogoURL:                 application.LogoURL,
+			ContactName:             application.ContactName,
+			ContactEmail:            application.ContactEmail,
+			ContactPhone:            application.ContactPhone,
+			SettlementBankCode:      application.SettlementBankCode,
+			SettlementAccountNumber: application.SettlementAccountNumber,
+			SettlementAccountHolder: application.SettlementAccountHolder,
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "account_id"}},
+			UpdateAll: true,
+		}).Create(profile).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to save merchant profile")
+		}
+
+		if account.AccountType != models.AccountTypeMerchant {
+			oldAccountType := account.AccountType
+			if err := tx.Model(account).Update("account_type", models.AccountTypeMerchant).Error; err != nil {
+				return errors.NewInternalServerError(err, "Failed to update account type")
+			}
+			if err := s.auditService.LogAuditTx(tx, "accounts", application.AccountID, models.AuditActionUpdate,
+				map[string]interface{}{"account_type": oldAccountType},
+				map[string]interface{}{"account_type": models.AccountTypeMerchant},
+				&reviewerID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return application, nil
+}
+
+// RejectApplication rejects an application with a reason. A merchant applying for a change
+// keeps its current profile.
+func (s *MerchantService) RejectApplication(id uuid.UUID, reviewerID uuid.UUID, req *models.MerchantApplicationReviewRequest) (*models.MerchantApplication, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+	if req.Reason == nil || strings.TrimSpace(*req.Reason) == "" {
+		return nil, errors.NewBadRequestError("A reason is required to reject a merchant application")
+	}
+
+	var application *models.MerchantApplication
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		application, err = s.closeApplication(tx, id, reviewerID, req.Reason, models.MerchantApplicationStatusRejected)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return application, nil
+}
+
+// GetProfile returns a merchant's own profile
+func (s *MerchantService) GetProfile(accountID uuid.UUID) (*models.MerchantProfile, error) {
+	var profile models.MerchantProfile
+	if err := s.db.Where("account_id = ?", accountID).First(&profile).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Merchant profile not found [" + ErrCodeMerchantProfileNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get merchant profile")
+	}
+	return &profile, nil
+}
+
+// GetPublicProfile returns what payers see about a merchant, or nil when the merchant has no
+// profile
+func (s *MerchantService) GetPublicProfile(merchantID uuid.UUID) (*models.MerchantPublicProfile, error) {
+	var profile models.MerchantProfile
+	if err := s.db.Where("account_id = ?", merchantID).First(&profile).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get merchant profile")
+	}
+	return profile.PublicProfile(), nil
+}
+
+// UpdateProfile changes the display and contact details of a merchant's profile
+func (s *MerchantService) UpdateProfile(accountID uuid.UUID, req *models.MerchantProfileUpdateRequest) (*models.MerchantProfile, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	profile, err := s.GetProfile(accountID)
+	if err != nil {
+		return nil, err
+	}
+	oldProfile := *profile
+
+	updates := map[string]interface{}{}
+	if req.BusinessName != nil {
+		profile.BusinessName = strings.TrimSpace(*req.BusinessName)
+		updates["business_name"] = profile.BusinessName
+	}
+	if req.Category != nil {
+		profile.Category = *req.Category
+		updates["category"] = profile.Category
+	}
+	if req.Description != nil {
+		profile.Description = req.Description
+		updates["description"] = profile.Description
+	}
+	if req.WebsiteURL != nil {
+		profile.WebsiteURL = req.WebsiteURL
+		updates["website_url"] = profile.WebsiteURL
+	}
+	if req.LogoURL != nil {
+		profile.LogoURL = req.LogoURL
+		updates["logo_url"] = profile.LogoURL
+	}
+	if req.ContactName != nil {
+		profile.ContactName = strings.TrimSpace(*req.ContactName)
+		updates["contact_name"] = profile.ContactName
+	}
+	if req.ContactEmail != nil {
+		profile.ContactEmail = strings.ToLower(*req.ContactEmail)
+		updates["contact_email"] = profile.ContactEmail
+	}
+	if req.ContactPhone != nil {
+		profile.ContactPhone = *req.ContactPhone
+		updates["contact_phone"] = profile.ContactPhone
+	}
+
+	if len(updates) == 0 {
+		return profile, nil
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(profile).Updates(updates).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update merchant profile")
+		}
+		return s.auditService.LogAuditTx(tx, "merchant_profiles", profile.AccountID, models.AuditActionUpdate, oldProfile, profile, &accountID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// closeApplication locks a pending application, records the review and audits it
+func (s *MerchantService) closeApplication(tx *gorm.DB, id uuid.UUID, reviewerID uuid.UUID, reason *string, status models.MerchantApplicationStatus) (*models.MerchantApplication, error) {
+	application, err := s.getApplication(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id)
+	if err != nil {
+		return nil, err
+	}
+	if application.Status != models.MerchantApplicationStatusPending {
+		return nil, errors.NewAppError(http.StatusConflict, fmt.Sprintf("Merchant application is already %s [%s]", application.Status, ErrCodeMerchantApplicationReviewed))
+	}
+
+	oldApplication := *application
+
+	now := time.Now()
+	application.Status = status
+	application.ReviewedBy = &reviewerID
+	application.ReviewReason = reason
+	application.ReviewedAt = &now
+	if err := tx.Model(application).Updates(map[string]interface{}{
+		"status":        application.Status,
+		"reviewed_by":   application.ReviewedBy,
+		"review_reason": application.ReviewReason,
+		"reviewed_at":   application.ReviewedAt,
+	}).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to update merchant application")
+	}
+
+	if err := s.auditService.LogAuditTx(tx, "merchant_applications", application.ID, models.AuditActionStatusChange, oldApplication, application, &reviewerID); err != nil {
+		return nil, err
+	}
+
+	return application, nil
+}
+
+func (s *MerchantService) lockAccount(tx *gorm.DB, accountID uuid.UUID) (*models.Account, error) {
+	var account models.Account
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("connect_id = ?", accountID).First(&account).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Account not found [" + ErrCodeAccountNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get account")
+	}
+	return &account, nil
+}
+
+func (s *MerchantService) getApplication(db *gorm.DB, id uuid.UUID) (*models.MerchantApplication, error) {
+	var application models.MerchantApplication
+	if err := db.Where("id = ?", id).First(&application).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Merchant application not found")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get merchant application")
+	}
+	return &application, nil
+}
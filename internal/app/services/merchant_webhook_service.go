@@ -0,0 +1,677 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// WebhookSignatureHeader carries the hex HMAC-SHA256 of "timestamp.body"
+	WebhookSignatureHeader = "X-GSALT-Signature"
+	// WebhookTimestampHeader carries the Unix time the delivery was signed at
+	WebhookTimestampHeader = "X-GSALT-Timestamp"
+	// WebhookEventHeader carries the event type
+	WebhookEventHeader = "X-GSALT-Event"
+	// WebhookDeliveryHeader carries the delivery ID, stable across retries
+	WebhookDeliveryHeader = "X-GSALT-Delivery"
+
+	// maxWebhookAttempts is how many automatic attempts are made before a delivery fails
+	maxWebhookAttempts = 12
+	// webhookBaseRetryDelay doubles after every failed attempt up to webhookMaxRetryDelay
+	webhookBaseRetryDelay = time.Minute
+	webhookMaxRetryDelay  = 6 * time.Hour
+	// webhookRequestTimeout bounds a single delivery request
+	webhookRequestTimeout = 10 * time.Second
+	// webhookDeliveryBatchSize bounds how many deliveries are loaded at once
+	webhookDeliveryBatchSize = 50
+	// maxWebhookResponseBodyLength is how much of an endpoint response is logged
+	maxWebhookResponseBodyLength = 2048
+	// webhookResolveTimeout bounds the DNS lookup of an endpoint URL at registration
+	webhookResolveTimeout = 5 * time.Second
+
+	ErrCodeWebhookURLNotAllowed = "WEBHOOK_URL_NOT_ALLOWED"
+)
+
+// nonPublicWebhookNetworks are ranges not covered by the net.IP helpers that webhooks must not reach
+var nonPublicWebhookNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),     // "this" network
+	mustParseCIDR("100.64.0.0/10"), // carrier-grade NAT
+	mustParseCIDR("192.0.0.0/24"),  // IETF protocol assignments
+	mustParseCIDR("198.18.0.0/15"), // benchmarking
+	mustParseCIDR("240.0.0.0/4"),   // reserved
+	mustParseCIDR("64:ff9b::/96"),  // NAT64, can embed any IPv4 address
+}
+
+// MerchantWebhookService manages merchant webhook endpoints and delivers events to them.
+// Events are written to an outbox with Enqueue in the caller's transaction and sent by the
+// deliver_webhooks job, so a change and its notification are committed together.
+// Delivery is at least once; merchants should deduplicate on the event ID.
+type MerchantWebhookService struct {
+	db         *gorm.DB
+	validator  *infrastructures.Validator
+	httpClient *http.Client
+}
+
+func NewMerchantWebhookService(db *gorm.DB, validator *infrastructures.Validator) *MerchantWebhookService {
+	return &MerchantWebhookService{
+		db:         db,
+		validator:  validator,
+		httpClient: newWebhookHTTPClient(),
+	}
+}
+
+// newWebhookHTTPClient returns a client that only connects to public addresses and does not
+// follow redirects. The address is checked on every connection, after DNS resolution, so an
+// endpoint cannot be pointed at internal services by changing its DNS records after registration.
+func newWebhookHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: webhookRequestTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+				return fmt.Errorf("webhook address %s is not a public address", host)
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: webhookRequestTimeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: webhookRequestTimeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// A redirect counts as a failed delivery rather than being followed
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// validateEndpointURL requires an https URL whose host resolves only to public addresses
+func validateEndpointURL(ctx context.Context, rawURL string) error {
+	notAllowed := func(reason string) error {
+		return errors.NewBadRequestError("Webhook URL " + reason + " [" + ErrCodeWebhookURLNotAllowed + "]")
+	}
+
+	endpointURL, err := url.Parse(rawURL)
+	if err != nil || endpointURL.Host == "" {
+		return notAllowed("is invalid")
+	}
+	if endpointURL.Scheme != "https" {
+		return notAllowed("must use https")
+	}
+	if endpointURL.User != nil {
+		return notAllowed("must not contain credentials")
+	}
+
+	host := endpointURL.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublicIP(ip) {
+			return notAllowed("must not point to a private, loopback or link-local address")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookResolveTimeout)
+	defer cancel()
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return notAllowed("host could not be resolved")
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return notAllowed("must not point to a private, loopback or link-local address")
+		}
+	}
+
+	return nil
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address
+func isPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicWebhookNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// CreateEndpoint registers a webhook endpoint for a merchant and returns its signing secret
+func (s *MerchantWebhookService) CreateEndpoint(merchantID uuid.UUID, req *models.WebhookEndpointCreateRequest) (*models.WebhookEndpointSecretResponse, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+	if err := validateEndpointURL(context.Background(), req.URL); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		MerchantID:  merchantID,
+		URL:         req.URL,
+		Description: req.Description,
+		Secret:      secret,
+		EventTypes:  req.EventTypes,
+		IsActive:    true,
+	}
+
+	if err := s.db.Create(endpoint).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to create webhook endpoint")
+	}
+
+	return &models.WebhookEndpointSecretResponse{WebhookEndpoint: *endpoint, Secret: secret}, nil
+}
+
+// GetEndpoints lists a merchant's webhook endpoints
+func (s *MerchantWebhookService) GetEndpoints(merchantID uuid.UUID) ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := s.db.Where("merchant_id = ?", merchantID).Order("created_at ASC").Find(&endpoints).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get webhook endpoints")
+	}
+	return endpoints, nil
+}
+
+// GetEndpoint returns one of a merchant's webhook endpoints
+func (s *MerchantWebhookService) GetEndpoint(merchantID uuid.UUID, endpointId string) (*models.WebhookEndpoint, error) {
+	endpointUUID, err := uuid.Parse(endpointId)
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid webhook endpoint ID")
+	}
+
+	var endpoint models.WebhookEndpoint
+	if err := s.db.Where("id = ? AND merchant_id = ?", endpointUUID, merchantID).First(&endpoint).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Webhook endpoint not found")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get webhook endpoint")
+	}
+
+	return &endpoint, nil
+}
+
+// UpdateEndpoint changes the URL, description, subscribed events or active flag of an endpoint
+func (s *MerchantWebhookService) UpdateEndpoint(merchantID uuid.UUID, endpointId string, req *models.WebhookEndpointUpdateRequest) (*models.WebhookEndpoint, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+	if req.URL != nil {
+		if err := validateEndpointURL(context.Background(), *req.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint, err := s.GetEndpoint(merchantID, endpointId)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		endpoint.URL = *req.URL
+	}
+	if req.Description != nil {
+		endpoint.Description = req.Description
+	}
+	if req.EventTypes != nil {
+		endpoint.EventTypes = req.EventTypes
+	}
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+	}
+
+	if err := s.db.Save(endpoint).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to update webhook endpoint")
+	}
+
+	return endpoint, nil
+}
+
+// RotateSecret replaces the signing secret of an endpoint. Deliveries sent afterwards,
+// including retries, are signed with the new secret.
+func (s *MerchantWebhookService) RotateSecret(merchantID uuid.UUID, endpointId string) (*models.WebhookEndpointSecretResponse, error) {
+	endpoint, err := s.GetEndpoint(merchantID, endpointId)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Model(endpoint).Update("secret", secret).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to rotate webhook secret")
+	}
+	endpoint.Secret = secret
+
+	return &models.WebhookEndpointSecretResponse{WebhookEndpoint: *endpoint, Secret: secret}, nil
+}
+
+// DeleteEndpoint removes an endpoint. Its pending deliveries fail on their next attempt.
+func (s *MerchantWebhookService) DeleteEndpoint(merchantID uuid.UUID, endpointId string) error {
+	endpoint, err := s.GetEndpoint(merchantID, endpointId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(endpoint).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to delete webhook endpoint")
+	}
+
+	return nil
+}
+
+// Enqueue writes an event and a pending delivery for every active endpoint of the merchant that
+// subscribes to it, using tx so the event commits or rolls back with the change it describes.
+// Nothing is written when no endpoint subscribes.
+func (s *MerchantWebhookService) Enqueue(tx *gorm.DB, merchantID uuid.UUID, eventType models.WebhookEventType, data interface{}) error {
+	var endpoints []models.WebhookEndpoint
+	if err := tx.Where("merchant_id = ? AND is_active = ?", merchantID, true).Find(&endpoints).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to get webhook endpoints")
+	}
+
+	subscribed := make([]models.WebhookEndpoint, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if endpoint.Subscribes(eventType) {
+			subscribed = append(subscribed, endpoint)
+		}
+	}
+	if len(subscribed) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return errors.NewInternalServerError(err, "Failed to encode webhook event")
+	}
+
+	event := &models.WebhookEvent{
+		MerchantID: merchantID,
+		Type:       eventType,
+		Data:       payload,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to create webhook event")
+	}
+
+	now := time.Now()
+	for _, endpoint := range subscribed {
+		delivery := &models.WebhookDelivery{
+			EventID:       event.ID,
+			EndpointID:    endpoint.ID,
+			MerchantID:    merchantID,
+			Status:        models.WebhookDeliveryStatusPending,
+			NextAttemptAt: &now,
+		}
+		if err := tx.Create(delivery).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to create webhook delivery")
+		}
+	}
+
+	return nil
+}
+
+// GetDeliveries lists a merchant's deliveries, newest first, optionally by endpoint and status
+func (s *MerchantWebhookService) GetDeliveries(merchantID uuid.UUID, endpointId *string, status *models.WebhookDeliveryStatus, pagination *models.PaginationRequest) (*models.Pagination[[]models.WebhookDelivery], error) {
+	if pagination.Limit <= 0 || pagination.Limit > 100 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	var endpointUUID *uuid.UUID
+	if endpointId != nil {
+		parsed, err := uuid.Parse(*endpointId)
+		if err != nil {
+			return nil, errors.NewBadRequestError("Invalid webhook endpoint ID")
+		}
+		endpointUUID = &parsed
+	}
+
+	scope := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("merchant_id = ?", merchantID)
+		if endpointUUID != nil {
+			db = db.Where("endpoint_id = ?", *endpointUUID)
+		}
+		if status != nil {
+			db = db.Where("status = ?", *status)
+		}
+		return db
+	}
+
+	var totalItems int64
+	if err := s.db.Model(&models.WebhookDelivery{}).Scopes(scope).Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count webhook deliveries")
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := s.db.Scopes(scope).
+		Preload("Event").
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(pagination.Limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get webhook deliveries")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return &models.Pagination[[]models.WebhookDelivery]{
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: totalPages,
+		TotalItems: int(totalItems),
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+		Items:      deliveries,
+	}, nil
+}
+
+// GetDelivery returns a delivery with its event and attempt log
+func (s *MerchantWebhookService) GetDelivery(merchantID uuid.UUID, deliveryId string) (*models.WebhookDelivery, error) {
+	deliveryUUID, err := uuid.Parse(deliveryId)
+	if err != nil {
+		return nil, errors.NewBadRequestError("Invalid webhook delivery ID")
+	}
+
+	var delivery models.WebhookDelivery
+	if err := s.db.
+		Preload("Event").
+		Preload("AttemptLog", func(db *gorm.DB) *gorm.DB { return db.Order("attempt_number ASC") }).
+		Where("id = ? AND merchant_id = ?", deliveryUUID, merchantID).
+		First(&delivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Webhook delivery not found")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get webhook delivery")
+	}
+
+	return &delivery, nil
+}
+
+// Redeliver sends a delivery again right away, whatever its status, and returns it with the
+// new attempt. A failed manual attempt does not change the retry schedule. It waits for a
+// scheduled attempt that is in flight for the same delivery.
+func (s *MerchantWebhookService) Redeliver(ctx context.Context, merchantID uuid.UUID, deliveryId string) (*models.WebhookDelivery, error) {
+	delivery, err := s.GetDelivery(merchantID, deliveryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.deliver(ctx, delivery.ID, true); err != nil {
+		return nil, err
+	}
+
+	return s.GetDelivery(merchantID, deliveryId)
+}
+
+// DeliverDue sends every pending delivery whose next attempt is due. Deliveries being sent by
+// another worker or a manual redelivery are skipped.
+func (s *MerchantWebhookService) DeliverDue(ctx context.Context) error {
+	startedAt := time.Now()
+	var lastID uuid.UUID
+	var sent, failed int
+
+	for {
+		var deliveryIDs []uuid.UUID
+		query := s.db.WithContext(ctx).
+			Model(&models.WebhookDelivery{}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryStatusPending, startedAt).
+			Order("id ASC").
+			Limit(webhookDeliveryBatchSize)
+		if lastID != uuid.Nil {
+			query = query.Where("id > ?", lastID)
+		}
+		if err := query.Pluck("id", &deliveryIDs).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to get due webhook deliveries")
+		}
+		if len(deliveryIDs) == 0 {
+			break
+		}
+
+		for _, deliveryID := range deliveryIDs {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastID = deliveryID
+			attempted, err := s.deliver(ctx, deliveryID, false)
+			if err != nil {
+				failed++
+				logrus.WithField("delivery_id", deliveryID).WithError(err).Error("Failed to deliver webhook")
+				continue
+			}
+			if attempted {
+				sent++
+			}
+		}
+	}
+
+	if sent > 0 || failed > 0 {
+		logrus.WithFields(logrus.Fields{
+			"attempts": sent,
+			"errors":   failed,
+		}).Info("Delivered webhooks")
+	}
+
+	return nil
+}
+
+// deliver makes one signed request for a delivery and records the attempt. The delivery row
+// stays locked until the attempt is stored, so the attempt number is read under the lock and a
+// scheduled and a manual attempt never overlap. A scheduled attempt skips a delivery that is
+// locked or no longer due and reports false. It returns an error only when the outcome could not
+// be stored; an endpoint failure is recorded on the delivery.
+func (s *MerchantWebhookService) deliver(ctx context.Context, deliveryID uuid.UUID, manual bool) (bool, error) {
+	attempted := false
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Preload("Event").Where("id = ?", deliveryID)
+		if manual {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+		} else {
+			query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryStatusPending, time.Now())
+		}
+
+		var delivery models.WebhookDelivery
+		result := query.Limit(1).Find(&delivery)
+		if result.Error != nil {
+			return errors.NewInternalServerError(result.Error, "Failed to lock webhook delivery")
+		}
+		if result.RowsAffected == 0 {
+			if manual {
+				return errors.NewNotFoundError("Webhook delivery not found")
+			}
+			return nil
+		}
+		attempted = true
+
+		var endpoint models.WebhookEndpoint
+		if err := tx.Unscoped().Where("id = ?", delivery.EndpointID).First(&endpoint).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to get webhook endpoint")
+		}
+
+		attempt := &models.WebhookDeliveryAttempt{
+			DeliveryID:    delivery.ID,
+			AttemptNumber: delivery.Attempts + 1,
+			URL:           endpoint.URL,
+			IsManual:      manual,
+		}
+
+		if endpoint.DeletedAt.Valid || !endpoint.IsActive {
+			attempt.Error = pkg.StringPtr("Webhook endpoint is disabled or deleted")
+		} else {
+			s.send(ctx, &endpoint, &delivery, attempt)
+		}
+
+		now := time.Now()
+		succeeded := attempt.StatusCode != nil && *attempt.StatusCode >= 200 && *attempt.StatusCode < 300
+		updates := map[string]interface{}{
+			"attempts":         attempt.AttemptNumber,
+			"last_attempt_at":  now,
+			"last_status_code": attempt.StatusCode,
+			"last_error":       attempt.Error,
+			"updated_at":       now,
+		}
+
+		switch {
+		case succeeded:
+			updates["status"] = models.WebhookDeliveryStatusSucceeded
+			updates["delivered_at"] = now
+			updates["next_attempt_at"] = nil
+		case manual && delivery.Status != models.WebhookDeliveryStatusPending:
+			// Keep a finished delivery's status when a manual retry fails
+		case endpoint.DeletedAt.Valid || !endpoint.IsActive || attempt.AttemptNumber >= maxWebhookAttempts:
+			updates["status"] = models.WebhookDeliveryStatusFailed
+			updates["next_attempt_at"] = nil
+		case !manual:
+			updates["next_attempt_at"] = now.Add(webhookRetryDelay(attempt.AttemptNumber))
+		}
+
+		if err := tx.Create(attempt).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to record webhook attempt")
+		}
+		if err := tx.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Updates(updates).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update webhook delivery")
+		}
+		return nil
+	})
+
+	return attempted, err
+}
+
+// send POSTs the event to the endpoint and fills in the attempt result
+func (s *MerchantWebhookService) send(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery, attempt *models.WebhookDeliveryAttempt) {
+	if delivery.Event == nil {
+		attempt.Error = pkg.StringPtr("Webhook event not found")
+		return
+	}
+
+	body, err := json.Marshal(models.WebhookPayload{
+		ID:        delivery.Event.ID,
+		Type:      delivery.Event.Type,
+		CreatedAt: delivery.Event.CreatedAt,
+		Data:      delivery.Event.Data,
+	})
+	if err != nil {
+		attempt.Error = pkg.StringPtr("Failed to encode webhook payload: " + err.Error())
+		return
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		attempt.Error = pkg.StringPtr("Invalid webhook request: " + err.Error())
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "GSALT-Webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, string(delivery.Event.Type))
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID.String())
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(endpoint.Secret, timestamp, body))
+
+	startedAt := time.Now()
+	resp, err := s.httpClient.Do(req)
+	attempt.DurationMs = time.Since(startedAt).Milliseconds()
+	if err != nil {
+		attempt.Error = pkg.StringPtr(err.Error())
+		return
+	}
+	defer resp.Body.Close()
+
+	statusCode := resp.StatusCode
+	attempt.StatusCode = &statusCode
+
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBodyLength))
+	if len(responseBody) > 0 {
+		responseText := string(responseBody)
+		attempt.ResponseBody = &responseText
+	}
+	if statusCode < 200 || statusCode >= 300 {
+		attempt.Error = pkg.StringPtr(fmt.Sprintf("Endpoint responded with status %d", statusCode))
+	}
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of "timestamp.body" keyed with secret
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookRetryDelay is the exponential backoff after the given number of failed attempts
+func webhookRetryDelay(attempts int) time.Duration {
+	delay := webhookBaseRetryDelay
+	for i := 1; i < attempts && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > webhookMaxRetryDelay {
+		delay = webhookMaxRetryDelay
+	}
+	return delay
+}
+
+// generateWebhookSecret returns a new "whsec_" prefixed signing secret
+func generateWebhookSecret() (string, error) {
+	secret, err := generateSigningSecret()
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + secret, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+)
+
+// PaymentProvider is implemented by every payment service provider (PSP) integration.
+// Amounts are in the provider's currency (IDR for Flip).
+type PaymentProvider interface {
+	// Code returns the provider code matching PaymentMethod.ProviderCode
+	Code() string
+
+	// CreateCharge asks the provider to collect money from a payer
+	CreateCharge(ctx context.Context, req models.ProviderChargeRequest) (*models.ProviderChargeResponse, error)
+
+	// GetChargeStatus retrieves the current status of a charge
+	GetChargeStatus(ctx context.Context, providerPaymentID string) (*models.ProviderChargeStatus, error)
+
+	// CancelCharge stops a charge from accepting payments
+	CancelCharge(ctx context.Context, providerPaymentID string) error
+
+	// ParseWebhook verifies an inbound callback and normalizes it into an event
+	ParseWebhook(req models.ProviderWebhookRequest) (*models.ProviderWebhookEvent, error)
+
+	// CreatePayout sends money to a bank account
+	CreatePayout(ctx context.Context, req models.ProviderPayoutRequest) (*models.ProviderPayoutResponse, error)
+
+	// GetPayoutStatus retrieves the current status of a payout
+	GetPayoutStatus(ctx context.Context, providerPayoutID string) (*models.ProviderPayoutResponse, error)
+
+	// FindPayout looks up a payout by the idempotency key it was created with. It returns nil
+	// without an error when the provider has no payout for the key.
+	FindPayout(ctx context.Context, idempotencyKey string) (*models.ProviderPayoutResponse, error)
+
+	// ParsePayoutWebhook verifies an inbound payout callback and normalizes it into an event
+	ParsePayoutWebhook(req models.ProviderWebhookRequest) (*models.ProviderPayoutEvent, error)
+
+	// InquireBankAccount validates a bank account before a payout
+	InquireBankAccount(ctx context.Context, bankCode, accountNumber string) (*models.BankAccountInquiryResponse, error)
+}
+
+// PaymentProviderRegistry resolves payment providers by provider code
+type PaymentProviderRegistry struct {
+	providers map[string]PaymentProvider
+}
+
+// NewPaymentProviderRegistry creates a registry with all built-in providers registered
+func NewPaymentProviderRegistry(flipService *FlipService) *PaymentProviderRegistry {
+	registry := &PaymentProviderRegistry{
+		providers: make(map[string]PaymentProvider),
+	}
+	registry.Register(flipService)
+	return registry
+}
+
+// Register adds or replaces a provider
+func (r *PaymentProviderRegistry) Register(provider PaymentProvider) {
+	r.providers[strings.ToUpper(provider.Code())] = provider
+}
+
+// Get returns the provider for the given code
+func (r *PaymentProviderRegistry) Get(code string) (PaymentProvider, error) {
+	provider, ok := r.providers[strings.ToUpper(code)]
+	if !ok {
+		return nil, errors.NewBadRequestError("Payment provider '" + code + "' is not supported [" + ErrCodeInvalidPaymentMethod + "]")
+	}
+	return provider, nil
+}
+
+// Codes returns the registered provider codes in sorted order
+func (r *PaymentProviderRegistry) Codes() []string {
+	codes := make([]string, 0, len(r.providers))
+	for code := range r.providers {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -77,13 +77,18 @@ func (s *PaymentService) GetPaymentDetailsByTransactionID(ctx context.Context, t
 
 // UpdatePaymentStatus updates the payment status and details
 func (s *PaymentService) UpdatePaymentStatus(ctx context.Context, transactionID uuid.UUID, req *models.PaymentStatusUpdateRequest) error {
+	return s.UpdatePaymentStatusTx(s.db.WithContext(ctx), transactionID, req)
+}
+
+// UpdatePaymentStatusTx updates the payment status and details using the given database transaction
+func (s *PaymentService) UpdatePaymentStatusTx(tx *gorm.DB, transactionID uuid.UUID, req *models.PaymentStatusUpdateRequest) error {
 	if err := s.validator.Validate(req); err != nil {
 		return err
 	}
 
 	// Get existing payment details
 	var details models.PaymentDetails
-	err := s.db.WithContext(ctx).
+	err := tx.
 		Where("transaction_id = ?", transactionID).
 		First(&details).Error
 
@@ -105,6 +110,9 @@ func (s *PaymentService) UpdatePaymentStatus(ctx context.Context, transactionID
 	if req.PaymentTime != nil {
 		updates["payment_time"] = *req.PaymentTime
 	}
+	if req.ReceiptURL != nil {
+		updates["receipt_url"] = *req.ReceiptURL
+	}
 
 	// Add status history
 	var statusHistory []map[string]interface{}
@@ -126,7 +134,7 @@ func (s *PaymentService) UpdatePaymentStatus(ctx context.Context, transactionID
 	}
 	updates["status_history"] = statusHistoryJSON
 
-	err = s.db.WithContext(ctx).
+	err = tx.
 		Model(&details).
 		Updates(updates).Error
 
@@ -0,0 +1,409 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reversibleTransactionTypes lists the completed transactions whose journal entry may be reversed.
+// Withdrawals have left the platform and voucher redemptions also change voucher usage.
+var reversibleTransactionTypes = map[models.TransactionType]bool{
+	models.TransactionTypeTopup:       true,
+	models.TransactionTypeTransferOut: true,
+	models.TransactionTypeTransferIn:  true,
+	models.TransactionTypePayment:     true,
+	models.TransactionTypeGiftIn:      true,
+	models.TransactionTypeGiftOut:     true,
+}
+
+// RefundService returns money for completed transactions. A refund returns all or part of a
+// TOPUP or PAYMENT amount; a reversal undoes a transaction's journal entry entirely. Both create
+// linked REFUND/REVERSAL transactions through RelatedTransactionID.
+type RefundService struct {
+	db                 *gorm.DB
+	validator          *infrastructures.Validator
+	transactionService *TransactionService
+	ledgerService      *LedgerService
+	auditService       *AuditService
+	webhookService     *MerchantWebhookService
+}
+
+func NewRefundService(
+	db *gorm.DB,
+	validator *infrastructures.Validator,
+	transactionService *TransactionService,
+	ledgerService *LedgerService,
+	auditService *AuditService,
+	webhookService *MerchantWebhookService,
+) *RefundService {
+	return &RefundService{
+		db:                 db,
+		validator:          validator,
+		transactionService: transactionService,
+		ledgerService:      ledgerService,
+		auditService:       auditService,
+		webhookService:     webhookService,
+	}
+}
+
+// RefundTransaction refunds part of a completed TOPUP or PAYMENT, or the remaining refundable
+// amount when req.AmountGsalt is empty. Refunds never exceed the original amount in total.
+// A payment refund moves balance from the merchant back to the payer; a topup refund debits the
+// wallet and stays PENDING until the money has been returned to the payer manually and recorded
+// with RecordRefundPayout. Fees are not refunded.
+func (s *RefundService) RefundTransaction(transactionId string, req *models.RefundRequest, refundedBy *uuid.UUID) (*models.Transaction, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	transactionUUID, err := s.transactionService.parseUUID(transactionId, "transaction ID")
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert GSALT amount to units (1 GSALT = 100 units)
+	var amountGsaltUnits *int64
+	if req.AmountGsalt != nil {
+		units, err := gsaltToUnits(*req.AmountGsalt, "refund amount")
+		if err != nil {
+			return nil, err
+		}
+		amountGsaltUnits = &units
+	}
+	reason := req.Reason
+
+	var refund *models.Transaction
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		original, err := s.lockTransaction(tx, transactionUUID)
+		if err != nil {
+			return err
+		}
+
+		if original.Type != models.TransactionTypeTopup && original.Type != models.TransactionTypePayment {
+			return errors.NewBadRequestError("Only TOPUP and PAYMENT transactions can be refunded")
+		}
+
+		if original.Status != models.TransactionStatusCompleted && original.Status != models.TransactionStatusPartiallyRefunded {
+			return errors.NewBadRequestError("Only completed transactions can be refunded [" + ErrCodeInvalidStatusTransition + "]")
+		}
+
+		if original.Type == models.TransactionTypePayment && original.DestinationAccountID == nil {
+			return errors.NewBadRequestError("Payment has no merchant account to refund from")
+		}
+
+		refunded, err := s.refundedAmount(tx, original.ID)
+		if err != nil {
+			return err
+		}
+
+		remaining := original.AmountGsaltUnits - refunded
+		amount := remaining
+		if amountGsaltUnits != nil {
+			amount = *amountGsaltUnits
+		}
+		if amount <= 0 || amount > remaining {
+			return errors.NewBadRequestError(fmt.Sprintf("Refund amount exceeds the refundable amount of %d units [%s]", remaining, ErrCodeRefundExceedsAmount))
+		}
+
+		newStatus := models.TransactionStatusPartiallyRefunded
+		if amount == remaining {
+			newStatus = models.TransactionStatusRefunded
+		}
+		if err := s.transactionService.validateStatusTransition(original.Status, newStatus); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		description := fmt.Sprintf("Refund of %s: %s", original.ID, reason)
+		refund = s.transactionService.createBaseTransaction(original.AccountID, models.TransactionTypeRefund, amount, models.TransactionStatusCompleted, &description)
+		refund.RelatedTransactionID = &original.ID
+		refund.PaymentMethod = original.PaymentMethod
+		refund.PaymentStatus = models.PaymentStatusCompleted
+		refund.CompletedAt = &now
+		if original.Type == models.TransactionTypePayment {
+			refund.SourceAccountID = original.DestinationAccountID
+			refund.DestinationAccountID = &original.AccountID
+		}
+
+		if err := tx.Create(refund).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to create refund transaction")
+		}
+
+		var entry *models.JournalEntry
+		switch original.Type {
+		case models.TransactionTypePayment:
+			entry, err = s.ledgerService.RecordWalletTransfer(tx, *original.DestinationAccountID, original.AccountID, amount, refund.ID.String(), &description)
+		case models.TransactionTypeTopup:
+			entry, err = s.refundTopup(tx, original, refund, amount)
+		}
+		if err != nil {
+			return err
+		}
+
+		refund.JournalEntryID = &entry.ID
+		if err := tx.Save(refund).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update refund transaction")
+		}
+
+		// The status history of the original is written by the transactions trigger
+		if err := tx.Model(original).Updates(map[string]interface{}{
+			"status":     newStatus,
+			"updated_at": now,
+		}).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update refunded transaction")
+		}
+
+		if err := s.auditService.LogTransactionStatusChangeTx(tx, refund.ID, nil, models.TransactionStatusCompleted, reason, map[string]interface{}{
+			"related_transaction_id": original.ID,
+			"amount_gsalt_units":     amount,
+			"refunded_gsalt_units":   refunded + amount,
+		}, refundedBy); err != nil {
+			return err
+		}
+
+		// Payment refunds are reported to the merchant, topup refunds to the account owner
+		recipient := original.AccountID
+		if original.Type == models.TransactionTypePayment {
+			recipient = *original.DestinationAccountID
+		}
+		return s.webhookService.Enqueue(tx, recipient, models.WebhookEventRefundCompleted, map[string]interface{}{
+			"refund":                 refund,
+			"transaction_id":         original.ID,
+			"transaction_status":     newStatus,
+			"refunded_gsalt_units":   refunded + amount,
+			"refundable_gsalt_units": remaining - amount,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// refundTopup debits the wallet for a topup refund and leaves its payment status PENDING. No
+// provider can return a collected charge, so the payer is paid back manually and the payout is
+// recorded with RecordRefundPayout.
+func (s *RefundService) refundTopup(tx *gorm.DB, original, refund *models.Transaction, amount int64) (*models.JournalEntry, error) {
+	providerCode := s.transactionService.transactionProviderCode(tx, original.ID)
+
+	entry, err := s.ledgerService.RecordTopupRefund(tx, original.AccountID, providerCode, amount, refund.ID.String(), refund.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	refund.PaymentStatus = models.PaymentStatusPending
+	refund.PaymentStatusDescription = pkg.StringPtr(fmt.Sprintf("%s does not support refunds; return the amount to the payer manually and record the payout", providerCode))
+
+	return entry, nil
+}
+
+// RecordRefundPayout marks a PENDING topup refund as paid after support returned the money to
+// the payer outside the payment provider
+func (s *RefundService) RecordRefundPayout(refundId string, req *models.RefundPayoutRequest, paidOutBy *uuid.UUID) (*models.Transaction, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	refundUUID, err := s.transactionService.parseUUID(refundId, "transaction ID")
+	if err != nil {
+		return nil, err
+	}
+
+	var refund *models.Transaction
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		refund, err = s.lockTransaction(tx, refundUUID)
+		if err != nil {
+			return err
+		}
+
+		if refund.Type != models.TransactionTypeRefund {
+			return errors.NewBadRequestError("Only REFUND transactions can be paid out")
+		}
+		if refund.PaymentStatus != models.PaymentStatusPending {
+			return errors.NewBadRequestError(fmt.Sprintf("Refund payout is already %s [%s]", refund.PaymentStatus, ErrCodeInvalidStatusTransition))
+		}
+
+		oldRefund := *refund
+		now := time.Now()
+		description := "Paid out manually, reference " + req.Reference
+		if req.Note != nil {
+			description += ": " + *req.Note
+		}
+		refund.PaymentStatus = models.PaymentStatusCompleted
+		refund.PaymentStatusDescription = &description
+		refund.PaymentCompletedAt = &now
+
+		if err := tx.Model(refund).Updates(map[string]interface{}{
+			"payment_status":             refund.PaymentStatus,
+			"payment_status_description": description,
+			"payment_completed_at":       now,
+			"updated_at":                 now,
+		}).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update refund transaction")
+		}
+
+		return s.auditService.LogAuditTx(tx, "transactions", refund.ID, models.AuditActionUpdate, oldRefund, refund, paidOutBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// ReverseTransaction undoes a completed transaction by reversing its journal entry, including fees.
+// Both sides of a transfer share one journal entry and are reversed together; every reversed
+// transaction gets its own REVERSAL transaction. No provider is called.
+func (s *RefundService) ReverseTransaction(transactionId string, req *models.ReversalRequest, reversedBy *uuid.UUID) ([]models.Transaction, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	transactionUUID, err := s.transactionService.parseUUID(transactionId, "transaction ID")
+	if err != nil {
+		return nil, err
+	}
+	reason := req.Reason
+
+	var reversals []models.Transaction
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		original, err := s.lockTransaction(tx, transactionUUID)
+		if err != nil {
+			return err
+		}
+
+		if !reversibleTransactionTypes[original.Type] {
+			return errors.NewBadRequestError(fmt.Sprintf("%s transactions cannot be reversed", original.Type))
+		}
+
+		if err := s.transactionService.validateStatusTransition(original.Status, models.TransactionStatusReversed); err != nil {
+			return err
+		}
+
+		if original.JournalEntryID == nil {
+			return errors.NewBadRequestError("Transaction has no journal entry to reverse")
+		}
+
+		var linked []models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("journal_entry_id = ? AND id <> ?", *original.JournalEntryID, original.ID).
+			Find(&linked).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to get linked transactions")
+		}
+		for _, transaction := range linked {
+			if transaction.Status != models.TransactionStatusCompleted {
+				return errors.NewBadRequestError(fmt.Sprintf("Linked transaction %s is %s and cannot be reversed [%s]", transaction.ID, transaction.Status, ErrCodeInvalidStatusTransition))
+			}
+		}
+
+		description := fmt.Sprintf("Reversal of %s: %s", original.ID, reason)
+		entry, err := s.ledgerService.ReverseJournalEntry(tx, *original.JournalEntryID, original.ID.String()+":reversal", &description)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, transaction := range append([]models.Transaction{*original}, linked...) {
+			reversal := s.transactionService.createBaseTransaction(transaction.AccountID, models.TransactionTypeReversal, transaction.AmountGsaltUnits, models.TransactionStatusCompleted, &description)
+			reversal.FeeGsaltUnits = transaction.FeeGsaltUnits
+			reversal.TotalAmountGsaltUnits = transaction.TotalAmountGsaltUnits
+			reversal.RelatedTransactionID = &transaction.ID
+			reversal.JournalEntryID = &entry.ID
+			reversal.PaymentStatus = models.PaymentStatusCompleted
+			reversal.CompletedAt = &now
+
+			if err := tx.Create(reversal).Error; err != nil {
+				return errors.NewInternalServerError(err, "Failed to create reversal transaction")
+			}
+
+			if err := tx.Model(&models.Transaction{}).Where("id = ?", transaction.ID).Updates(map[string]interface{}{
+				"status":     models.TransactionStatusReversed,
+				"updated_at": now,
+			}).Error; err != nil {
+				return errors.NewInternalServerError(err, "Failed to update reversed transaction")
+			}
+
+			if err := s.auditService.LogTransactionStatusChangeTx(tx, reversal.ID, nil, models.TransactionStatusCompleted, reason, map[string]interface{}{
+				"related_transaction_id": transaction.ID,
+				"journal_entry_id":       entry.ID,
+			}, reversedBy); err != nil {
+				return err
+			}
+
+			reversals = append(reversals, *reversal)
+
+			if transaction.Type == models.TransactionTypePayment && transaction.DestinationAccountID != nil {
+				if err := s.webhookService.Enqueue(tx, *transaction.DestinationAccountID, models.WebhookEventPaymentReversed, map[string]interface{}{
+					"reversal":       reversal,
+					"transaction_id": transaction.ID,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reversals, nil
+}
+
+// GetRefunds lists the refunds and reversals linked to a transaction, oldest first
+func (s *RefundService) GetRefunds(transactionId string) ([]models.Transaction, error) {
+	transactionUUID, err := s.transactionService.parseUUID(transactionId, "transaction ID")
+	if err != nil {
+		return nil, err
+	}
+
+	var refunds []models.Transaction
+	if err := s.db.
+		Where("related_transaction_id = ? AND type IN ?", transactionUUID, []models.TransactionType{models.TransactionTypeRefund, models.TransactionTypeReversal}).
+		Order("created_at ASC").
+		Find(&refunds).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get refunds")
+	}
+
+	return refunds, nil
+}
+
+// lockTransaction loads a transaction with a row lock for the duration of tx
+func (s *RefundService) lockTransaction(tx *gorm.DB, transactionID uuid.UUID) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transactionID).First(&transaction).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Transaction not found [" + ErrCodeTransactionNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get transaction")
+	}
+	return &transaction, nil
+}
+
+// refundedAmount sums the completed refunds of a transaction
+func (s *RefundService) refundedAmount(tx *gorm.DB, transactionID uuid.UUID) (int64, error) {
+	var refunded int64
+	if err := tx.Model(&models.Transaction{}).
+		Where("related_transaction_id = ? AND type = ? AND status = ?", transactionID, models.TransactionTypeRefund, models.TransactionStatusCompleted).
+		Select("COALESCE(SUM(amount_gsalt_units), 0)").
+		Scan(&refunded).Error; err != nil {
+		return 0, errors.NewInternalServerError(err, "Failed to sum refunds")
+	}
+	return refunded, nil
+}
@@ -0,0 +1,482 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/app/pkg"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// ScheduledJob is a unit of background work run by the SchedulerService
+type ScheduledJob struct {
+	Name        string
+	Description string
+	Spec        string        // See pkg.ParseCronSpec
+	Timeout     time.Duration // Also bounds how long the leader lock is held
+	Run         func(ctx context.Context) error
+}
+
+type scheduledJobEntry struct {
+	job       ScheduledJob
+	schedule  *pkg.CronSchedule
+	nextRunAt *time.Time
+}
+
+// releaseLockScript deletes the lock only if this instance still owns it
+var releaseLockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// SchedulerService runs registered jobs on their schedules. Each scheduled activation is
+// claimed in Redis under the job name and activation time, so with several replicas each
+// occurrence is executed by one of them only, and every run holds a per-job lock so runs of
+// the same job never overlap.
+type SchedulerService struct {
+	db         *gorm.DB
+	redis      *redis.Client
+	keyPrefix  string
+	instanceID string
+
+	mu      sync.Mutex
+	jobs    []*scheduledJobEntry
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+func NewSchedulerService(db *gorm.DB, redis *redis.Client, keyPrefix string, transactionService *TransactionService, voucherService *VoucherService, idempotencyService *IdempotencyService, checkoutService *CheckoutService, webhookService *MerchantWebhookService, closureService *AccountClosureService) *SchedulerService {
+	hostname, _ := os.Hostname()
+
+	s := &SchedulerService{
+		db:         db,
+		redis:      redis,
+		keyPrefix:  keyPrefix,
+		instanceID: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+	}
+
+	builtinJobs := []ScheduledJob{
+		{
+			Name:        "expire_pending_transactions",
+			Description: "Expires pending transactions whose payment window has passed",
+			Spec:        "*/5 * * * *",
+			Timeout:     5 * time.Minute,
+			Run:         transactionService.ExpirePendingTransactions,
+		},
+		{
+			Name:        "update_expired_vouchers",
+			Description: "Marks active vouchers past valid_until as expired",
+			Spec:        "*/10 * * * *",
+			Timeout:     5 * time.Minute,
+			Run: func(ctx context.Context) error {
+				return voucherService.UpdateExpiredVouchers()
+			},
+		},
+		{
+			Name:        "refresh_materialized_views",
+			Description: "Refreshes reporting materialized views via refresh_all_materialized_views()",
+			Spec:        "0 * * * *",
+			Timeout:     15 * time.Minute,
+			Run: func(ctx context.Context) error {
+				return db.WithContext(ctx).Exec("SELECT refresh_all_materialized_views()").Error
+			},
+		},
+		{
+			Name:        "purge_idempotency_keys",
+			Description: "Deletes stored Idempotency-Key responses past their 24 hour retention",
+			Spec:        "30 * * * *",
+			Timeout:     5 * time.Minute,
+			Run:         idempotencyService.PurgeExpired,
+		},
+		{
+			Name:        "expire_checkout_sessions",
+			Description: "Marks open checkout sessions past expires_at as expired",
+			Spec:        "*/5 * * * *",
+			Timeout:     5 * time.Minute,
+			Run:         checkoutService.ExpireSessions,
+		},
+		{
+			Name:        "deliver_webhooks",
+			Description: "Sends due merchant webhook deliveries and schedules retries with exponential backoff",
+			Spec:        "* * * * *",
+			Timeout:     5 * time.Minute,
+			Run:         webhookService.DeliverDue,
+		},
+		{
+			Name:        "finalize_account_closures",
+			Description: "Anonymises and closes accounts whose closure cooling-off period has passed",
+			Spec:        "15 * * * *",
+			Timeout:     15 * time.Minute,
+			Run:         closureService.FinalizeDueClosures,
+		},
+		{
+			Name:        "reconcile_payouts",
+			Description: "Records or fails withdrawals whose payout was requested but never recorded",
+			Spec:        "*/10 * * * *",
+			Timeout:     5 * time.Minute,
+			Run:         transactionService.ReconcilePayouts,
+		},
+	}
+
+	for _, job := range builtinJobs {
+		if err := s.Register(job); err != nil {
+			logrus.Fatalf("failed to register scheduled job: %v", err)
+		}
+	}
+
+	return s
+}
+
+// Register adds a job. Jobs must be registered before Start.
+func (s *SchedulerService) Register(job ScheduledJob) error {
+	schedule, err := pkg.ParseCronSpec(job.Spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	if job.Timeout <= 0 {
+		job.Timeout = 5 * time.Minute
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("job %s: scheduler already started", job.Name)
+	}
+	for _, entry := range s.jobs {
+		if entry.job.Name == job.Name {
+			return fmt.Errorf("job %s is already registered", job.Name)
+		}
+	}
+
+	s.jobs = append(s.jobs, &scheduledJobEntry{job: job, schedule: schedule})
+	return nil
+}
+
+// Start launches one scheduling loop per job. It returns immediately.
+func (s *SchedulerService) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, entry := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, entry)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"instance_id": s.instanceID,
+		"jobs":        len(s.jobs),
+	}).Info("Scheduler started")
+}
+
+// Stop stops scheduling and waits for running jobs to finish, including triggered runs when
+// the scheduler was never started
+func (s *SchedulerService) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+	logrus.Info("Scheduler stopped")
+}
+
+// loop waits for each activation of a job and runs it
+func (s *SchedulerService) loop(ctx context.Context, entry *scheduledJobEntry) {
+	defer s.wg.Done()
+
+	for {
+		next := entry.schedule.Next(time.Now())
+		if next.IsZero() {
+			logrus.WithField("job", entry.job.Name).Warn("Scheduled job has no next activation")
+			return
+		}
+
+		s.mu.Lock()
+		entry.nextRunAt = &next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if _, err := s.execute(ctx, entry, models.ScheduledJobTriggerSchedule, nil, &next); err != nil {
+			if appErr, ok := err.(*errors.AppError); ok && appErr.StatusCode == http.StatusConflict {
+				logrus.WithField("job", entry.job.Name).Debug("Scheduled job is running on another instance")
+				continue
+			}
+			logrus.WithField("job", entry.job.Name).WithError(err).Error("Scheduled job failed")
+		}
+	}
+}
+
+// execute runs a job under its leader lock and records the run. Scheduled runs pass their
+// activation time, which must not have been claimed by another instance yet.
+func (s *SchedulerService) execute(ctx context.Context, entry *scheduledJobEntry, trigger models.ScheduledJobTrigger, triggeredBy *uuid.UUID, activation *time.Time) (*models.ScheduledJobRun, error) {
+	run, release, err := s.begin(ctx, entry, trigger, triggeredBy, activation)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	return s.finish(ctx, entry, run)
+}
+
+// begin claims the activation, takes the job lock and records the run as RUNNING. The
+// returned function releases the job lock.
+func (s *SchedulerService) begin(ctx context.Context, entry *scheduledJobEntry, trigger models.ScheduledJobTrigger, triggeredBy *uuid.UUID, activation *time.Time) (*models.ScheduledJobRun, func(), error) {
+	if activation != nil {
+		// The claim is never released: it expires after the following activation, so a replica
+		// whose timer fires late, or after a short run has finished, cannot run the same
+		// occurrence again
+		ttl := entry.job.Timeout + time.Minute
+		if following := entry.schedule.Next(*activation); !following.IsZero() && time.Until(following)+time.Minute > ttl {
+			ttl = time.Until(following) + time.Minute
+		}
+		claimed, err := s.redis.SetNX(ctx, s.activationKey(entry.job.Name, *activation), s.instanceID, ttl).Result()
+		if err != nil {
+			return nil, nil, errors.NewInternalServerError(err, "Failed to claim job activation")
+		}
+		if !claimed {
+			return nil, nil, errors.NewAppError(http.StatusConflict, fmt.Sprintf("Job %s already ran for %s", entry.job.Name, activation.Format(time.RFC3339)))
+		}
+	}
+
+	lockKey := s.lockKey(entry.job.Name)
+
+	acquired, err := s.redis.SetNX(ctx, lockKey, s.instanceID, entry.job.Timeout+time.Minute).Result()
+	if err != nil {
+		return nil, nil, errors.NewInternalServerError(err, "Failed to acquire job lock")
+	}
+	if !acquired {
+		return nil, nil, errors.NewAppError(http.StatusConflict, fmt.Sprintf("Job %s is already running", entry.job.Name))
+	}
+	release := func() {
+		if err := releaseLockScript.Run(context.Background(), s.redis, []string{lockKey}, s.instanceID).Err(); err != nil {
+			logrus.WithField("job", entry.job.Name).WithError(err).Warn("Failed to release job lock")
+		}
+	}
+
+	run := &models.ScheduledJobRun{
+		ID:          uuid.New(),
+		JobName:     entry.job.Name,
+		Trigger:     trigger,
+		TriggeredBy: triggeredBy,
+		InstanceID:  s.instanceID,
+		Status:      models.ScheduledJobRunStatusRunning,
+		StartedAt:   time.Now(),
+	}
+	if err := s.db.Create(run).Error; err != nil {
+		release()
+		return nil, nil, errors.NewInternalServerError(err, "Failed to record job run")
+	}
+
+	return run, release, nil
+}
+
+// finish runs the job of a started run and records its outcome
+func (s *SchedulerService) finish(ctx context.Context, entry *scheduledJobEntry, run *models.ScheduledJobRun) (*models.ScheduledJobRun, error) {
+	runCtx, cancel := context.WithTimeout(ctx, entry.job.Timeout)
+	jobErr := s.runJob(runCtx, entry.job)
+	cancel()
+
+	finishedAt := time.Now()
+	durationMs := finishedAt.Sub(run.StartedAt).Milliseconds()
+	run.FinishedAt = &finishedAt
+	run.DurationMs = &durationMs
+	run.Status = models.ScheduledJobRunStatusSucceeded
+	if jobErr != nil {
+		run.Status = models.ScheduledJobRunStatusFailed
+		run.ErrorMessage = pkg.StringPtr(jobErr.Error())
+	}
+
+	if err := s.db.Save(run).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to update job run")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"job":         run.JobName,
+		"trigger":     run.Trigger,
+		"status":      run.Status,
+		"duration_ms": durationMs,
+	}).Info("Scheduled job finished")
+
+	return run, nil
+}
+
+// runJob runs the job function, converting panics into errors
+func (s *SchedulerService) runJob(ctx context.Context, job ScheduledJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+// ListJobs returns every registered job with its next and last run
+func (s *SchedulerService) ListJobs(ctx context.Context) ([]models.ScheduledJobInfo, error) {
+	s.mu.Lock()
+	entries := make([]*scheduledJobEntry, len(s.jobs))
+	copy(entries, s.jobs)
+	s.mu.Unlock()
+
+	jobs := make([]models.ScheduledJobInfo, 0, len(entries))
+	for _, entry := range entries {
+		info := models.ScheduledJobInfo{
+			Name:        entry.job.Name,
+			Description: entry.job.Description,
+			Spec:        entry.job.Spec,
+			Timeout:     entry.job.Timeout.String(),
+		}
+
+		s.mu.Lock()
+		if entry.nextRunAt != nil {
+			next := *entry.nextRunAt
+			info.NextRunAt = &next
+		}
+		s.mu.Unlock()
+		if info.NextRunAt == nil {
+			next := entry.schedule.Next(time.Now())
+			info.NextRunAt = &next
+		}
+
+		running, err := s.redis.Exists(ctx, s.lockKey(entry.job.Name)).Result()
+		if err != nil {
+			return nil, errors.NewInternalServerError(err, "Failed to get job lock")
+		}
+		info.Running = running > 0
+
+		var lastRun models.ScheduledJobRun
+		err = s.db.WithContext(ctx).Where("job_name = ?", entry.job.Name).Order("started_at DESC").First(&lastRun).Error
+		if err == nil {
+			info.LastRun = &lastRun
+		} else if err != gorm.ErrRecordNotFound {
+			return nil, errors.NewInternalServerError(err, "Failed to get last job run")
+		}
+
+		jobs = append(jobs, info)
+	}
+
+	return jobs, nil
+}
+
+// TriggerJob starts a job in the background and returns its RUNNING run; poll the run history
+// for the outcome
+func (s *SchedulerService) TriggerJob(name string, triggeredBy *uuid.UUID) (*models.ScheduledJobRun, error) {
+	entry, err := s.getJob(name)
+	if err != nil {
+		return nil, err
+	}
+
+	run, release, err := s.begin(context.Background(), entry, models.ScheduledJobTriggerManual, triggeredBy, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *run
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer release()
+
+		if _, err := s.finish(context.Background(), entry, run); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"job":    entry.job.Name,
+				"run_id": run.ID,
+			}).WithError(err).Error("Triggered job failed")
+		}
+	}()
+
+	return &started, nil
+}
+
+// GetJobRuns retrieves the run history of a job with pagination
+func (s *SchedulerService) GetJobRuns(name string, pagination *models.PaginationRequest) (*models.Pagination[[]models.ScheduledJobRun], error) {
+	if _, err := s.getJob(name); err != nil {
+		return nil, err
+	}
+
+	if pagination.Limit <= 0 {
+		pagination.Limit = 10
+	}
+	if pagination.Page <= 0 {
+		pagination.Page = 1
+	}
+
+	offset := (pagination.Page - 1) * pagination.Limit
+
+	var totalItems int64
+	if err := s.db.Model(&models.ScheduledJobRun{}).Where("job_name = ?", name).Count(&totalItems).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to count job runs")
+	}
+
+	var runs []models.ScheduledJobRun
+	query := s.db.Where("job_name = ?", name).Order("started_at DESC").Limit(pagination.Limit)
+	if offset > 0 {
+		query = query.Offset(offset)
+	}
+
+	if err := query.Find(&runs).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get job runs")
+	}
+
+	totalPages := int((totalItems + int64(pagination.Limit) - 1) / int64(pagination.Limit))
+
+	return &models.Pagination[[]models.ScheduledJobRun]{
+		Page:       pagination.Page,
+		Limit:      pagination.Limit,
+		TotalPages: totalPages,
+		TotalItems: int(totalItems),
+		HasNext:    pagination.Page < totalPages,
+		HasPrev:    pagination.Page > 1,
+		Items:      runs,
+	}, nil
+}
+
+func (s *SchedulerService) getJob(name string) (*scheduledJobEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range s.jobs {
+		if entry.job.Name == name {
+			return entry, nil
+		}
+	}
+
+	return nil, errors.NewNotFoundError("Job not found")
+}
+
+func (s *SchedulerService) lockKey(name string) string {
+	return fmt.Sprintf("%s:scheduler:lock:%s", s.keyPrefix, name)
+}
+
+func (s *SchedulerService) activationKey(name string, activation time.Time) string {
+	return fmt.Sprintf("%s:scheduler:activation:%s:%d", s.keyPrefix, name, activation.Unix())
+}
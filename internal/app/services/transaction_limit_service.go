@@ -0,0 +1,373 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// transactionLimitsReloadInterval is how long a loaded limit configuration is used before it is
+// read again, so changes made on another replica apply within this interval
+const transactionLimitsReloadInterval = 30 * time.Second
+
+// fallbackTransactionLimits apply when system_configurations has no default_limits entry
+var fallbackTransactionLimits = models.TransactionLimits{
+	MinTopupAmount:       1000,      // 10 GSALT (10,000 IDR)
+	MaxTopupAmount:       5000000,   // 50,000 GSALT (50,000,000 IDR)
+	MinTransferAmount:    100,       // 1 GSALT (1,000 IDR)
+	MaxTransferAmount:    2500000,   // 25,000 GSALT (25,000,000 IDR)
+	MinPaymentAmount:     100,       // 1 GSALT (1,000 IDR)
+	MaxPaymentAmount:     1000000,   // 10,000 GSALT (10,000,000 IDR)
+	DailyTransferLimit:   10000000,  // 100,000 GSALT (100,000,000 IDR)
+	MonthlyTransferLimit: 100000000, // 1,000,000 GSALT (1,000,000,000 IDR)
+	DailyPaymentLimit:    5000000,   // 50,000 GSALT (50,000,000 IDR)
+	MonthlyPaymentLimit:  50000000,  // 500,000 GSALT (500,000,000 IDR)
+}
+
+// TransactionLimitService serves the transaction limits stored in system_configurations. The
+// configuration is cached in memory and reloaded every transactionLimitsReloadInterval.
+type TransactionLimitService struct {
+	db           *gorm.DB
+	validator    *infrastructures.Validator
+	auditService *AuditService
+
+	mu       sync.Mutex
+	config   *models.TransactionLimitConfig
+	loadedAt time.Time
+}
+
+func NewTransactionLimitService(db *gorm.DB, validator *infrastructures.Validator, auditService *AuditService) *TransactionLimitService {
+	return &TransactionLimitService{
+		db:           db,
+		validator:    validator,
+		auditService: auditService,
+	}
+}
+
+// GetConfig returns the current limit configuration
+func (s *TransactionLimitService) GetConfig(ctx context.Context) (*models.TransactionLimitConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.config != nil && time.Since(s.loadedAt) < transactionLimitsReloadInterval {
+		return s.config, nil
+	}
+
+	config, err := s.loadConfig(ctx)
+	if err != nil {
+		if s.config == nil {
+			return nil, err
+		}
+		// Keep serving the last good configuration and retry after the next interval
+		logrus.WithError(err).Warn("Failed to reload transaction limits; using cached limits")
+		s.loadedAt = time.Now()
+		return s.config, nil
+	}
+
+	s.config = config
+	s.loadedAt = time.Now()
+	return s.config, nil
+}
+
+// GetDefaultLimits returns the limits of an account without overrides
+func (s *TransactionLimitService) GetDefaultLimits(ctx context.Context) (models.TransactionLimits, error) {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return models.TransactionLimits{}, err
+	}
+	return config.DefaultLimits, nil
+}
+
+// GetAccountLimits returns the limits that apply to account
+func (s *TransactionLimitService) GetAccountLimits(ctx context.Context, account *models.Account) (models.TransactionLimits, error) {
+	config, err := s.GetConfig(ctx)
+	if err != nil {
+		return models.TransactionLimits{}, err
+	}
+	return resolveTransactionLimits(config, account.AccountType, account.KYCStatus, account.KYCLevel), nil
+}
+
+// UpdateDefaultLimits replaces the default limits
+func (s *TransactionLimitService) UpdateDefaultLimits(ctx context.Context, limits *models.TransactionLimits, changedBy uuid.UUID) (*models.TransactionLimitConfig, error) {
+	if err := s.validator.Validate(limits); err != nil {
+		return nil, err
+	}
+
+	return s.updateConfig(ctx, models.ConfigKeyDefaultLimits, changedBy, func(config *models.TransactionLimitConfig) {
+		config.DefaultLimits = *limits
+	})
+}
+
+// SetAccountTypeOverride replaces the limit override of an account type
+func (s *TransactionLimitService) SetAccountTypeOverride(ctx context.Context, accountType models.AccountType, override *models.TransactionLimitsOverride, changedBy uuid.UUID) (*models.TransactionLimitConfig, error) {
+	if accountType != models.AccountTypePersonal && accountType != models.AccountTypeMerchant {
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid account type %q", accountType))
+	}
+	if err := s.validator.Validate(override); err != nil {
+		return nil, err
+	}
+
+	return s.updateConfig(ctx, models.ConfigKeyAccountTypeLimitOverrides, changedBy, func(config *models.TransactionLimitConfig) {
+		config.AccountTypeOverrides[accountType] = *override
+	})
+}
+
+// DeleteAccountTypeOverride removes the limit override of an account type
+func (s *TransactionLimitService) DeleteAccountTypeOverride(ctx context.Context, accountType models.AccountType, changedBy uuid.UUID) (*models.TransactionLimitConfig, error) {
+	return s.updateConfig(ctx, models.ConfigKeyAccountTypeLimitOverrides, changedBy, func(config *models.TransactionLimitConfig) {
+		delete(config.AccountTypeOverrides, accountType)
+	})
+}
+
+// SetKYCOverride replaces the limit override of a KYC status
+func (s *TransactionLimitService) SetKYCOverride(ctx context.Context, kycStatus models.KYCStatus, override *models.TransactionLimitsOverride, changedBy uuid.UUID) (*models.TransactionLimitConfig, error) {
+	switch kycStatus {
+	case models.KYCStatusUnverified, models.KYCStatusPending, models.KYCStatusVerified, models.KYCStatusRejected:
+	default:
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid KYC status %q", kycStatus))
+	}
+	if err := s.validator.Validate(override); err != nil {
+		return nil, err
+	}
+
+	return s.updateConfig(ctx, models.ConfigKeyKYCLimitOverrides, changedBy, func(config *models.TransactionLimitConfig) {
+		config.KYCOverrides[kycStatus] = *override
+	})
+}
+
+// DeleteKYCOverride removes the limit override of a KYC status
+func (s *TransactionLimitService) DeleteKYCOverride(ctx context.Context, kycStatus models.KYCStatus, changedBy uuid.UUID) (*models.TransactionLimitConfig, error) {
+	return s.updateConfig(ctx, models.ConfigKeyKYCLimitOverrides, changedBy, func(config *models.TransactionLimitConfig) {
+		delete(config.KYCOverrides, kycStatus)
+	})
+}
+
+// SetKYCLevelOverride replaces the limit override of a KYC level
+func (s *TransactionLimitService) SetKYCLevelOverride(ctx context.Context, kycLevel models.KYCLevel, override *models.TransactionLimitsOverride, changedBy uuid.UUID) (*models.TransactionLimitConfig, error) {
+	switch kycLevel {
+	case models.KYCLevelNone, models.KYCLevelBasic, models.KYCLevelFull:
+	default:
+		return nil, errors.NewBadRequestError(fmt.Sprintf("Invalid KYC level %q", kycLevel))
+	}
+	if err := s.validator.Validate(override); err != nil {
+		return nil, err
+	}
+
+	return s.updateConfig(ctx, models.ConfigKeyKYCLevelLimitOverrides, changedBy, func(config *models.TransactionLimitConfig) {
+		config.KYCLevelOverrides[kycLevel] = *override
+	})
+}
+
+// DeleteKYCLevelOverride removes the limit override of a KYC level
+func (s *TransactionLimitService) DeleteKYCLevelOverride(ctx context.Context, kycLevel models.KYCLevel, changedBy uuid.UUID) (*models.TransactionLimitConfig, error) {
+	return s.updateConfig(ctx, models.ConfigKeyKYCLevelLimitOverrides, changedBy, func(config *models.TransactionLimitConfig) {
+		delete(config.KYCLevelOverrides, kycLevel)
+	})
+}
+
+// UpdateAccountLimits sets the daily and monthly spending caps of one account
+func (s *TransactionLimitService) UpdateAccountLimits(ctx context.Context, accountID uuid.UUID, req *models.AccountLimitsUpdateRequest, changedBy uuid.UUID) (*models.Account, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+	if req.DailyLimit != nil && req.MonthlyLimit != nil && *req.MonthlyLimit < *req.DailyLimit {
+		return nil, errors.NewBadRequestError("monthly_limit must be at least daily_limit")
+	}
+
+	var account models.Account
+	if err := s.db.WithContext(ctx).Where("connect_id = ?", accountID).First(&account).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Account not found [" + ErrCodeAccountNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get account")
+	}
+
+	oldLimits := map[string]*int64{"daily_limit": account.DailyLimit, "monthly_limit": account.MonthlyLimit}
+
+	newLimits := map[string]*int64{"daily_limit": req.DailyLimit, "monthly_limit": req.MonthlyLimit}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&account).Updates(map[string]interface{}{
+			"daily_limit":   req.DailyLimit,
+			"monthly_limit": req.MonthlyLimit,
+		}).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update account limits")
+		}
+		return s.auditService.LogAuditTx(tx, "accounts", account.ConnectID, models.AuditActionUpdate, oldLimits, newLimits, &changedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	account.DailyLimit = req.DailyLimit
+	account.MonthlyLimit = req.MonthlyLimit
+
+	return &account, nil
+}
+
+// updateConfig applies change to a fresh copy of the configuration, validates the limits every
+// account could end up with, and stores the entry under key
+func (s *TransactionLimitService) updateConfig(ctx context.Context, key string, changedBy uuid.UUID, change func(config *models.TransactionLimitConfig)) (*models.TransactionLimitConfig, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config, err := s.loadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var oldValue interface{}
+	switch key {
+	case models.ConfigKeyDefaultLimits:
+		oldValue = config.DefaultLimits
+	case models.ConfigKeyAccountTypeLimitOverrides:
+		oldValue = cloneOverrides(config.AccountTypeOverrides)
+	case models.ConfigKeyKYCLimitOverrides:
+		oldValue = cloneOverrides(config.KYCOverrides)
+	case models.ConfigKeyKYCLevelLimitOverrides:
+		oldValue = cloneOverrides(config.KYCLevelOverrides)
+	}
+
+	change(config)
+	if err := s.validateConfig(config); err != nil {
+		return nil, err
+	}
+
+	var newValue interface{}
+	switch key {
+	case models.ConfigKeyDefaultLimits:
+		newValue = config.DefaultLimits
+	case models.ConfigKeyAccountTypeLimitOverrides:
+		newValue = config.AccountTypeOverrides
+	case models.ConfigKeyKYCLimitOverrides:
+		newValue = config.KYCOverrides
+	case models.ConfigKeyKYCLevelLimitOverrides:
+		newValue = config.KYCLevelOverrides
+	}
+
+	value, err := json.Marshal(newValue)
+	if err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to encode transaction limits")
+	}
+
+	entry := &models.SystemConfiguration{
+		Category: models.ConfigCategoryTransactionLimits,
+		Key:      key,
+		Value:    string(value),
+		IsActive: true,
+	}
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "category"}, {Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{"value", "is_active", "updated_at"}),
+		}).Create(entry).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to save transaction limits")
+		}
+		return s.auditService.LogAuditTx(tx, "system_configurations", entry.ID, models.AuditActionUpdate, oldValue, newValue, &changedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the change on this replica right away
+	s.config = config
+	s.loadedAt = time.Now()
+	return config, nil
+}
+
+// validateConfig checks the defaults and every combination of account type and KYC overrides
+func (s *TransactionLimitService) validateConfig(config *models.TransactionLimitConfig) error {
+	accountTypes := []models.AccountType{models.AccountTypePersonal, models.AccountTypeMerchant}
+	kycStatuses := []models.KYCStatus{models.KYCStatusUnverified, models.KYCStatusPending, models.KYCStatusVerified, models.KYCStatusRejected}
+	kycLevels := []models.KYCLevel{models.KYCLevelNone, models.KYCLevelBasic, models.KYCLevelFull}
+
+	for _, accountType := range accountTypes {
+		for _, kycStatus := range kycStatuses {
+			for _, kycLevel := range kycLevels {
+				limits := resolveTransactionLimits(config, accountType, kycStatus, kycLevel)
+				if err := s.validator.Validate(&limits); err != nil {
+					return errors.NewBadRequestError(fmt.Sprintf("Invalid limits for %s accounts with KYC status %s at level %s: %s", accountType, kycStatus, kycLevel, err.Error()))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// loadConfig reads the limit configuration from system_configurations
+func (s *TransactionLimitService) loadConfig(ctx context.Context) (*models.TransactionLimitConfig, error) {
+	var entries []models.SystemConfiguration
+	if err := s.db.WithContext(ctx).
+		Where("category = ? AND is_active = ?", models.ConfigCategoryTransactionLimits, true).
+		Find(&entries).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to load transaction limits")
+	}
+
+	config := &models.TransactionLimitConfig{
+		DefaultLimits:        fallbackTransactionLimits,
+		AccountTypeOverrides: map[models.AccountType]models.TransactionLimitsOverride{},
+		KYCOverrides:         map[models.KYCStatus]models.TransactionLimitsOverride{},
+		KYCLevelOverrides:    map[models.KYCLevel]models.TransactionLimitsOverride{},
+	}
+
+	hasDefaults := false
+	for _, entry := range entries {
+		var target interface{}
+		switch entry.Key {
+		case models.ConfigKeyDefaultLimits:
+			target = &config.DefaultLimits
+			hasDefaults = true
+		case models.ConfigKeyAccountTypeLimitOverrides:
+			target = &config.AccountTypeOverrides
+		case models.ConfigKeyKYCLimitOverrides:
+			target = &config.KYCOverrides
+		case models.ConfigKeyKYCLevelLimitOverrides:
+			target = &config.KYCLevelOverrides
+		default:
+			continue
+		}
+
+		if err := json.Unmarshal([]byte(entry.Value), target); err != nil {
+			return nil, errors.NewInternalServerError(err, fmt.Sprintf("Invalid transaction limits in %s.%s", entry.Category, entry.Key))
+		}
+	}
+
+	if !hasDefaults {
+		logrus.Warn("No transaction_limits.default_limits configuration found; using built-in limits")
+	}
+
+	return config, nil
+}
+
+// resolveTransactionLimits applies the account type, KYC status and KYC level overrides to the
+// default limits, in that order
+func resolveTransactionLimits(config *models.TransactionLimitConfig, accountType models.AccountType, kycStatus models.KYCStatus, kycLevel models.KYCLevel) models.TransactionLimits {
+	limits := config.DefaultLimits
+	if override, ok := config.AccountTypeOverrides[accountType]; ok {
+		limits = limits.Apply(override)
+	}
+	if override, ok := config.KYCOverrides[kycStatus]; ok {
+		limits = limits.Apply(override)
+	}
+	if override, ok := config.KYCLevelOverrides[kycLevel]; ok {
+		limits = limits.Apply(override)
+	}
+	return limits
+}
+
+func cloneOverrides[K comparable](overrides map[K]models.TransactionLimitsOverride) map[K]models.TransactionLimitsOverride {
+	clone := make(map[K]models.TransactionLimitsOverride, len(overrides))
+	for k, v := range overrides {
+		clone[k] = v
+	}
+	return clone
+}
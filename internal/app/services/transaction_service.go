@@ -2,8 +2,9 @@ package services
 
 import (
 	"context"
+	stderrors "errors"
 	"fmt"
-	"strconv"
+	"net/http"
 	"strings"
 	"time"
 
@@ -13,21 +14,26 @@ import (
 	"github.com/safatanc/gsalt-core/internal/app/pkg"
 	"github.com/safatanc/gsalt-core/internal/infrastructures"
 	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Error codes for better client error handling
 const (
 	ErrCodeInsufficientBalance     = "INSUFFICIENT_BALANCE"
 	ErrCodeDailyLimitExceeded      = "DAILY_LIMIT_EXCEEDED"
+	ErrCodeMonthlyLimitExceeded    = "MONTHLY_LIMIT_EXCEEDED"
 	ErrCodeAmountBelowMinimum      = "AMOUNT_BELOW_MINIMUM"
 	ErrCodeAmountAboveMaximum      = "AMOUNT_ABOVE_MAXIMUM"
 	ErrCodeSelfTransfer            = "SELF_TRANSFER_NOT_ALLOWED"
 	ErrCodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
 	ErrCodeDuplicateTransaction    = "DUPLICATE_TRANSACTION"
 	ErrCodeAccountNotFound         = "ACCOUNT_NOT_FOUND"
+	ErrCodeAccountClosing          = "ACCOUNT_CLOSING"
 	ErrCodeTransactionNotFound     = "TRANSACTION_NOT_FOUND"
 	ErrCodeInvalidPaymentMethod    = "INVALID_PAYMENT_METHOD"
+	ErrCodeRefundExceedsAmount     = "REFUND_EXCEEDS_AMOUNT"
 )
 
 // Supported currencies for payments
@@ -48,7 +54,10 @@ type TransactionService struct {
 	paymentMethodService *PaymentMethodService
 	paymentService       *PaymentService
 	auditService         *AuditService
-	limits               TransactionLimits
+	ledgerService        *LedgerService
+	providerRegistry     *PaymentProviderRegistry
+	webhookService       *MerchantWebhookService
+	limitService         *TransactionLimitService
 }
 
 func NewTransactionService(
@@ -60,6 +69,10 @@ func NewTransactionService(
 	paymentMethodService *PaymentMethodService,
 	paymentService *PaymentService,
 	auditService *AuditService,
+	ledgerService *LedgerService,
+	providerRegistry *PaymentProviderRegistry,
+	webhookService *MerchantWebhookService,
+	limitService *TransactionLimitService,
 ) *TransactionService {
 	return &TransactionService{
 		db:                   db,
@@ -70,33 +83,24 @@ func NewTransactionService(
 		paymentMethodService: paymentMethodService,
 		paymentService:       paymentService,
 		auditService:         auditService,
-		limits:               defaultLimits,
+		ledgerService:        ledgerService,
+		providerRegistry:     providerRegistry,
+		webhookService:       webhookService,
+		limitService:         limitService,
 	}
 }
 
-// Transaction limits configuration (in GSALT units)
-type TransactionLimits struct {
-	MinTopupAmount     int64 // In GSALT units (100 units = 1 GSALT)
-	MaxTopupAmount     int64
-	MinTransferAmount  int64
-	MaxTransferAmount  int64
-	MinPaymentAmount   int64
-	MaxPaymentAmount   int64
-	DailyTransferLimit int64
-	DailyPaymentLimit  int64
-}
-
-// Default transaction limits (in GSALT units)
-var defaultLimits = TransactionLimits{
-	MinTopupAmount:     1000,     // 10 GSALT (10,000 IDR)
-	MaxTopupAmount:     5000000,  // 50,000 GSALT (50,000,000 IDR)
-	MinTransferAmount:  100,      // 1 GSALT (1,000 IDR)
-	MaxTransferAmount:  2500000,  // 25,000 GSALT (25,000,000 IDR)
-	MinPaymentAmount:   100,      // 1 GSALT (1,000 IDR)
-	MaxPaymentAmount:   1000000,  // 10,000 GSALT (10,000,000 IDR)
-	DailyTransferLimit: 10000000, // 100,000 GSALT (100,000,000 IDR)
-	DailyPaymentLimit:  5000000,  // 50,000 GSALT (50,000,000 IDR)
-}
+const (
+	// defaultPendingTransactionTTL applies to pending transactions without a payment expiry time
+	defaultPendingTransactionTTL = 24 * time.Hour
+	// expiryBatchSize bounds how many transactions a single expiry run handles
+	expiryBatchSize = 100
+	// payoutReconcileDelay is how long a withdrawal may wait for its payout ID before the provider
+	// is asked about it, longer than any in-flight payout request can take
+	payoutReconcileDelay = 10 * time.Minute
+	// payoutReconcileBatchSize bounds how many withdrawals a single reconcile run handles
+	payoutReconcileBatchSize = 50
+)
 
 // Helper function to compare balance (both are in GSALT units)
 func (s *TransactionService) hasSufficientBalance(balance int64, amountGsaltUnits int64) bool {
@@ -395,127 +399,119 @@ func (s *TransactionService) ProcessTopup(accountId string, amountGsaltUnits int
 	// Get current exchange rate
 	exchangeRate := decimal.NewFromInt(10000) // 1 GSALT = 10,000 IDR
 
-	var transaction *models.Transaction
+	// Resolve the provider that serves this payment method
+	provider, err := s.providerRegistry.Get(paymentMethod.ProviderCode)
+	if err != nil {
+		return nil, err
+	}
 
-	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Create transaction record
-		description := fmt.Sprintf("Topup %d GSALT", amountGsaltUnits/100)
-		transaction = s.createBaseTransaction(accountUUID, models.TransactionTypeTopup, amountGsaltUnits, models.TransactionStatusPending, &description)
-		transaction.ExchangeRateIDR = exchangeRate
-		transaction.PaymentAmount = &finalPaymentAmount
-		transaction.PaymentCurrency = &paymentMethod.Currency
-		transaction.PaymentMethod = &paymentMethod.Code
-		if externalRefId != nil {
-			transaction.ExternalReferenceID = externalRefId
-		}
+	connectUser, err := s.connectService.GetUser(accountUUID.String())
+	if err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get connect user details")
+	}
 
-		if err := tx.Create(transaction).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to create topup transaction")
-		}
+	// Commit the PENDING topup before asking the provider for a charge, so no charge exists
+	// without a transaction to settle it
+	description := fmt.Sprintf("Topup %d GSALT", amountGsaltUnits/100)
+	transaction := s.createBaseTransaction(accountUUID, models.TransactionTypeTopup, amountGsaltUnits, models.TransactionStatusPending, &description)
+	transaction.ExchangeRateIDR = exchangeRate
+	transaction.FeeGsaltUnits = feeGsaltUnits
+	transaction.TotalAmountGsaltUnits = totalAmountGsalt
+	transaction.PaymentAmount = &finalPaymentAmount
+	transaction.PaymentCurrency = &paymentMethod.Currency
+	transaction.PaymentMethod = &paymentMethod.Code
+	if externalRefId != nil {
+		transaction.ExternalReferenceID = externalRefId
+	}
 
-		// --- PAYLOAD TO PROVIDER ---
-		// Currently, we only support FLIP. In the future, we can use a factory or strategy pattern here based on `paymentMethod.ProviderCode`.
-		if paymentMethod.ProviderCode != "FLIP" {
-			return errors.NewInternalServerError(nil, fmt.Sprintf("Payment provider '%s' is not implemented", paymentMethod.ProviderCode))
-		}
+	if err := s.db.Create(transaction).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to create topup transaction")
+	}
 
-		connectUser, err := s.connectService.GetUser(accountUUID.String())
-		if err != nil {
-			return errors.NewInternalServerError(err, "Failed to get connect user details")
-		}
-
-		internalRef := fmt.Sprintf("GSALT-%s", pkg.RandomNumberString(10))
-		expiredDate := time.Now().Add(time.Hour * 3)
-		billReq := models.CreateBillRequest{
-			Title:             fmt.Sprintf("GSALT Topup - %s", transaction.ID.String()),
-			Amount:            finalPaymentAmount,
-			ReferenceID:       internalRef,
-			ChargeFee:         true,
-			ExpiredDate:       expiredDate.Format("2006-01-02 15:04"),
-			SenderPhoneNumber: "081234567890",
-			Type:              "SINGLE",
-			Step:              "3",
-			SenderName:        connectUser.FullName,
-			SenderEmail:       connectUser.Email,
-			SenderBank:        paymentMethod.ProviderMethodCode,
-			SenderBankType:    paymentMethod.ProviderMethodType,
-			RedirectURL:       "https://connect.safatanc.com/gsalt/topup/success",
-			ItemDetails: []models.ItemDetail{
-				{
-					Name:     "GSALT Balance",
-					Price:    amountIDR,
-					Quantity: 1,
-					Desc:     fmt.Sprintf("%d GSALT", amountGsaltUnits/100),
-				},
-				{
-					Name:     "Fee",
-					Price:    feeDecimal.IntPart(),
-					Quantity: 1,
-					Desc:     "Payment processing fee",
-				},
+	chargeReq := models.ProviderChargeRequest{
+		TransactionID: transaction.ID,
+		Title:         fmt.Sprintf("GSALT Topup - %s", transaction.ID.String()),
+		Amount:        finalPaymentAmount,
+		Currency:      paymentMethod.Currency,
+		ReferenceID:   fmt.Sprintf("GSALT-%s", pkg.RandomNumberString(10)),
+		ExpiresAt:     time.Now().Add(time.Hour * 3),
+		MethodCode:    paymentMethod.ProviderMethodCode,
+		MethodType:    paymentMethod.ProviderMethodType,
+		CustomerName:  connectUser.FullName,
+		CustomerEmail: connectUser.Email,
+		CustomerPhone: "081234567890",
+		RedirectURL:   "https://connect.safatanc.com/gsalt/topup/success",
+		ChargeFee:     true,
+		Items: []models.ItemDetail{
+			{
+				Name:     "GSALT Balance",
+				Price:    amountIDR,
+				Quantity: 1,
+				Desc:     fmt.Sprintf("%d GSALT", amountGsaltUnits/100),
 			},
-		}
-
-		// Create payment in Flip
-		billResp, err := s.flipService.CreateBill(context.Background(), billReq)
-		if err != nil {
-			return errors.NewInternalServerError(err, "Failed to create payment in Flip")
-		}
+			{
+				Name:     "Fee",
+				Price:    feeDecimal.IntPart(),
+				Quantity: 1,
+				Desc:     "Payment processing fee",
+			},
+		},
+	}
 
-		var providerPaymentIdString string
-		providerPaymentIdString = strconv.Itoa(billResp.LinkID)
-		expiryTime, err := time.Parse("2006-01-02 15:04", *billResp.ExpiredDate)
-		if err != nil {
-			return errors.NewInternalServerError(err, "Failed to parse expiry time")
-		}
+	// Create charge at the provider, outside any database transaction
+	ctx := context.Background()
+	chargeResp, err := provider.CreateCharge(ctx, chargeReq)
+	if err != nil {
+		s.failTopup(transaction, fmt.Sprintf("Failed to create payment in %s", provider.Code()))
+		return nil, errors.NewInternalServerError(err, fmt.Sprintf("Failed to create payment in %s", provider.Code()))
+	}
 
-		// Create payment details
-		paymentDetails := &models.PaymentDetails{
-			ID:                uuid.New(),
-			TransactionID:     transaction.ID,
-			Provider:          paymentMethod.ProviderCode,
-			ProviderPaymentID: &providerPaymentIdString,
-			PaymentURL:        &billResp.LinkURL,
-			ExpiryTime:        &expiryTime,
-			CreatedAt:         time.Now(),
-			UpdatedAt:         time.Now(),
-		}
+	// Create payment details
+	paymentDetails := &models.PaymentDetails{
+		ID:                   uuid.New(),
+		TransactionID:        transaction.ID,
+		Provider:             provider.Code(),
+		ProviderPaymentID:    &chargeResp.ProviderPaymentID,
+		PaymentURL:           chargeResp.PaymentURL,
+		QRCode:               chargeResp.QRCode,
+		VirtualAccountNumber: chargeResp.VirtualAccountNumber,
+		ExpiryTime:           chargeResp.ExpiryTime,
+		RawProviderResponse:  chargeResp.RawResponse,
+		CreatedAt:            time.Now(),
+		UpdatedAt:            time.Now(),
+	}
 
-		if err := tx.Create(paymentDetails).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to create payment details")
+	if err := s.db.Create(paymentDetails).Error; err != nil {
+		// Nobody has seen the charge yet, so withdraw it; the topup expires without payment details
+		if cancelErr := provider.CancelCharge(ctx, chargeResp.ProviderPaymentID); cancelErr != nil {
+			logrus.WithFields(logrus.Fields{
+				"transaction_id": transaction.ID,
+				"charge_id":      chargeResp.ProviderPaymentID,
+			}).WithError(cancelErr).Warn("Failed to cancel unrecorded charge")
 		}
-
-		return nil
-	})
-
-	if err != nil {
-		return nil, err
+		return nil, errors.NewInternalServerError(err, "Failed to create payment details")
 	}
 
 	return transaction, nil
 }
 
-// updateAccountBalance updates the account balance with the given amount
-func (s *TransactionService) updateAccountBalance(tx *gorm.DB, accountID uuid.UUID, amountGsaltUnits int64) error {
-	// Lock and get account
-	var account models.Account
-	if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("connect_id = ?", accountID).First(&account).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return errors.NewNotFoundError("Account not found")
-		}
-		return errors.NewInternalServerError(err, "Failed to get account")
-	}
-
-	// Update balance
-	account.Balance += amountGsaltUnits
-	if err := tx.Save(&account).Error; err != nil {
-		return errors.NewInternalServerError(err, "Failed to update account balance")
+// failTopup marks a topup whose charge could not be created as FAILED
+func (s *TransactionService) failTopup(transaction *models.Transaction, reason string) {
+	now := time.Now()
+	if err := s.db.Model(transaction).Updates(map[string]interface{}{
+		"status":                     models.TransactionStatusFailed,
+		"payment_status":             models.PaymentStatusFailed,
+		"payment_status_description": reason,
+		"payment_failed_at":          now,
+		"updated_at":                 now,
+	}).Error; err != nil {
+		logrus.WithField("transaction_id", transaction.ID).WithError(err).Warn("Failed to mark topup as failed")
 	}
-
-	return nil
 }
 
-func (s *TransactionService) ProcessTransfer(sourceAccountId, destAccountId string, amountGsaltUnits int64, description *string) (*models.Transaction, *models.Transaction, error) {
+// ProcessTransfer moves GSALT between two wallets. A non-nil externalReferenceID is stored on
+// both sides and may only be paid once, which makes a dynamic QR single use.
+func (s *TransactionService) ProcessTransfer(sourceAccountId, destAccountId string, amountGsaltUnits int64, description *string, externalReferenceID *string) (*models.Transaction, *models.Transaction, error) {
 	// Parse UUIDs using helper functions
 	sourceUUID, err := s.parseUUID(sourceAccountId, "source account ID")
 	if err != nil {
@@ -532,13 +528,15 @@ func (s *TransactionService) ProcessTransfer(sourceAccountId, destAccountId stri
 		return nil, nil, errors.NewBadRequestError("Cannot transfer to the same account [" + ErrCodeSelfTransfer + "]")
 	}
 
-	// Validate transaction amount
-	if err := s.validateTransactionAmount(models.TransactionTypeTransferOut, amountGsaltUnits); err != nil {
-		return nil, nil, err
+	// Check the amount and the daily and monthly limits of the source account
+	var limitAccount models.Account
+	if err := s.db.Where("connect_id = ?", sourceUUID).First(&limitAccount).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil, errors.NewNotFoundError("Source account not found")
+		}
+		return nil, nil, errors.NewInternalServerError(err, "Failed to get source account")
 	}
-
-	// Check daily limits
-	if err := s.checkDailyLimits(sourceUUID, models.TransactionTypeTransferOut, amountGsaltUnits); err != nil {
+	if err := s.checkTransactionLimits(&limitAccount, models.TransactionTypeTransferOut, amountGsaltUnits); err != nil {
 		return nil, nil, err
 	}
 
@@ -546,32 +544,36 @@ func (s *TransactionService) ProcessTransfer(sourceAccountId, destAccountId stri
 	now := time.Now()
 
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Lock and verify source account with SELECT FOR UPDATE
-		var sourceAccount models.Account
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("connect_id = ?", sourceUUID).First(&sourceAccount).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.NewNotFoundError("Source account not found")
-			}
-			return errors.NewInternalServerError(err, "Failed to get source account")
+		// Lock both accounts; payments of the same reference serialise on the destination row lock
+		sourceAccount, destAccount, err := s.lockAccountPair(tx, sourceUUID, destUUID)
+		if err != nil {
+			return err
+		}
+		if destAccount.Status == models.AccountStatusClosing {
+			return errors.NewBadRequestError("Destination account is being closed [" + ErrCodeAccountClosing + "]")
 		}
 
-		// Lock and verify destination account with SELECT FOR UPDATE
-		var destAccount models.Account
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("connect_id = ?", destUUID).First(&destAccount).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.NewNotFoundError("Destination account not found")
+		if externalReferenceID != nil {
+			var paid int64
+			if err := tx.Model(&models.Transaction{}).
+				Where("account_id = ? AND type = ? AND external_reference_id = ?", destUUID, models.TransactionTypeTransferIn, *externalReferenceID).
+				Count(&paid).Error; err != nil {
+				return errors.NewInternalServerError(err, "Failed to check transfer reference")
+			}
+			if paid > 0 {
+				return errors.NewAppError(http.StatusConflict, "This transfer has already been paid ["+ErrCodeDuplicateTransaction+"]")
 			}
-			return errors.NewInternalServerError(err, "Failed to get destination account")
 		}
 
 		// Check sufficient balance
-		if !s.hasSufficientBalance(sourceAccount.Balance, amountGsaltUnits) {
+		if !s.hasSufficientBalance(sourceAccount.AvailableBalance, amountGsaltUnits) {
 			return errors.NewBadRequestError("Insufficient balance [" + ErrCodeInsufficientBalance + "]")
 		}
 
 		// Create outgoing transfer record using helper function
 		transferOut = s.createBaseTransaction(sourceUUID, models.TransactionTypeTransferOut, amountGsaltUnits, models.TransactionStatusCompleted, description)
 		transferOut.DestinationAccountID = &destUUID
+		transferOut.ExternalReferenceID = externalReferenceID
 		transferOut.CompletedAt = &now
 
 		// Create transaction in database
@@ -584,6 +586,7 @@ func (s *TransactionService) ProcessTransfer(sourceAccountId, destAccountId stri
 		transferIn = s.createBaseTransaction(destUUID, models.TransactionTypeTransferIn, amountGsaltUnits, models.TransactionStatusCompleted, description)
 		transferIn.SourceAccountID = &sourceUUID
 		transferIn.RelatedTransactionID = &transferOutIDPtr
+		transferIn.ExternalReferenceID = externalReferenceID
 		transferIn.CompletedAt = &now
 
 		// Create transaction in database
@@ -595,16 +598,21 @@ func (s *TransactionService) ProcessTransfer(sourceAccountId, destAccountId stri
 		transferInIDPtr := transferIn.ID
 		transferOut.RelatedTransactionID = &transferInIDPtr
 
-		// Update account balances atomically (all in GSALT units)
-		sourceAccount.Balance -= amountGsaltUnits
-		destAccount.Balance += amountGsaltUnits
+		// Move the balance through the ledger (all in GSALT units)
+		entry, err := s.ledgerService.RecordWalletTransfer(tx, sourceUUID, destUUID, amountGsaltUnits, transferOut.ID.String(), description)
+		if err != nil {
+			return err
+		}
 
-		if err := tx.Save(&sourceAccount).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to update source account balance")
+		transferOut.JournalEntryID = &entry.ID
+		transferIn.JournalEntryID = &entry.ID
+
+		if err := tx.Save(transferOut).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update transfer out transaction")
 		}
 
-		if err := tx.Save(&destAccount).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to update destination account balance")
+		if err := tx.Save(transferIn).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update transfer in transaction")
 		}
 
 		return nil
@@ -617,6 +625,40 @@ func (s *TransactionService) ProcessTransfer(sourceAccountId, destAccountId stri
 	return transferOut, transferIn, nil
 }
 
+// lockAccountPair locks the source and destination accounts of a wallet transfer. The rows are
+// locked in connect_id order so that transfers in opposite directions cannot deadlock.
+func (s *TransactionService) lockAccountPair(tx *gorm.DB, sourceUUID, destUUID uuid.UUID) (*models.Account, *models.Account, error) {
+	var sourceAccount, destAccount models.Account
+
+	lock := func(connectID uuid.UUID, account *models.Account, name string) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("connect_id = ?", connectID).First(account).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return errors.NewNotFoundError(name + " account not found")
+			}
+			return errors.NewInternalServerError(err, "Failed to get "+strings.ToLower(name)+" account")
+		}
+		return nil
+	}
+
+	if sourceUUID.String() < destUUID.String() {
+		if err := lock(sourceUUID, &sourceAccount, "Source"); err != nil {
+			return nil, nil, err
+		}
+		if err := lock(destUUID, &destAccount, "Destination"); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		if err := lock(destUUID, &destAccount, "Destination"); err != nil {
+			return nil, nil, err
+		}
+		if err := lock(sourceUUID, &sourceAccount, "Source"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &sourceAccount, &destAccount, nil
+}
+
 // ProcessPayment processes external payment (QRIS, Bank Transfer, E-wallet, Credit Card, etc.)
 func (s *TransactionService) ProcessPayment(request models.PaymentRequest) (*models.Transaction, error) {
 	// Validate request
@@ -676,74 +718,104 @@ func (s *TransactionService) ProcessPayment(request models.PaymentRequest) (*mod
 	return transaction, nil
 }
 
-// Helper function to validate transaction amounts (in GSALT units)
-func (s *TransactionService) validateTransactionAmount(txnType models.TransactionType, amountGsaltUnits int64) error {
+// Helper function to validate transaction amounts against limits (in GSALT units)
+func validateTransactionAmount(limits models.TransactionLimits, txnType models.TransactionType, amountGsaltUnits int64) error {
+	var name string
+	var minAmount, maxAmount int64
+
 	switch txnType {
 	case models.TransactionTypeTopup:
-		if amountGsaltUnits < s.limits.MinTopupAmount {
-			return fmt.Errorf("topup amount must be at least %d GSALT units", s.limits.MinTopupAmount)
-		}
-		if amountGsaltUnits > s.limits.MaxTopupAmount {
-			return fmt.Errorf("topup amount cannot exceed %d GSALT units", s.limits.MaxTopupAmount)
-		}
+		name, minAmount, maxAmount = "Topup", limits.MinTopupAmount, limits.MaxTopupAmount
 	case models.TransactionTypeTransferOut:
-		if amountGsaltUnits < s.limits.MinTransferAmount {
-			return fmt.Errorf("transfer amount must be at least %d GSALT units", s.limits.MinTransferAmount)
-		}
-		if amountGsaltUnits > s.limits.MaxTransferAmount {
-			return fmt.Errorf("transfer amount cannot exceed %d GSALT units", s.limits.MaxTransferAmount)
-		}
+		name, minAmount, maxAmount = "Transfer", limits.MinTransferAmount, limits.MaxTransferAmount
 	case models.TransactionTypePayment:
-		if amountGsaltUnits < s.limits.MinPaymentAmount {
-			return fmt.Errorf("payment amount must be at least %d GSALT units", s.limits.MinPaymentAmount)
-		}
-		if amountGsaltUnits > s.limits.MaxPaymentAmount {
-			return fmt.Errorf("payment amount cannot exceed %d GSALT units", s.limits.MaxPaymentAmount)
-		}
+		name, minAmount, maxAmount = "Payment", limits.MinPaymentAmount, limits.MaxPaymentAmount
 	case models.TransactionTypeWithdrawal:
-		if amountGsaltUnits < s.limits.MinTransferAmount {
-			return fmt.Errorf("withdrawal amount must be at least %d GSALT units", s.limits.MinTransferAmount)
-		}
-		if amountGsaltUnits > s.limits.MaxTransferAmount {
-			return fmt.Errorf("withdrawal amount cannot exceed %d GSALT units", s.limits.MaxTransferAmount)
-		}
+		name, minAmount, maxAmount = "Withdrawal", limits.MinTransferAmount, limits.MaxTransferAmount
+	default:
+		return nil
+	}
+
+	if amountGsaltUnits < minAmount {
+		return errors.NewBadRequestError(fmt.Sprintf("%s amount must be at least %d GSALT units [%s]", name, minAmount, ErrCodeAmountBelowMinimum))
+	}
+	if amountGsaltUnits > maxAmount {
+		return errors.NewBadRequestError(fmt.Sprintf("%s amount cannot exceed %d GSALT units [%s]", name, maxAmount, ErrCodeAmountAboveMaximum))
 	}
 	return nil
 }
 
-// Helper function to check daily limits (in GSALT units)
-func (s *TransactionService) checkDailyLimits(accountId uuid.UUID, txnType models.TransactionType, amountGsaltUnits int64) error {
-	today := time.Now().Truncate(24 * time.Hour)
-	tomorrow := today.Add(24 * time.Hour)
+// checkTransactionLimits validates the amount against the limits of the account and checks that
+// the account stays within its daily and monthly limits (in GSALT units)
+func (s *TransactionService) checkTransactionLimits(account *models.Account, txnType models.TransactionType, amountGsaltUnits int64) error {
+	limits, err := s.limitService.GetAccountLimits(context.Background(), account)
+	if err != nil {
+		return err
+	}
 
-	var totalToday int64
-	var dailyLimit int64
+	if err := validateTransactionAmount(limits, txnType, amountGsaltUnits); err != nil {
+		return err
+	}
 
+	var dailyLimit, monthlyLimit int64
 	switch txnType {
-	case models.TransactionTypeTransferOut:
-		dailyLimit = defaultLimits.DailyTransferLimit
-		s.db.Model(&models.Transaction{}).
-			Where("account_id = ? AND type = ? AND created_at >= ? AND created_at < ? AND status = ?",
-				accountId, models.TransactionTypeTransferOut, today, tomorrow, models.TransactionStatusCompleted).
-			Select("COALESCE(SUM(amount_gsalt_units), 0)").Scan(&totalToday)
+	case models.TransactionTypeTransferOut, models.TransactionTypeWithdrawal:
+		dailyLimit, monthlyLimit = limits.DailyTransferLimit, limits.MonthlyTransferLimit
 	case models.TransactionTypePayment:
-		dailyLimit = defaultLimits.DailyPaymentLimit
-		s.db.Model(&models.Transaction{}).
-			Where("account_id = ? AND type = ? AND created_at >= ? AND created_at < ? AND status = ?",
-				accountId, models.TransactionTypePayment, today, tomorrow, models.TransactionStatusCompleted).
-			Select("COALESCE(SUM(amount_gsalt_units), 0)").Scan(&totalToday)
-	case models.TransactionTypeWithdrawal:
-		dailyLimit = defaultLimits.DailyTransferLimit
-		s.db.Model(&models.Transaction{}).
-			Where("account_id = ? AND type = ? AND created_at >= ? AND created_at < ? AND status = ?",
-				accountId, models.TransactionTypeWithdrawal, today, tomorrow, models.TransactionStatusCompleted).
-			Select("COALESCE(SUM(amount_gsalt_units), 0)").Scan(&totalToday)
+		dailyLimit, monthlyLimit = limits.DailyPaymentLimit, limits.MonthlyPaymentLimit
 	default:
-		return nil // No daily limit for other transaction types
+		return nil // No period limits for other transaction types
 	}
 
-	if totalToday+amountGsaltUnits > dailyLimit {
-		return errors.NewBadRequestError("Daily transaction limit exceeded [" + ErrCodeDailyLimitExceeded + "]")
+	now := time.Now().UTC()
+	today := now.Truncate(24 * time.Hour)
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	if err := s.checkPeriodLimit(account.ConnectID, []models.TransactionType{txnType}, today, dailyLimit, amountGsaltUnits, "Daily", ErrCodeDailyLimitExceeded); err != nil {
+		return err
+	}
+	if err := s.checkPeriodLimit(account.ConnectID, []models.TransactionType{txnType}, thisMonth, monthlyLimit, amountGsaltUnits, "Monthly", ErrCodeMonthlyLimitExceeded); err != nil {
+		return err
+	}
+
+	// Limits set on the account itself cover all outgoing transactions together
+	outgoing := []models.TransactionType{models.TransactionTypeTransferOut, models.TransactionTypePayment, models.TransactionTypeWithdrawal}
+	if account.DailyLimit != nil {
+		if err := s.checkPeriodLimit(account.ConnectID, outgoing, today, *account.DailyLimit, amountGsaltUnits, "Daily account", ErrCodeDailyLimitExceeded); err != nil {
+			return err
+		}
+	}
+	if account.MonthlyLimit != nil {
+		if err := s.checkPeriodLimit(account.ConnectID, outgoing, thisMonth, *account.MonthlyLimit, amountGsaltUnits, "Monthly account", ErrCodeMonthlyLimitExceeded); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// limitExcludedStatuses are the statuses of transactions that moved no money, which are left out
+// of daily and monthly totals. In-flight withdrawals (PENDING or PROCESSING) count.
+var limitExcludedStatuses = []models.TransactionStatus{models.TransactionStatusFailed, models.TransactionStatusCancelled}
+
+// countsTowardLimits scopes a transaction query to the transactions included in limit totals
+func countsTowardLimits(db *gorm.DB) *gorm.DB {
+	return db.Where("status NOT IN ?", limitExcludedStatuses)
+}
+
+// checkPeriodLimit fails when the transactions of the given types created since the start of the
+// period, other than failed and cancelled ones, plus amountGsaltUnits exceed limit
+func (s *TransactionService) checkPeriodLimit(accountId uuid.UUID, txnTypes []models.TransactionType, since time.Time, limit int64, amountGsaltUnits int64, name string, errCode string) error {
+	var total int64
+	if err := s.db.Model(&models.Transaction{}).
+		Scopes(countsTowardLimits).
+		Where("account_id = ? AND type IN ? AND created_at >= ?", accountId, txnTypes, since).
+		Select("COALESCE(SUM(amount_gsalt_units), 0)").Scan(&total).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to check transaction limits")
+	}
+
+	if total+amountGsaltUnits > limit {
+		return errors.NewBadRequestError(name + " transaction limit exceeded [" + errCode + "]")
 	}
 
 	return nil
@@ -757,11 +829,27 @@ func (s *TransactionService) validateStatusTransition(currentStatus, newStatus m
 			models.TransactionStatusFailed,
 			models.TransactionStatusCancelled,
 		},
-		models.TransactionStatusCompleted: {}, // No transitions allowed from completed
+		models.TransactionStatusProcessing: {
+			models.TransactionStatusCompleted,
+			models.TransactionStatusFailed,
+			models.TransactionStatusCancelled,
+		},
+		// Completed transactions only move through refunds and reversals
+		models.TransactionStatusCompleted: {
+			models.TransactionStatusPartiallyRefunded,
+			models.TransactionStatusRefunded,
+			models.TransactionStatusReversed,
+		},
+		models.TransactionStatusPartiallyRefunded: {
+			models.TransactionStatusPartiallyRefunded,
+			models.TransactionStatusRefunded,
+		},
 		models.TransactionStatusFailed: {
 			models.TransactionStatusPending, // Allow retry
 		},
 		models.TransactionStatusCancelled: {}, // No transitions allowed from cancelled
+		models.TransactionStatusRefunded:  {},
+		models.TransactionStatusReversed:  {},
 	}
 
 	allowedStatuses, exists := validTransitions[currentStatus]
@@ -778,24 +866,117 @@ func (s *TransactionService) validateStatusTransition(currentStatus, newStatus m
 	return errors.NewBadRequestError("Invalid status transition [" + ErrCodeInvalidStatusTransition + "]")
 }
 
-// Method to expire old pending transactions
-func (s *TransactionService) ExpirePendingTransactions() error {
-	expiryTime := time.Now().Add(-24 * time.Hour) // 24 hours ago
+// ExpirePendingTransactions expires pending transactions whose payment window has passed.
+// Transactions with payment details expire at PaymentDetails.ExpiryTime; others fall back to
+// defaultPendingTransactionTTL. Withdrawals are left to their payout status. When
+// CANCEL_EXPIRED_CHARGES is enabled the charge is deactivated at the provider first, so a
+// payer can never pay into a bill that has already been expired here.
+func (s *TransactionService) ExpirePendingTransactions(ctx context.Context) error {
+	now := time.Now()
 
-	result := s.db.Model(&models.Transaction{}).
-		Where("status = ? AND created_at < ?", models.TransactionStatusPending, expiryTime).
-		Updates(map[string]interface{}{
-			"status": models.TransactionStatusCancelled,
-			// GORM will handle updated_at automatically
-		})
+	var transactionIDs []uuid.UUID
+	err := s.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Where("status = ? AND type <> ?", models.TransactionStatusPending, models.TransactionTypeWithdrawal).
+		Where(`EXISTS (SELECT 1 FROM payment_details pd WHERE pd.transaction_id = transactions.id AND pd.expiry_time < ?)
+			OR (NOT EXISTS (SELECT 1 FROM payment_details pd WHERE pd.transaction_id = transactions.id AND pd.expiry_time IS NOT NULL) AND created_at < ?)`,
+			now, now.Add(-defaultPendingTransactionTTL)).
+		Order("created_at").
+		Limit(expiryBatchSize).
+		Pluck("id", &transactionIDs).Error
+	if err != nil {
+		return errors.NewInternalServerError(err, "Failed to get expired pending transactions")
+	}
 
-	if result.Error != nil {
-		return errors.NewInternalServerError(result.Error, "Failed to expire pending transactions")
+	expired := 0
+	for _, transactionID := range transactionIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		ok, err := s.expirePendingTransaction(ctx, transactionID)
+		if err != nil {
+			logrus.WithField("transaction_id", transactionID).WithError(err).Warn("Failed to expire pending transaction")
+			continue
+		}
+		if ok {
+			expired++
+		}
+	}
+
+	if len(transactionIDs) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"candidates": len(transactionIDs),
+			"expired":    expired,
+		}).Info("Expired pending transactions")
 	}
 
 	return nil
 }
 
+// expirePendingTransaction expires a single transaction. It returns false when the transaction
+// was left pending, e.g. because the provider could not confirm the charge is closed.
+func (s *TransactionService) expirePendingTransaction(ctx context.Context, transactionID uuid.UUID) (bool, error) {
+	var paymentDetails *models.PaymentDetails
+	details, err := s.paymentService.GetPaymentDetailsByTransactionID(ctx, transactionID)
+	if err == nil {
+		paymentDetails = details
+	} else if appErr, ok := err.(*errors.AppError); !ok || appErr.StatusCode != http.StatusNotFound {
+		return false, err
+	}
+
+	reason := "Payment expired"
+	if paymentDetails != nil && paymentDetails.ProviderPaymentID != nil && infrastructures.Config.CancelExpiredCharges {
+		provider, err := s.providerRegistry.Get(paymentDetails.Provider)
+		if err != nil {
+			return false, err
+		}
+
+		// A payment that landed just before expiry is settled instead of expired
+		chargeStatus, err := provider.GetChargeStatus(ctx, *paymentDetails.ProviderPaymentID)
+		if err != nil {
+			return false, fmt.Errorf("failed to get charge status: %w", err)
+		}
+		if chargeStatus.Status == models.PaymentStatusCompleted {
+			return false, s.settlePaidExpiredCharge(transactionID, chargeStatus)
+		}
+
+		if err := provider.CancelCharge(ctx, *paymentDetails.ProviderPaymentID); err != nil {
+			return false, fmt.Errorf("failed to cancel charge: %w", err)
+		}
+		reason = "Payment expired, charge cancelled at provider"
+	}
+
+	// trg_transaction_status_change records the TransactionStatusHistory row for the
+	// PENDING -> CANCELLED change, using payment_status_description as the reason
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, err := s.SettlePayment(tx, transactionID, models.PaymentStatusExpired, nil, &reason)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// settlePaidExpiredCharge completes a transaction whose charge was paid before it could be expired
+func (s *TransactionService) settlePaidExpiredCharge(transactionID uuid.UUID, chargeStatus *models.ProviderChargeStatus) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+		if err := tx.Where("id = ?", transactionID).First(&transaction).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to get transaction")
+		}
+
+		if transaction.PaymentAmount == nil || *transaction.PaymentAmount != chargeStatus.Amount {
+			return fmt.Errorf("charge %s is paid but the amount %d does not match", chargeStatus.ProviderPaymentID, chargeStatus.Amount)
+		}
+
+		_, err := s.SettlePayment(tx, transactionID, models.PaymentStatusCompleted, &chargeStatus.ProviderPaymentID, pkg.StringPtr("Payment confirmed by provider at expiry"))
+		return err
+	})
+}
+
 // Helper function to validate currency
 func (s *TransactionService) validateCurrency(currency string) error {
 	if !supportedCurrencies[currency] {
@@ -825,7 +1006,7 @@ func (s *TransactionService) ProcessGiftIn(accountId string, amountGsaltUnits in
 	err = s.db.Transaction(func(tx *gorm.DB) error {
 		// Lock and verify account with SELECT FOR UPDATE
 		var account models.Account
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("connect_id = ?", accountUUID).First(&account).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("connect_id = ?", accountUUID).First(&account).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				return errors.NewNotFoundError("Account not found")
 			}
@@ -849,10 +1030,15 @@ func (s *TransactionService) ProcessGiftIn(accountId string, amountGsaltUnits in
 			return errors.NewInternalServerError(err, "Failed to create gift transaction")
 		}
 
-		// Update account balance atomically (both in GSALT units)
-		account.Balance += amountGsaltUnits
-		if err := tx.Save(&account).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to update account balance")
+		// Credit the account through the ledger (in GSALT units)
+		entry, err := s.ledgerService.RecordPromoCredit(tx, accountUUID, amountGsaltUnits, transaction.ID.String(), &giftDescription)
+		if err != nil {
+			return err
+		}
+
+		transaction.JournalEntryID = &entry.ID
+		if err := tx.Save(transaction).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update gift transaction")
 		}
 
 		return nil
@@ -887,26 +1073,17 @@ func (s *TransactionService) ProcessGiftOut(sourceAccountId, destAccountId strin
 	now := time.Now()
 
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Lock and verify source account with SELECT FOR UPDATE
-		var sourceAccount models.Account
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("connect_id = ?", sourceUUID).First(&sourceAccount).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.NewNotFoundError("Source account not found")
-			}
-			return errors.NewInternalServerError(err, "Failed to get source account")
+		// Lock both accounts
+		sourceAccount, destAccount, err := s.lockAccountPair(tx, sourceUUID, destUUID)
+		if err != nil {
+			return err
 		}
-
-		// Lock and verify destination account with SELECT FOR UPDATE
-		var destAccount models.Account
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("connect_id = ?", destUUID).First(&destAccount).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.NewNotFoundError("Destination account not found")
-			}
-			return errors.NewInternalServerError(err, "Failed to get destination account")
+		if destAccount.Status == models.AccountStatusClosing {
+			return errors.NewBadRequestError("Destination account is being closed [" + ErrCodeAccountClosing + "]")
 		}
 
 		// Check sufficient balance
-		if !s.hasSufficientBalance(sourceAccount.Balance, amountGsaltUnits) {
+		if !s.hasSufficientBalance(sourceAccount.AvailableBalance, amountGsaltUnits) {
 			return errors.NewBadRequestError("Insufficient balance [" + ErrCodeInsufficientBalance + "]")
 		}
 
@@ -929,22 +1106,22 @@ func (s *TransactionService) ProcessGiftOut(sourceAccountId, destAccountId strin
 			return errors.NewInternalServerError(err, "Failed to create gift in transaction")
 		}
 
-		// Update related transaction ID
+		// Move the balance through the ledger (all in GSALT units)
+		entry, err := s.ledgerService.RecordWalletTransfer(tx, sourceUUID, destUUID, amountGsaltUnits, giftOut.ID.String(), description)
+		if err != nil {
+			return err
+		}
+
+		// Update related transaction ID and journal entry
 		giftOut.RelatedTransactionID = &giftIn.ID
+		giftOut.JournalEntryID = &entry.ID
 		if err := tx.Save(giftOut).Error; err != nil {
 			return errors.NewInternalServerError(err, "Failed to update gift out transaction")
 		}
 
-		// Update account balances atomically (all in GSALT units)
-		sourceAccount.Balance -= amountGsaltUnits
-		destAccount.Balance += amountGsaltUnits
-
-		if err := tx.Save(&sourceAccount).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to update source account balance")
-		}
-
-		if err := tx.Save(&destAccount).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to update destination account balance")
+		giftIn.JournalEntryID = &entry.ID
+		if err := tx.Save(giftIn).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to update gift in transaction")
 		}
 
 		return nil
@@ -974,27 +1151,24 @@ func (s *TransactionService) ProcessWithdrawal(accountId string, amountGsaltUnit
 		return nil, errors.NewInternalServerError(err, "Failed to get account")
 	}
 
-	// Check if account has sufficient balance
-	if !s.hasSufficientBalance(account.Balance, amountGsaltUnits) {
+	// Check if account has sufficient available balance (re-checked atomically when the hold is placed)
+	if !s.hasSufficientBalance(account.AvailableBalance, amountGsaltUnits) {
 		return nil, errors.NewBadRequestError("Insufficient balance [" + ErrCodeInsufficientBalance + "]")
 	}
 
-	// Validate transaction amount
-	if err := s.validateTransactionAmount(models.TransactionTypeWithdrawal, amountGsaltUnits); err != nil {
-		return nil, errors.NewBadRequestError(err.Error())
-	}
-
-	// Check daily limits
-	if err := s.checkDailyLimits(accountUUID, models.TransactionTypeWithdrawal, amountGsaltUnits); err != nil {
+	// Check the amount and the daily and monthly limits
+	if err := s.checkTransactionLimits(&account, models.TransactionTypeWithdrawal, amountGsaltUnits); err != nil {
 		return nil, err
 	}
 
 	// Validate bank account first
 	ctx := context.Background()
-	bankInquiry, err := s.flipService.BankAccountInquiry(ctx, models.BankAccountInquiryRequest{
-		AccountNumber: accountNumber,
-		BankCode:      bankCode,
-	})
+	provider, err := s.providerRegistry.Get(models.PaymentProviderFlip)
+	if err != nil {
+		return nil, err
+	}
+
+	bankInquiry, err := provider.InquireBankAccount(ctx, bankCode, accountNumber)
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate bank account: %w", err)
 	}
@@ -1013,71 +1187,191 @@ func (s *TransactionService) ProcessWithdrawal(accountId string, amountGsaltUnit
 		return nil, errors.NewBadRequestError("Disbursement service is currently unavailable")
 	}
 
-	var transaction *models.Transaction
+	withdrawalDesc := fmt.Sprintf("Withdrawal %d GSALT to %s (%s)", amountGsaltUnits/100, bankCode, accountNumber)
+	if description != nil {
+		withdrawalDesc = *description
+	}
 
-	// Execute withdrawal in database transaction
+	transaction := s.createBaseTransaction(accountUUID, models.TransactionTypeWithdrawal, amountGsaltUnits, models.TransactionStatusPending, &withdrawalDesc)
+	if externalRefId != nil {
+		transaction.ExternalReferenceID = externalRefId
+	}
+
+	// Commit the PENDING withdrawal and its hold before any money moves. The payment details are
+	// created without a payout ID; ReconcilePayouts settles withdrawals left in that state.
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Create withdrawal transaction using helper function
-		withdrawalDesc := fmt.Sprintf("Withdrawal %d GSALT to %s (%s)", amountGsaltUnits/100, bankCode, accountNumber)
-		if description != nil {
-			withdrawalDesc = *description
+		if err := tx.Create(transaction).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to create withdrawal transaction")
 		}
 
-		transaction = s.createBaseTransaction(accountUUID, models.TransactionTypeWithdrawal, amountGsaltUnits, models.TransactionStatusPending, &withdrawalDesc)
-		if externalRefId != nil {
-			transaction.ExternalReferenceID = externalRefId
+		// Hold the amount until the disbursement settles; the ledger is only debited on completion
+		if _, err := s.ledgerService.PlaceHold(tx, accountUUID, amountGsaltUnits, transaction.ID.String(), &transaction.ID, &withdrawalDesc); err != nil {
+			return err
 		}
 
-		if err := tx.Create(transaction).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to create withdrawal transaction")
+		paymentDetails := &models.PaymentDetails{
+			ID:            uuid.New(),
+			TransactionID: transaction.ID,
+			Provider:      provider.Code(),
+			CreatedAt:     time.Now(),
+			UpdatedAt:     time.Now(),
+		}
+		if err := tx.Create(paymentDetails).Error; err != nil {
+			return errors.NewInternalServerError(err, "Failed to create payment details")
 		}
+		transaction.PaymentDetails = paymentDetails
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-		// Deduct balance immediately (will be reversed if disbursement fails)
-		if err := s.updateAccountBalance(tx, accountUUID, -amountGsaltUnits); err != nil {
+	// Create the payout outside any database transaction. The transaction ID is the provider
+	// idempotency key, so the payout can be looked up or retried without sending money twice.
+	transactionIDStr := transaction.ID.String()
+	payoutResp, err := provider.CreatePayout(ctx, models.ProviderPayoutRequest{
+		IdempotencyKey: transactionIDStr,
+		BankCode:       bankCode,
+		AccountNumber:  accountNumber,
+		Amount:         s.ConvertGSALTToIDR(amountGsaltUnits),
+		Remark:         fmt.Sprintf("GSALT Withdrawal - %s", transactionIDStr),
+	})
+	if err != nil {
+		// The payout may still have been created; the withdrawal stays on hold until reconciled
+		logrus.WithField("transaction_id", transaction.ID).WithError(err).Warn("Failed to create disbursement")
+		return nil, fmt.Errorf("failed to create disbursement: %w", err)
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		updated, err := s.recordPayout(tx, transaction.ID, payoutResp)
+		if err != nil {
 			return err
 		}
+		transaction = updated
+		return nil
+	})
+	if err != nil {
+		// The payout exists; ReconcilePayouts records it from the provider
+		logrus.WithFields(logrus.Fields{
+			"transaction_id": transaction.ID,
+			"payout_id":      payoutResp.ProviderPayoutID,
+		}).WithError(err).Warn("Failed to record disbursement")
+		return nil, err
+	}
 
-		// Convert GSALT to IDR for disbursement
-		amountIDR := s.ConvertGSALTToIDR(amountGsaltUnits)
+	var paymentDetails models.PaymentDetails
+	if err := s.db.Where("transaction_id = ?", transaction.ID).First(&paymentDetails).Error; err == nil {
+		transaction.PaymentDetails = &paymentDetails
+	}
 
-		// Create disbursement request
-		transactionIDStr := transaction.ID.String()
-		disbursementReq := models.DisbursementRequest{
-			AccountNumber:  accountNumber,
-			BankCode:       bankCode,
-			Amount:         amountIDR,
-			Remark:         fmt.Sprintf("GSALT Withdrawal - %s", transactionIDStr),
-			IdempotencyKey: transactionIDStr,
-			Timestamp:      time.Now().Format(time.RFC3339),
-		}
+	return transaction, nil
+}
 
-		// Create disbursement via Flip
-		disbursementResp, err := s.flipService.CreateDisbursement(ctx, disbursementReq)
-		if err != nil {
-			// If disbursement fails, we need to reverse the balance deduction
-			reverseTx := s.db.Begin()
-			s.updateAccountBalance(reverseTx, accountUUID, amountGsaltUnits)
-			reverseTx.Commit()
+// recordPayout stores the provider payout ID on a withdrawal's payment details and applies the
+// payout status. The disbursement callback may have recorded the payout first, so repeating
+// this is harmless.
+func (s *TransactionService) recordPayout(tx *gorm.DB, transactionID uuid.UUID, payout *models.ProviderPayoutResponse) (*models.Transaction, error) {
+	if err := s.attachPayout(tx, transactionID, payout); err != nil {
+		return nil, err
+	}
 
-			return fmt.Errorf("failed to create disbursement: %w", err)
-		}
+	var transaction models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transactionID).First(&transaction).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to get transaction")
+	}
+	if payout.Status == models.PaymentStatusPending || !s.isWithdrawalOpen(transaction.Status) {
+		return &transaction, nil
+	}
 
-		// Update transaction with disbursement ID
-		disbursementID := fmt.Sprintf("%d", disbursementResp.ID)
-		transaction.PaymentDetails.ProviderPaymentID = &disbursementID
+	return s.SettlePayout(tx, transactionID, payout)
+}
 
-		if err := tx.Save(transaction).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to update transaction with disbursement ID")
+// attachPayout sets the payout ID of a withdrawal whose payment details do not have one yet
+func (s *TransactionService) attachPayout(tx *gorm.DB, transactionID uuid.UUID, payout *models.ProviderPayoutResponse) error {
+	if err := tx.Model(&models.PaymentDetails{}).
+		Where("transaction_id = ? AND provider_payment_id IS NULL", transactionID).
+		Updates(models.PaymentDetails{
+			ProviderPaymentID:   &payout.ProviderPayoutID,
+			ProviderFeeAmount:   &payout.Fee,
+			RawProviderResponse: payout.RawResponse,
+		}).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to record disbursement ID")
+	}
+	return nil
+}
+
+// ReconcilePayouts resolves withdrawals whose payout was requested but never recorded, because
+// the provider call failed or the process stopped before the payout ID was stored. The payout is
+// looked up by its idempotency key: a payout found is recorded and its status applied, and a
+// withdrawal the provider has no payout for is failed and its hold released.
+func (s *TransactionService) ReconcilePayouts(ctx context.Context) error {
+	var transactionIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).
+		Model(&models.Transaction{}).
+		Joins("JOIN payment_details ON payment_details.transaction_id = transactions.id").
+		Where("transactions.type = ? AND transactions.status = ? AND payment_details.provider_payment_id IS NULL AND transactions.created_at < ?",
+			models.TransactionTypeWithdrawal, models.TransactionStatusPending, time.Now().Add(-payoutReconcileDelay)).
+		Order("transactions.created_at").
+		Limit(payoutReconcileBatchSize).
+		Pluck("transactions.id", &transactionIDs).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to get unrecorded payouts")
+	}
+
+	reconciled := 0
+	for _, transactionID := range transactionIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
 
-		return nil
-	})
+		if err := s.reconcilePayout(ctx, transactionID); err != nil {
+			logrus.WithField("transaction_id", transactionID).WithError(err).Warn("Failed to reconcile payout")
+			continue
+		}
+		reconciled++
+	}
+
+	if len(transactionIDs) > 0 {
+		logrus.WithFields(logrus.Fields{
+			"candidates": len(transactionIDs),
+			"reconciled": reconciled,
+		}).Info("Reconciled payouts")
+	}
+
+	return nil
+}
 
+// reconcilePayout looks up the payout of one withdrawal at its provider and records the outcome
+func (s *TransactionService) reconcilePayout(ctx context.Context, transactionID uuid.UUID) error {
+	var paymentDetails models.PaymentDetails
+	if err := s.db.WithContext(ctx).Where("transaction_id = ?", transactionID).First(&paymentDetails).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to get payment details")
+	}
+
+	provider, err := s.providerRegistry.Get(paymentDetails.Provider)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return transaction, nil
+	payout, err := provider.FindPayout(ctx, transactionID.String())
+	if err != nil {
+		return fmt.Errorf("failed to find payout: %w", err)
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if payout != nil {
+			_, err := s.recordPayout(tx, transactionID, payout)
+			return err
+		}
+
+		// The provider never created the payout, so no money left the platform
+		_, err := s.SettlePayout(tx, transactionID, &models.ProviderPayoutResponse{
+			Status:         models.PaymentStatusFailed,
+			ProviderStatus: "NOT_CREATED",
+			Reason:         pkg.StringPtr(fmt.Sprintf("Disbursement was never created at %s", provider.Code())),
+		})
+		return err
+	})
 }
 
 // GetSupportedBanksForWithdrawal retrieves supported banks for withdrawal
@@ -1104,34 +1398,20 @@ func (s *TransactionService) ValidateBankAccountForWithdrawal(ctx context.Contex
 	return s.ValidateBankAccountForFlip(ctx, accountNumber, bankCode)
 }
 
-// GetWithdrawalBalance gets available balance for withdrawal (considering pending withdrawals)
+// GetWithdrawalBalance gets the balance available for withdrawal.
+// Pending withdrawals are already reserved by balance holds, so this is the available balance.
 func (s *TransactionService) GetWithdrawalBalance(accountId string) (int64, error) {
 	accountUUID, err := s.parseUUID(accountId, "account ID")
 	if err != nil {
 		return 0, fmt.Errorf("invalid account ID: %w", err)
 	}
 
-	// Get account balance
 	var account models.Account
 	if err := s.db.First(&account, "connect_id = ?", accountUUID).Error; err != nil {
 		return 0, fmt.Errorf("account not found: %w", err)
 	}
 
-	// Calculate pending withdrawal amount
-	var pendingWithdrawals int64
-	s.db.Model(&models.Transaction{}).
-		Where("account_id = ? AND type = ? AND status IN (?)",
-			accountUUID, models.TransactionTypeWithdrawal,
-			[]models.TransactionStatus{models.TransactionStatusPending, models.TransactionStatusProcessing}).
-		Select("COALESCE(SUM(amount_gsalt_units), 0)").Scan(&pendingWithdrawals)
-
-	// Available balance = total balance - pending withdrawals
-	availableBalance := account.Balance - pendingWithdrawals
-	if availableBalance < 0 {
-		availableBalance = 0
-	}
-
-	return availableBalance, nil
+	return account.AvailableBalance, nil
 }
 
 // getPaymentInstructions returns payment instructions based on payment method
@@ -1201,7 +1481,8 @@ func (s *TransactionService) GetSupportedBanksForFlip(ctx context.Context) ([]mo
 	return s.flipService.GetBanks(ctx)
 }
 
-// CheckWithdrawalStatus checks the status of a withdrawal transaction
+// CheckWithdrawalStatus checks the status of a withdrawal transaction.
+// The payout is polled from the provider and applied when it has moved on.
 func (s *TransactionService) CheckWithdrawalStatus(transactionId string) (*models.WithdrawalResponse, error) {
 	// Get transaction
 	transaction, err := s.GetTransaction(transactionId)
@@ -1213,102 +1494,210 @@ func (s *TransactionService) CheckWithdrawalStatus(transactionId string) (*model
 		return nil, errors.NewBadRequestError("Transaction is not a withdrawal")
 	}
 
-	// Get disbursement status from Flip if we have external payment ID
-	var disbursementStatus *models.DisbursementResponse
-	if transaction.PaymentDetails.ProviderPaymentID != nil {
-		ctx := context.Background()
-		disbursementStatus, err = s.flipService.GetDisbursementByID(ctx, *transaction.PaymentDetails.ProviderPaymentID)
-		if err != nil {
-			// Log error but don't fail the request
-			fmt.Printf("Failed to get disbursement status: %v\n", err)
-		}
-	}
-
 	response := &models.WithdrawalResponse{
 		Transaction:   transaction,
 		Status:        string(transaction.Status),
 		EstimatedTime: "1-3 business days",
 	}
 
-	if disbursementStatus != nil {
-		disbursementIDStr := fmt.Sprintf("%d", disbursementStatus.ID)
-		response.DisbursementID = &disbursementIDStr
+	var paymentDetails models.PaymentDetails
+	if err := s.db.Where("transaction_id = ?", transaction.ID).First(&paymentDetails).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return response, nil
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get payment details")
 	}
+	transaction.PaymentDetails = &paymentDetails
 
-	return response, nil
-}
+	if paymentDetails.ProviderPaymentID == nil {
+		return response, nil
+	}
+	response.DisbursementID = paymentDetails.ProviderPaymentID
 
-// ConfirmPayment confirms a payment transaction
-func (s *TransactionService) ConfirmPayment(transactionId string, externalPaymentId *string) (*models.Transaction, error) {
-	transactionUUID, err := s.parseUUID(transactionId, "transaction ID")
+	if !s.isWithdrawalOpen(transaction.Status) {
+		return response, nil
+	}
+
+	// Poll the provider; a failure here must not hide the stored status
+	provider, err := s.providerRegistry.Get(paymentDetails.Provider)
 	if err != nil {
 		return nil, err
 	}
 
-	var transaction *models.Transaction
-	now := time.Now()
+	payout, err := provider.GetPayoutStatus(context.Background(), *paymentDetails.ProviderPaymentID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"transaction_id": transaction.ID,
+			"payout_id":      *paymentDetails.ProviderPaymentID,
+		}).WithError(err).Warn("Failed to get payout status")
+		return response, nil
+	}
 
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Lock and get the transaction
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", transactionUUID).First(&transaction).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.NewNotFoundError("Transaction not found")
-			}
-			return errors.NewInternalServerError(err, "Failed to get transaction")
+		transaction, err = s.SettlePayout(tx, transaction.ID, payout)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Where("transaction_id = ?", transaction.ID).First(&paymentDetails).Error; err == nil {
+		transaction.PaymentDetails = &paymentDetails
+	}
+	response.Transaction = transaction
+	response.Status = string(transaction.Status)
+
+	return response, nil
+}
+
+// SettlePayout applies a provider payout status to a withdrawal within tx.
+// PROCESSING moves the withdrawal to PROCESSING, COMPLETED captures the balance hold, debits the
+// ledger and stores the receipt, CANCELLED and FAILED release the hold so the amount is available again.
+// Statuses that would move the withdrawal backwards are ignored.
+func (s *TransactionService) SettlePayout(tx *gorm.DB, transactionID uuid.UUID, payout *models.ProviderPayoutResponse) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transactionID).First(&transaction).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Transaction not found")
 		}
+		return nil, errors.NewInternalServerError(err, "Failed to get transaction")
+	}
+
+	if transaction.Type != models.TransactionTypeWithdrawal {
+		return nil, errors.NewBadRequestError("Transaction is not a withdrawal")
+	}
+
+	if !s.isWithdrawalOpen(transaction.Status) {
+		return nil, errors.NewBadRequestError("Withdrawal is already " + string(transaction.Status) + " [" + ErrCodeInvalidStatusTransition + "]")
+	}
+
+	now := time.Now()
+	description := payout.Reason
+	if description == nil {
+		description = pkg.StringPtr(fmt.Sprintf("Disbursement %s", payout.ProviderStatus))
+	}
 
-		// Validate transaction can be confirmed
+	switch payout.Status {
+	case models.PaymentStatusPending:
 		if transaction.Status != models.TransactionStatusPending {
-			return errors.NewBadRequestError("Only pending transactions can be confirmed [" + ErrCodeInvalidStatusTransition + "]")
+			return &transaction, nil
 		}
 
-		if transaction.Type != models.TransactionTypeTopup {
-			return errors.NewBadRequestError("Only topup transactions can be confirmed")
+	case models.PaymentStatusProcessing:
+		if transaction.Status == models.TransactionStatusProcessing {
+			return &transaction, nil
 		}
+		transaction.Status = models.TransactionStatusProcessing
 
-		// Lock and get the account
-		var account models.Account
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("connect_id = ?", transaction.AccountID).First(&account).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.NewNotFoundError("Account not found")
+	case models.PaymentStatusCompleted:
+		hold, err := s.ledgerService.GetTransactionHold(tx, transaction.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hold != nil && hold.Status == models.BalanceHoldStatusActive {
+			if _, err := s.ledgerService.CaptureHold(tx, hold.ID, hold.Amount); err != nil {
+				return nil, err
 			}
-			return errors.NewInternalServerError(err, "Failed to get account")
+			entry, err := s.ledgerService.RecordWithdrawal(tx, transaction.AccountID, s.transactionProviderCode(tx, transaction.ID), hold.Amount, 0, transaction.ID.String(), transaction.Description)
+			if err != nil {
+				return nil, err
+			}
+			transaction.JournalEntryID = &entry.ID
 		}
 
-		// Update transaction status
 		transaction.Status = models.TransactionStatusCompleted
 		transaction.CompletedAt = &now
-		if externalPaymentId != nil {
-			if transaction.ExternalReferenceID == nil {
-				transaction.ExternalReferenceID = externalPaymentId
+		transaction.PaymentCompletedAt = &now
+
+	case models.PaymentStatusCancelled, models.PaymentStatusFailed:
+		hold, err := s.ledgerService.GetTransactionHold(tx, transaction.ID)
+		if err != nil {
+			return nil, err
+		}
+		if hold != nil && hold.Status == models.BalanceHoldStatusActive {
+			if _, err := s.ledgerService.ReleaseHold(tx, hold.ID); err != nil {
+				return nil, err
+			}
+		} else if transaction.JournalEntryID != nil {
+			// Withdrawals created before balance holds were debited up front
+			refundDesc := fmt.Sprintf("Refund of withdrawal %s", transaction.ID)
+			if _, err := s.ledgerService.ReverseJournalEntry(tx, *transaction.JournalEntryID, transaction.ID.String()+":refund", &refundDesc); err != nil {
+				return nil, err
 			}
 		}
 
-		if err := tx.Save(transaction).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to update transaction")
+		transaction.Status = models.TransactionStatusCancelled
+		if payout.Status == models.PaymentStatusFailed {
+			transaction.Status = models.TransactionStatusFailed
 		}
+		transaction.PaymentFailedAt = &now
 
-		// Update account balance
-		account.Balance += transaction.AmountGsaltUnits
-		if err := tx.Save(&account).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to update account balance")
-		}
+	default:
+		return nil, errors.NewBadRequestError("Unsupported payout status " + string(payout.Status))
+	}
 
-		// Update payment status
-		statusUpdateReq := &models.PaymentStatusUpdateRequest{
-			Status:            models.PaymentStatusCompleted,
-			StatusDescription: pkg.StringPtr("Payment confirmed"),
-			PaymentTime:       &now,
-		}
-		if externalPaymentId != nil {
-			statusUpdateReq.ProviderPaymentID = externalPaymentId
-		}
+	transaction.PaymentStatus = payout.Status
+	transaction.PaymentStatusDescription = description
 
-		if err := s.paymentService.UpdatePaymentStatus(context.Background(), transaction.ID, statusUpdateReq); err != nil {
-			return err
+	if err := tx.Save(&transaction).Error; err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to update transaction")
+	}
+
+	statusUpdateReq := &models.PaymentStatusUpdateRequest{
+		Status:            payout.Status,
+		StatusDescription: description,
+		ReceiptURL:        payout.ReceiptURL,
+	}
+	if payout.Status == models.PaymentStatusCompleted {
+		statusUpdateReq.PaymentTime = &now
+	}
+	if err := s.paymentService.UpdatePaymentStatusTx(tx, transaction.ID, statusUpdateReq); err != nil {
+		return nil, err
+	}
+
+	var eventType models.WebhookEventType
+	switch transaction.Status {
+	case models.TransactionStatusCompleted:
+		eventType = models.WebhookEventWithdrawalCompleted
+	case models.TransactionStatusCancelled, models.TransactionStatusFailed:
+		eventType = models.WebhookEventWithdrawalFailed
+	}
+	if eventType != "" {
+		if err := s.webhookService.Enqueue(tx, transaction.AccountID, eventType, map[string]interface{}{
+			"transaction": transaction,
+		}); err != nil {
+			return nil, err
 		}
+	}
 
-		return nil
+	return &transaction, nil
+}
+
+// transactionProviderCode resolves the provider that handled a transaction, defaulting to Flip
+func (s *TransactionService) transactionProviderCode(tx *gorm.DB, transactionID uuid.UUID) string {
+	var paymentDetails models.PaymentDetails
+	if err := tx.Where("transaction_id = ?", transactionID).First(&paymentDetails).Error; err == nil {
+		return paymentDetails.Provider
+	}
+	return models.PaymentProviderFlip
+}
+
+// isWithdrawalOpen reports whether a withdrawal can still receive payout updates
+func (s *TransactionService) isWithdrawalOpen(status models.TransactionStatus) bool {
+	return status == models.TransactionStatusPending || status == models.TransactionStatusProcessing
+}
+
+// ConfirmPayment confirms a payment transaction
+func (s *TransactionService) ConfirmPayment(transactionId string, externalPaymentId *string) (*models.Transaction, error) {
+	transactionUUID, err := s.parseUUID(transactionId, "transaction ID")
+	if err != nil {
+		return nil, err
+	}
+
+	var transaction *models.Transaction
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		transaction, err = s.SettlePayment(tx, transactionUUID, models.PaymentStatusCompleted, externalPaymentId, pkg.StringPtr("Payment confirmed"))
+		return err
 	})
 
 	if err != nil {
@@ -1325,55 +1714,167 @@ func (s *TransactionService) RejectPayment(transactionId string, reason *string)
 		return nil, err
 	}
 
-	var transaction models.Transaction
-
+	var transaction *models.Transaction
 	err = s.db.Transaction(func(tx *gorm.DB) error {
-		// Lock and get the transaction
-		if err := tx.Set("gorm:query_option", "FOR UPDATE").Where("id = ?", transactionUUID).First(&transaction).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				return errors.NewNotFoundError("Transaction not found")
-			}
-			return errors.NewInternalServerError(err, "Failed to get transaction")
+		transaction, err = s.SettlePayment(tx, transactionUUID, models.PaymentStatusFailed, nil, reason)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return transaction, nil
+}
+
+// SettlePayment applies a final payment outcome to a pending topup within tx.
+// COMPLETED credits the account through the ledger; FAILED, EXPIRED and CANCELLED close
+// the transaction without moving balance. The transaction row is locked for the duration of tx.
+func (s *TransactionService) SettlePayment(tx *gorm.DB, transactionID uuid.UUID, paymentStatus models.PaymentStatus, providerPaymentID *string, reason *string) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transactionID).First(&transaction).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Transaction not found")
 		}
+		return nil, errors.NewInternalServerError(err, "Failed to get transaction")
+	}
 
-		// Validate transaction can be rejected
-		if transaction.Status != models.TransactionStatusPending {
-			return errors.NewBadRequestError("Only pending transactions can be rejected [" + ErrCodeInvalidStatusTransition + "]")
+	if transaction.Status != models.TransactionStatusPending {
+		return nil, errors.NewBadRequestError("Only pending transactions can be settled [" + ErrCodeInvalidStatusTransition + "]")
+	}
+
+	now := time.Now()
+	transaction.PaymentStatus = paymentStatus
+	transaction.PaymentStatusDescription = reason
+
+	switch paymentStatus {
+	case models.PaymentStatusCompleted:
+		if err := s.completeTopup(tx, &transaction, providerPaymentID, now); err != nil {
+			return nil, err
 		}
 
-		// Update transaction status
+	case models.PaymentStatusFailed:
 		transaction.Status = models.TransactionStatusFailed
+		transaction.PaymentFailedAt = &now
 		if reason != nil && *reason != "" {
 			failureReason := fmt.Sprintf("Payment rejected: %s", *reason)
 			transaction.Description = &failureReason
 		}
 
-		if err := tx.Save(&transaction).Error; err != nil {
-			return errors.NewInternalServerError(err, "Failed to update transaction")
-		}
+	case models.PaymentStatusExpired:
+		transaction.Status = models.TransactionStatusCancelled
+		transaction.PaymentExpiredAt = &now
 
-		// Update payment status
-		now := time.Now()
-		statusUpdateReq := &models.PaymentStatusUpdateRequest{
-			Status:            models.PaymentStatusFailed,
-			StatusDescription: reason,
-			PaymentTime:       &now,
-		}
+	case models.PaymentStatusCancelled:
+		transaction.Status = models.TransactionStatusCancelled
+		transaction.PaymentFailedAt = &now
 
-		if err := s.paymentService.UpdatePaymentStatus(context.Background(), transaction.ID, statusUpdateReq); err != nil {
-			return err
+	default:
+		return nil, errors.NewBadRequestError("Payment status " + string(paymentStatus) + " is not final [" + ErrCodeInvalidStatusTransition + "]")
+	}
+
+	if err := s.saveSettlement(tx, &transaction, paymentStatus, providerPaymentID, reason, now); err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+// SettleLatePayment completes a topup the provider reports as paid after it was expired or
+// cancelled here, e.g. when the payment lands while the expiry job cancels the charge. The
+// customer has paid, so the account is credited rather than the payment being dropped.
+func (s *TransactionService) SettleLatePayment(tx *gorm.DB, transactionID uuid.UUID, providerPaymentID *string, reason *string) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", transactionID).First(&transaction).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, errors.NewNotFoundError("Transaction not found")
 		}
+		return nil, errors.NewInternalServerError(err, "Failed to get transaction")
+	}
 
-		return nil
-	})
+	if !isLatePayable(&transaction) {
+		return nil, errors.NewBadRequestError("Only expired or cancelled topups can be settled late [" + ErrCodeInvalidStatusTransition + "]")
+	}
 
-	if err != nil {
+	now := time.Now()
+	transaction.PaymentStatus = models.PaymentStatusCompleted
+	transaction.PaymentStatusDescription = reason
+	if err := s.completeTopup(tx, &transaction, providerPaymentID, now); err != nil {
 		return nil, err
 	}
 
+	if err := s.saveSettlement(tx, &transaction, models.PaymentStatusCompleted, providerPaymentID, reason, now); err != nil {
+		return nil, err
+	}
+
+	logrus.WithField("transaction_id", transaction.ID).Warn("Settled payment received after the topup was cancelled")
 	return &transaction, nil
 }
 
+// isLatePayable reports whether a transaction is a topup cancelled for lack of payment
+func isLatePayable(transaction *models.Transaction) bool {
+	return transaction.Type == models.TransactionTypeTopup &&
+		transaction.Status == models.TransactionStatusCancelled &&
+		(transaction.PaymentStatus == models.PaymentStatusExpired || transaction.PaymentStatus == models.PaymentStatusCancelled)
+}
+
+// completeTopup credits a paid topup through the ledger and marks it completed
+func (s *TransactionService) completeTopup(tx *gorm.DB, transaction *models.Transaction, providerPaymentID *string, now time.Time) error {
+	if transaction.Type != models.TransactionTypeTopup {
+		return errors.NewBadRequestError("Only topup transactions can be confirmed")
+	}
+
+	// Resolve the provider that collected the funds
+	providerCode := models.PaymentProviderFlip
+	var paymentDetails models.PaymentDetails
+	if err := tx.Where("transaction_id = ?", transaction.ID).First(&paymentDetails).Error; err == nil {
+		providerCode = paymentDetails.Provider
+	} else if err != gorm.ErrRecordNotFound {
+		return errors.NewInternalServerError(err, "Failed to get payment details")
+	}
+
+	// Credit the account through the ledger
+	entry, err := s.ledgerService.RecordTopup(tx, transaction.AccountID, providerCode, transaction.AmountGsaltUnits, transaction.FeeGsaltUnits, transaction.ID.String(), transaction.Description)
+	if err != nil {
+		return err
+	}
+
+	transaction.JournalEntryID = &entry.ID
+	transaction.Status = models.TransactionStatusCompleted
+	transaction.CompletedAt = &now
+	transaction.PaymentCompletedAt = &now
+	if providerPaymentID != nil && transaction.ExternalReferenceID == nil {
+		transaction.ExternalReferenceID = providerPaymentID
+	}
+	return nil
+}
+
+// saveSettlement stores a settled transaction and mirrors the outcome to its payment details
+func (s *TransactionService) saveSettlement(tx *gorm.DB, transaction *models.Transaction, paymentStatus models.PaymentStatus, providerPaymentID *string, reason *string, now time.Time) error {
+	if err := tx.Save(transaction).Error; err != nil {
+		return errors.NewInternalServerError(err, "Failed to update transaction")
+	}
+
+	// Update payment status
+	statusUpdateReq := &models.PaymentStatusUpdateRequest{
+		Status:            paymentStatus,
+		StatusDescription: reason,
+		ProviderPaymentID: providerPaymentID,
+	}
+	if paymentStatus == models.PaymentStatusCompleted {
+		statusUpdateReq.PaymentTime = &now
+	}
+	if err := s.paymentService.UpdatePaymentStatusTx(tx, transaction.ID, statusUpdateReq); err != nil {
+		// Manually created topups may have no payment details
+		var appErr *errors.AppError
+		if !stderrors.As(err, &appErr) || appErr.StatusCode != http.StatusNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // DeleteTransaction performs soft delete on a transaction
 func (s *TransactionService) DeleteTransaction(transactionId string) error {
 	transactionUUID, err := s.parseUUID(transactionId, "transaction ID")
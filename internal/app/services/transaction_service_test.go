@@ -0,0 +1,86 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestCountsTowardLimitsExcludesOnlyFailedAndCancelled(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+		Logger:               logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to open dry run database: %v", err)
+	}
+
+	var transactions []models.Transaction
+	stmt := db.Model(&models.Transaction{}).Scopes(countsTowardLimits).Find(&transactions).Statement
+
+	if sql := stmt.SQL.String(); !strings.Contains(sql, "status NOT IN") {
+		t.Fatalf("query %q does not exclude statuses", sql)
+	}
+
+	excluded := make(map[models.TransactionStatus]bool)
+	for _, v := range stmt.Vars {
+		switch v := v.(type) {
+		case models.TransactionStatus:
+			excluded[v] = true
+		case []models.TransactionStatus:
+			for _, status := range v {
+				excluded[status] = true
+			}
+		}
+	}
+
+	for _, status := range []models.TransactionStatus{
+		models.TransactionStatusPending,
+		models.TransactionStatusProcessing,
+		models.TransactionStatusCompleted,
+		models.TransactionStatusPartiallyRefunded,
+		models.TransactionStatusRefunded,
+		models.TransactionStatusReversed,
+	} {
+		if excluded[status] {
+			t.Errorf("%s transactions are left out of limit totals", status)
+		}
+	}
+	for _, status := range []models.TransactionStatus{models.TransactionStatusFailed, models.TransactionStatusCancelled} {
+		if !excluded[status] {
+			t.Errorf("%s transactions count toward limits", status)
+		}
+	}
+}
+
+func TestProcessingWithdrawalCountsTowardDailyLimit(t *testing.T) {
+	env := newFlipFlowEnv(t)
+	accountID := env.createAccount(t)
+	env.topup(t, accountID, 10000)
+
+	if err := env.db.Model(&models.Account{}).Where("connect_id = ?", accountID).Update("daily_limit", 5000).Error; err != nil {
+		t.Fatalf("failed to set daily limit: %v", err)
+	}
+
+	withdrawal, err := env.transactionService.ProcessWithdrawal(accountID.String(), 4000, "bca", "1234567890", "Flip Flow Test", nil, nil)
+	if err != nil {
+		t.Fatalf("ProcessWithdrawal: %v", err)
+	}
+	disbursementID := env.disbursementID(t, withdrawal.ID)
+	if _, err := env.sim.CompleteDisbursement(disbursementID, models.DisbursementStatusProcessed); err != nil {
+		t.Fatalf("CompleteDisbursement: %v", err)
+	}
+	if status := env.transaction(t, withdrawal.ID).Status; status != models.TransactionStatusProcessing {
+		t.Fatalf("withdrawal status = %s, want %s", status, models.TransactionStatusProcessing)
+	}
+
+	_, err = env.transactionService.ProcessWithdrawal(accountID.String(), 2000, "bca", "1234567890", "Flip Flow Test", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), ErrCodeDailyLimitExceeded) {
+		t.Fatalf("second withdrawal error = %v, want %s", err, ErrCodeDailyLimitExceeded)
+	}
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/safatanc/gsalt-core/internal/app/errors"
+	"github.com/safatanc/gsalt-core/internal/app/models"
+	"github.com/safatanc/gsalt-core/internal/infrastructures"
+	"gorm.io/gorm"
+)
+
+// Error codes for transfer recipients and QR payloads
+const (
+	ErrCodeInvalidRecipient  = "INVALID_RECIPIENT"
+	ErrCodeRecipientNotFound = "RECIPIENT_NOT_FOUND"
+	ErrCodeInvalidQR         = "INVALID_QR"
+	ErrCodeQRExpired         = "QR_EXPIRED"
+	ErrCodeQRAmountMismatch  = "QR_AMOUNT_MISMATCH"
+)
+
+const (
+	// transferQRVersion prefixes every QR payload so the format can change later
+	transferQRVersion = "GSALT1"
+	// defaultTransferQRExpiry applies to dynamic QRs created without expires_in_minutes
+	defaultTransferQRExpiry = 15 * time.Minute
+	// transferQRReferencePrefix marks the external reference of transfers paying a dynamic QR
+	transferQRReferencePrefix = "QR:"
+)
+
+// transferQRClaims is the signed body of a QR payload
+type transferQRClaims struct {
+	ID          *uuid.UUID            `json:"id,omitempty"`
+	Type        models.TransferQRType `json:"typ"`
+	AccountID   uuid.UUID             `json:"acc"`
+	Amount      *int64                `json:"amt,omitempty"`
+	Description *string               `json:"desc,omitempty"`
+	IssuedAt    int64                 `json:"iat"`
+	ExpiresAt   *int64                `json:"exp,omitempty"`
+}
+
+// resolvedRecipient is the destination of a transfer. User is only loaded when a preview needs
+// it or the lookup went through Connect.
+type resolvedRecipient struct {
+	Account *models.Account
+	User    *models.ConnectUser
+	QR      *models.TransferQR
+}
+
+// TransferRecipientService resolves who receives a transfer from an account ID, a Connect
+// username or email, or a signed QR payload, and issues those QR payloads.
+//
+// A QR payload is "GSALT1.<claims>.<signature>": base64url JSON claims and the base64url
+// HMAC-SHA256 of "GSALT1.<claims>" keyed with QR_SIGNING_SECRET.
+type TransferRecipientService struct {
+	db                 *gorm.DB
+	validator          *infrastructures.Validator
+	connectService     *ConnectService
+	transactionService *TransactionService
+}
+
+func NewTransferRecipientService(
+	db *gorm.DB,
+	validator *infrastructures.Validator,
+	connectService *ConnectService,
+	transactionService *TransactionService,
+) *TransferRecipientService {
+	return &TransferRecipientService{
+		db:                 db,
+		validator:          validator,
+		connectService:     connectService,
+		transactionService: transactionService,
+	}
+}
+
+// Preview returns the masked recipient a payer confirms before transferring
+func (s *TransferRecipientService) Preview(sourceAccountID uuid.UUID, req *models.TransferRecipient) (*models.RecipientPreview, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	recipient, err := s.resolve(sourceAccountID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	user := recipient.User
+	if user == nil {
+		user, err = s.connectService.GetUser(recipient.Account.ConnectID.String())
+		if err != nil {
+			return nil, errors.NewInternalServerError(err, "Failed to look up recipient")
+		}
+	}
+
+	preview := &models.RecipientPreview{
+		AccountID:  recipient.Account.ConnectID,
+		MaskedName: maskName(user),
+		QR:         recipient.QR,
+	}
+	if user.AvatarURL != "" {
+		preview.AvatarURL = &user.AvatarURL
+	}
+	return preview, nil
+}
+
+// Transfer resolves the recipient and sends the transfer. Paying a dynamic QR uses its amount
+// and description, and records the QR ID so it cannot be paid twice.
+func (s *TransferRecipientService) Transfer(sourceAccountID uuid.UUID, req *models.TransferRequest) (*models.Transaction, *models.Transaction, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, nil, err
+	}
+
+	recipient, err := s.resolve(sourceAccountID, &req.TransferRecipient)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	description := req.Description
+	var reference *string
+	var amountGsaltUnits int64
+
+	if recipient.QR != nil && recipient.QR.Type == models.TransferQRTypeDynamic {
+		amountGsaltUnits = *recipient.QR.AmountGsaltUnits
+		if req.AmountGsalt != "" {
+			units, err := gsaltToUnits(req.AmountGsalt, "amount_gsalt")
+			if err != nil {
+				return nil, nil, err
+			}
+			if units != amountGsaltUnits {
+				return nil, nil, errors.NewBadRequestError("Amount does not match the QR [" + ErrCodeQRAmountMismatch + "]")
+			}
+		}
+		if description == nil {
+			description = recipient.QR.Description
+		}
+		ref := transferQRReferencePrefix + recipient.QR.ID.String()
+		reference = &ref
+	} else {
+		if req.AmountGsalt == "" {
+			return nil, nil, errors.NewBadRequestError("amount_gsalt is required")
+		}
+		amountGsaltUnits, err = gsaltToUnits(req.AmountGsalt, "amount_gsalt")
+		if err != nil {
+			return nil, nil, err
+		}
+		if description == nil && recipient.QR != nil {
+			description = recipient.QR.Description
+		}
+	}
+
+	return s.transactionService.ProcessTransfer(
+		sourceAccountID.String(),
+		recipient.Account.ConnectID.String(),
+		amountGsaltUnits,
+		description,
+		reference,
+	)
+}
+
+// CreateQR issues a signed QR payload that pays the given account
+func (s *TransferRecipientService) CreateQR(accountID uuid.UUID, req *models.TransferQRRequest) (*models.TransferQR, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claims := transferQRClaims{
+		Type:        req.Type,
+		AccountID:   accountID,
+		Description: req.Description,
+		IssuedAt:    now.Unix(),
+	}
+
+	if req.Type == models.TransferQRTypeDynamic {
+		amount, err := gsaltToUnits(*req.AmountGsalt, "amount_gsalt")
+		if err != nil {
+			return nil, err
+		}
+		expiry := defaultTransferQRExpiry
+		if req.ExpiresInMinutes != nil {
+			expiry = time.Duration(*req.ExpiresInMinutes) * time.Minute
+		}
+		id := uuid.New()
+		expiresAt := now.Add(expiry).Unix()
+		claims.ID = &id
+		claims.Amount = &amount
+		claims.ExpiresAt = &expiresAt
+	} else if req.AmountGsalt != nil || req.ExpiresInMinutes != nil {
+		return nil, errors.NewBadRequestError("Static QRs cannot carry an amount or expiry [" + ErrCodeInvalidQR + "]")
+	}
+
+	body, err := json.Marshal(claims)
+	if err != nil {
+		return nil, errors.NewInternalServerError(err, "Failed to encode QR payload")
+	}
+	signed := transferQRVersion + "." + base64.RawURLEncoding.EncodeToString(body)
+
+	qr := transferQRFromClaims(&claims)
+	qr.Payload = signed + "." + signTransferQR(signed)
+	return qr, nil
+}
+
+// resolve finds the account behind exactly one recipient field
+func (s *TransferRecipientService) resolve(sourceAccountID uuid.UUID, req *models.TransferRecipient) (*resolvedRecipient, error) {
+	set := 0
+	for _, field := range []*string{req.DestinationAccountID, req.DestinationUsername, req.DestinationEmail, req.QRPayload} {
+		if field != nil && strings.TrimSpace(*field) != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, errors.NewBadRequestError("Provide exactly one of destination_account_id, destination_username, destination_email or qr_payload [" + ErrCodeInvalidRecipient + "]")
+	}
+
+	recipient := &resolvedRecipient{}
+	var accountID uuid.UUID
+	var err error
+
+	switch {
+	case req.DestinationAccountID != nil && *req.DestinationAccountID != "":
+		accountID, err = uuid.Parse(*req.DestinationAccountID)
+		if err != nil {
+			return nil, errors.NewBadRequestError("Invalid destination_account_id [" + ErrCodeInvalidRecipient + "]")
+		}
+	case req.QRPayload != nil && *req.QRPayload != "":
+		recipient.QR, err = parseTransferQR(strings.TrimSpace(*req.QRPayload))
+		if err != nil {
+			return nil, err
+		}
+		accountID = recipient.QR.AccountID
+	default:
+		if req.DestinationEmail != nil && *req.DestinationEmail != "" {
+			recipient.User, err = s.connectService.GetUserByEmail(strings.TrimSpace(*req.DestinationEmail))
+		} else {
+			recipient.User, err = s.connectService.GetUserByUsername(strings.TrimPrefix(strings.TrimSpace(*req.DestinationUsername), "@"))
+		}
+		if err != nil {
+			var appErr *errors.AppError
+			if stderrors.As(err, &appErr) && appErr.StatusCode == http.StatusNotFound {
+				return nil, errors.NewNotFoundError("Recipient not found [" + ErrCodeRecipientNotFound + "]")
+			}
+			return nil, errors.NewInternalServerError(err, "Failed to look up recipient")
+		}
+		accountID = recipient.User.ID
+	}
+
+	if accountID == sourceAccountID {
+		return nil, errors.NewBadRequestError("Cannot transfer to the same account [" + ErrCodeSelfTransfer + "]")
+	}
+
+	var account models.Account
+	if err := s.db.Where("connect_id = ?", accountID).First(&account).Error; err != nil {
+		if stderrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.NewNotFoundError("Recipient not found [" + ErrCodeRecipientNotFound + "]")
+		}
+		return nil, errors.NewInternalServerError(err, "Failed to get recipient account")
+	}
+	if account.Status == models.AccountStatusClosing {
+		return nil, errors.NewBadRequestError("Destination account is being closed [" + ErrCodeAccountClosing + "]")
+	}
+	recipient.Account = &account
+
+	return recipient, nil
+}
+
+// parseTransferQR verifies a QR payload's signature and expiry
+func parseTransferQR(payload string) (*models.TransferQR, error) {
+	invalid := errors.NewBadRequestError("Invalid QR payload [" + ErrCodeInvalidQR + "]")
+
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 || parts[0] != transferQRVersion {
+		return nil, invalid
+	}
+	signed := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(signTransferQR(signed)), []byte(parts[2])) {
+		return nil, invalid
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, invalid
+	}
+	var claims transferQRClaims
+	if err := json.Unmarshal(body, &claims); err != nil {
+		return nil, invalid
+	}
+
+	switch claims.Type {
+	case models.TransferQRTypeStatic:
+	case models.TransferQRTypeDynamic:
+		if claims.ID == nil || claims.Amount == nil || claims.ExpiresAt == nil {
+			return nil, invalid
+		}
+		if time.Now().Unix() > *claims.ExpiresAt {
+			return nil, errors.NewBadRequestError("QR has expired [" + ErrCodeQRExpired + "]")
+		}
+	default:
+		return nil, invalid
+	}
+
+	return transferQRFromClaims(&claims), nil
+}
+
+// signTransferQR returns the base64url HMAC-SHA256 of the signed part of a QR payload
+func signTransferQR(signed string) string {
+	mac := hmac.New(sha256.New, []byte(infrastructures.Config.QRSigningSecret))
+	mac.Write([]byte(signed))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func transferQRFromClaims(claims *transferQRClaims) *models.TransferQR {
+	qr := &models.TransferQR{
+		ID:               claims.ID,
+		Type:             claims.Type,
+		AccountID:        claims.AccountID,
+		AmountGsaltUnits: claims.Amount,
+		Description:      claims.Description,
+	}
+	if claims.ExpiresAt != nil {
+		expiresAt := time.Unix(*claims.ExpiresAt, 0)
+		qr.ExpiresAt = &expiresAt
+	}
+	return qr
+}
+
+// maskName keeps the first letter of each word of the user's name, e.g. "Budi Santoso" becomes
+// "B*** S******". The username is masked when the user has no name.
+func maskName(user *models.ConnectUser) string {
+	name := strings.TrimSpace(user.FullName)
+	if name == "" {
+		name = user.Username
+	}
+
+	words := strings.Fields(name)
+	if len(words) == 0 {
+		return "***"
+	}
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	}
+	return strings.Join(words, " ")
+}
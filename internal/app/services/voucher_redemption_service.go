@@ -16,15 +16,17 @@ type VoucherRedemptionService struct {
 	voucherService     *VoucherService
 	accountService     *AccountService
 	transactionService *TransactionService
+	ledgerService      *LedgerService
 }
 
-func NewVoucherRedemptionService(db *gorm.DB, validator *infrastructures.Validator, voucherService *VoucherService, accountService *AccountService, transactionService *TransactionService) *VoucherRedemptionService {
+func NewVoucherRedemptionService(db *gorm.DB, validator *infrastructures.Validator, voucherService *VoucherService, accountService *AccountService, transactionService *TransactionService, ledgerService *LedgerService) *VoucherRedemptionService {
 	return &VoucherRedemptionService{
 		db:                 db,
 		validator:          validator,
 		voucherService:     voucherService,
 		accountService:     accountService,
 		transactionService: transactionService,
+		ledgerService:      ledgerService,
 	}
 }
 
@@ -273,13 +275,6 @@ func (s *VoucherRedemptionService) RedeemVoucher(accountId, voucherCode string)
 			return nil, nil, errors.NewBadRequestError("Unsupported voucher currency for balance type")
 		}
 
-		// Add balance to account (in GSALT units)
-		account.Balance += amountGsaltUnits
-		if err := tx.Save(account).Error; err != nil {
-			tx.Rollback()
-			return nil, nil, errors.NewInternalServerError(err, "Failed to update account balance")
-		}
-
 		// Create transaction record
 		transaction = &models.Transaction{
 			AccountID:        account.ConnectID,
@@ -296,11 +291,6 @@ func (s *VoucherRedemptionService) RedeemVoucher(accountId, voucherCode string)
 		var amountGsaltUnits int64
 		if voucher.LoyaltyPointsValue != nil {
 			amountGsaltUnits = *voucher.LoyaltyPointsValue
-			account.Balance += amountGsaltUnits
-			if err := tx.Save(account).Error; err != nil {
-				tx.Rollback()
-				return nil, nil, errors.NewInternalServerError(err, "Failed to update account balance")
-			}
 		}
 
 		// Create transaction record
@@ -335,6 +325,21 @@ func (s *VoucherRedemptionService) RedeemVoucher(accountId, voucherCode string)
 		return nil, nil, errors.NewInternalServerError(err, "Failed to create voucher redemption transaction")
 	}
 
+	// Credit the account through the ledger (in GSALT units)
+	if transaction.AmountGsaltUnits > 0 {
+		entry, err := s.ledgerService.RecordPromoCredit(tx, account.ConnectID, transaction.AmountGsaltUnits, transaction.ID.String(), transaction.Description)
+		if err != nil {
+			tx.Rollback()
+			return nil, nil, err
+		}
+
+		transaction.JournalEntryID = &entry.ID
+		if err := tx.Save(transaction).Error; err != nil {
+			tx.Rollback()
+			return nil, nil, errors.NewInternalServerError(err, "Failed to update voucher redemption transaction")
+		}
+	}
+
 	// Create redemption record
 	redemption := &models.VoucherRedemption{
 		VoucherID:     voucher.ID,
@@ -2,14 +2,24 @@ package infrastructures
 
 import (
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type AppConfig struct {
-	DATABASE_URL     string
-	CONNECT_BASE_URL string
-	FlipConfig       *FlipConfig
+	DATABASE_URL         string
+	CONNECT_BASE_URL     string
+	FlipConfig           *FlipConfig
+	SchedulerEnabled     bool
+	CancelExpiredCharges bool
+	// APIKeyPepper keys the HMAC merchant API keys are stored as; changing it invalidates every key
+	APIKeyPepper string
+	// QRSigningSecret keys the HMAC on transfer QR payloads; changing it invalidates every QR
+	QRSigningSecret string
+	// AccountClosureCoolingOff is how long a closing account can be reactivated before it is anonymised
+	AccountClosureCoolingOff time.Duration
 }
 
 var Config *AppConfig
@@ -21,15 +31,31 @@ func LoadConfig() *AppConfig {
 		DATABASE_URL:     os.Getenv("DATABASE_URL"),
 		CONNECT_BASE_URL: os.Getenv("CONNECT_BASE_URL"),
 		FlipConfig: &FlipConfig{
-			SecretKey:          os.Getenv("FLIP_SECRET_KEY"),
-			WebhookToken:       os.Getenv("FLIP_WEBHOOK_TOKEN"),
-			CallbackURL:        os.Getenv("FLIP_CALLBACK_URL"),
-			SuccessURL:         os.Getenv("FLIP_SUCCESS_URL"),
-			FailureURL:         os.Getenv("FLIP_FAILURE_URL"),
-			Environment:        os.Getenv("FLIP_ENVIRONMENT"),
-			DefaultRedirectURL: os.Getenv("FLIP_DEFAULT_REDIRECT_URL"),
+			SecretKey:               os.Getenv("FLIP_SECRET_KEY"),
+			WebhookToken:            os.Getenv("FLIP_WEBHOOK_TOKEN"),
+			CallbackURL:             os.Getenv("FLIP_CALLBACK_URL"),
+			DisbursementCallbackURL: os.Getenv("FLIP_DISBURSEMENT_CALLBACK_URL"),
+			SuccessURL:              os.Getenv("FLIP_SUCCESS_URL"),
+			FailureURL:              os.Getenv("FLIP_FAILURE_URL"),
+			Environment:             os.Getenv("FLIP_ENVIRONMENT"),
+			BaseURL:                 os.Getenv("FLIP_BASE_URL"),
+			DefaultRedirectURL:      os.Getenv("FLIP_DEFAULT_REDIRECT_URL"),
 		},
+		SchedulerEnabled:         os.Getenv("SCHEDULER_ENABLED") != "false",
+		CancelExpiredCharges:     os.Getenv("CANCEL_EXPIRED_CHARGES") != "false",
+		APIKeyPepper:             os.Getenv("API_KEY_PEPPER"),
+		QRSigningSecret:          os.Getenv("QR_SIGNING_SECRET"),
+		AccountClosureCoolingOff: time.Duration(envInt("ACCOUNT_CLOSURE_COOLING_OFF_DAYS", 30)) * 24 * time.Hour,
 	}
 
 	return Config
 }
+
+// envInt reads a positive integer environment variable, falling back to def
+func envInt(key string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return def
+	}
+	return value
+}
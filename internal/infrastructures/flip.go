@@ -6,17 +6,21 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/safatanc/gsalt-core/internal/infrastructures/flipsim"
+	"github.com/sirupsen/logrus"
 )
 
 type FlipConfig struct {
-	SecretKey          string
-	Environment        string // "sandbox" or "production"
-	BaseURL            string
-	WebhookToken       string
-	CallbackURL        string
-	SuccessURL         string
-	FailureURL         string
-	DefaultRedirectURL string
+	SecretKey               string
+	Environment             string // "sandbox", "production" or "simulator"
+	BaseURL                 string
+	WebhookToken            string
+	CallbackURL             string
+	DisbursementCallbackURL string
+	SuccessURL              string
+	FailureURL              string
+	DefaultRedirectURL      string
 }
 
 type FlipClient struct {
@@ -24,21 +28,47 @@ type FlipClient struct {
 	Config     *FlipConfig
 	BaseURL    string
 	AuthHeader string
+
+	// Simulator is set when FLIP_ENVIRONMENT is "simulator"
+	Simulator *flipsim.Server
 }
 
 // NewFlipClient creates a new Flip HTTP client with configuration
 func NewFlipClient() *FlipClient {
-	config := &FlipConfig{
-		SecretKey:   Config.FlipConfig.SecretKey,
-		Environment: Config.FlipConfig.Environment,
-	}
+	return NewFlipClientWithConfig(&FlipConfig{
+		SecretKey:               Config.FlipConfig.SecretKey,
+		Environment:             Config.FlipConfig.Environment,
+		BaseURL:                 Config.FlipConfig.BaseURL,
+		WebhookToken:            Config.FlipConfig.WebhookToken,
+		CallbackURL:             Config.FlipConfig.CallbackURL,
+		DisbursementCallbackURL: Config.FlipConfig.DisbursementCallbackURL,
+	})
+}
+
+// NewFlipClientWithConfig creates a new Flip HTTP client from an explicit configuration.
+// A non-empty BaseURL overrides the environment default, e.g. to target a flipsim.Server.
+func NewFlipClientWithConfig(config *FlipConfig) *FlipClient {
+	var simulator *flipsim.Server
 
 	// Set base URL based on environment - No version, let each endpoint handle its own version
-	if config.Environment == "production" {
-		config.BaseURL = "https://bigflip.id/api"
-	} else {
-		config.BaseURL = "https://bigflip.id/big_sandbox_api"
+	if config.BaseURL == "" {
+		switch config.Environment {
+		case "production":
+			config.BaseURL = "https://bigflip.id/api"
+		case "simulator":
+			simulator = flipsim.NewServer(flipsim.Config{
+				SecretKey:               config.SecretKey,
+				WebhookToken:            config.WebhookToken,
+				BillCallbackURL:         config.CallbackURL,
+				DisbursementCallbackURL: config.DisbursementCallbackURL,
+			})
+			config.BaseURL = simulator.URL
+			logrus.WithField("base_url", config.BaseURL).Info("Using in-process Flip simulator")
+		default:
+			config.BaseURL = "https://bigflip.id/big_sandbox_api"
+		}
 	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
 
 	// Create basic auth header - Flip requires secret key with colon
 	secretKey := config.SecretKey
@@ -55,6 +85,7 @@ func NewFlipClient() *FlipClient {
 		Config:     config,
 		BaseURL:    config.BaseURL,
 		AuthHeader: authHeader,
+		Simulator:  simulator,
 	}
 }
 
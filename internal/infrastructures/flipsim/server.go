@@ -0,0 +1,707 @@
+// Package flipsim provides an in-process simulator of the Flip for Business API.
+//
+// It implements the accept payment (bill), disbursement, bank account inquiry,
+// bank list and maintenance endpoints used by services.FlipService, and can fire
+// payment and disbursement callbacks on demand so topup and withdrawal flows can
+// run end to end without network access.
+package flipsim
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/safatanc/gsalt-core/internal/app/models"
+)
+
+// Flip date formats
+const (
+	expiredDateLayout = "2006-01-02 15:04"
+	timestampLayout   = "2006-01-02 15:04:05"
+)
+
+// Config configures the simulator
+type Config struct {
+	// SecretKey enables Basic auth checks when set, matching FlipConfig.SecretKey
+	SecretKey string
+	// WebhookToken is sent as the "token" form field of every callback
+	WebhookToken string
+	// BillCallbackURL receives accept payment callbacks
+	BillCallbackURL string
+	// DisbursementCallbackURL receives disbursement callbacks
+	DisbursementCallbackURL string
+	// Balance is the starting deposit balance in IDR
+	Balance int64
+}
+
+// Server is an httptest-based Flip API simulator
+type Server struct {
+	*httptest.Server
+
+	config Config
+	client *http.Client
+
+	mu              sync.Mutex
+	nextID          int
+	balance         int64
+	maintenance     bool
+	banks           []models.FlipBank
+	bankAccounts    map[string]string
+	bills           map[int]*models.GetBillResponse
+	disbursements   map[int]*models.DisbursementResponse
+	idempotencyKeys map[string]int
+}
+
+// NewServer starts a simulator listening on a local port
+func NewServer(config Config) *Server {
+	s := &Server{
+		config:          config,
+		client:          &http.Client{Timeout: 10 * time.Second},
+		nextID:          1000,
+		balance:         config.Balance,
+		banks:           defaultBanks(),
+		bankAccounts:    make(map[string]string),
+		bills:           make(map[int]*models.GetBillResponse),
+		disbursements:   make(map[int]*models.DisbursementResponse),
+		idempotencyKeys: make(map[string]int),
+	}
+	if s.balance == 0 {
+		s.balance = 1_000_000_000
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v2/general/balance", s.auth(s.handleGetBalance))
+	mux.HandleFunc("GET /v2/general/banks", s.auth(s.handleGetBanks))
+	mux.HandleFunc("GET /v2/general/maintenance", s.auth(s.handleGetMaintenance))
+	mux.HandleFunc("POST /v2/pwf/bill", s.auth(s.handleCreateBill))
+	mux.HandleFunc("GET /v2/pwf/bill", s.auth(s.handleGetAllBills))
+	mux.HandleFunc("GET /v2/pwf/{id}/bill", s.auth(s.handleGetBill))
+	mux.HandleFunc("PUT /v2/pwf/{id}/bill", s.auth(s.handleEditBill))
+	mux.HandleFunc("POST /v2/disbursement/bank-account-inquiry", s.auth(s.handleBankAccountInquiry))
+	mux.HandleFunc("POST /v2/disbursement", s.auth(s.handleCreateDisbursement))
+	mux.HandleFunc("GET /v2/disbursement", s.auth(s.handleGetDisbursements))
+	mux.HandleFunc("GET /v2/disbursement/{id}", s.auth(s.handleGetDisbursement))
+	mux.HandleFunc("GET /v2/get-disbursement", s.auth(s.handleGetDisbursementByIdempotencyKey))
+
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// SetCallbackURLs updates where callbacks are delivered, useful when the app
+// under test is started after the simulator
+func (s *Server) SetCallbackURLs(billCallbackURL, disbursementCallbackURL string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config.BillCallbackURL = billCallbackURL
+	s.config.DisbursementCallbackURL = disbursementCallbackURL
+}
+
+// SetMaintenance toggles the global maintenance flag
+func (s *Server) SetMaintenance(maintenance bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maintenance = maintenance
+}
+
+// SetBankAccount registers the holder name returned by inquiries for an account.
+// An empty name makes the account invalid. Unregistered accounts resolve to a
+// generated holder name.
+func (s *Server) SetBankAccount(bankCode, accountNumber, accountName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bankAccounts[bankAccountKey(bankCode, accountNumber)] = accountName
+}
+
+// Bill returns a copy of a bill by link ID
+func (s *Server) Bill(linkID int) (*models.GetBillResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bill, ok := s.bills[linkID]
+	if !ok {
+		return nil, false
+	}
+	billCopy := *bill
+	billCopy.BillPayments = append([]models.BillPaymentResponse(nil), bill.BillPayments...)
+	return &billCopy, true
+}
+
+// Disbursement returns a copy of a disbursement by ID
+func (s *Server) Disbursement(id int) (*models.DisbursementResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	disbursement, ok := s.disbursements[id]
+	if !ok {
+		return nil, false
+	}
+	disbursementCopy := *disbursement
+	return &disbursementCopy, true
+}
+
+// PayBill records a successful payment of the full bill amount and fires the callback
+func (s *Server) PayBill(linkID int) (*models.FlipWebhookPayload, error) {
+	return s.FireBillCallback(linkID, models.FlipStatusSuccessful, 0)
+}
+
+// FireBillCallback records a payment attempt with the given status on a bill and
+// posts it to the bill callback URL. An amount of 0 uses the bill amount.
+func (s *Server) FireBillCallback(linkID int, status models.FlipPaymentStatus, amount int64) (*models.FlipWebhookPayload, error) {
+	s.mu.Lock()
+	bill, ok := s.bills[linkID]
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("bill %d not found", linkID)
+	}
+	if amount == 0 {
+		amount = bill.Amount
+	}
+	if status == models.FlipStatusSuccessful && bill.Status == "INACTIVE" {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("bill %d is inactive", linkID)
+	}
+
+	now := time.Now()
+	s.nextID++
+	payment := models.BillPaymentResponse{
+		ID:         s.nextID,
+		BillLinkID: bill.LinkID,
+		BillTitle:  bill.Title,
+		Amount:     amount,
+		Status:     status,
+		Settlement: "Pending",
+		Created:    now,
+		Updated:    now,
+	}
+	bill.BillPayments = append(bill.BillPayments, payment)
+	if status == models.FlipStatusSuccessful && bill.Type == "SINGLE" {
+		bill.Status = "INACTIVE"
+	}
+	bill.Updated = now
+
+	payload := &models.FlipWebhookPayload{
+		ID:         payment.ID,
+		BillLinkID: bill.LinkID,
+		BillTitle:  bill.Title,
+		Amount:     amount,
+		Status:     status,
+		Settlement: payment.Settlement,
+		Created:    now.Format(timestampLayout),
+		Updated:    now.Format(timestampLayout),
+	}
+	callbackURL := s.config.BillCallbackURL
+	s.mu.Unlock()
+
+	if err := s.postCallback(callbackURL, payload); err != nil {
+		return payload, err
+	}
+	return payload, nil
+}
+
+// CompleteDisbursement moves a disbursement to the given status (PROCESSED, DONE
+// or CANCELLED) and posts it to the disbursement callback URL
+func (s *Server) CompleteDisbursement(id int, status models.DisbursementStatus) (*models.DisbursementResponse, error) {
+	s.mu.Lock()
+	disbursement, ok := s.disbursements[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("disbursement %d not found", id)
+	}
+
+	disbursement.Status = string(status)
+	switch status {
+	case models.DisbursementStatusDone:
+		disbursement.Receipt = fmt.Sprintf("%s/receipts/%d.jpg", s.URL, id)
+		disbursement.TimeServed = time.Now().Format(timestampLayout)
+	case models.DisbursementStatusCancelled:
+		disbursement.Reason = "Cancelled by simulator"
+		s.balance += disbursement.Amount + disbursement.Fee
+	}
+	payload := *disbursement
+	callbackURL := s.config.DisbursementCallbackURL
+	s.mu.Unlock()
+
+	if err := s.postCallback(callbackURL, payload); err != nil {
+		return &payload, err
+	}
+	return &payload, nil
+}
+
+// ========== HTTP HANDLERS ==========
+
+func (s *Server) handleGetBalance(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, models.FlipBalance{Balance: s.balance})
+}
+
+func (s *Server) handleGetBanks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.banks)
+}
+
+func (s *Server) handleGetMaintenance(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, models.FlipMaintenanceInfo{Maintenance: s.maintenance, Banks: []models.FlipMaintenanceBankInfo{}})
+}
+
+func (s *Server) handleCreateBill(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid form body")
+		return
+	}
+
+	title := r.PostForm.Get("title")
+	amount, err := strconv.ParseInt(r.PostForm.Get("amount"), 10, 64)
+	if title == "" || err != nil || amount < 10000 {
+		writeError(w, http.StatusUnprocessableEntity, "Title is required and amount must be at least 10000")
+		return
+	}
+
+	expiredDate := r.PostForm.Get("expired_date")
+	if expiredDate == "" {
+		expiredDate = time.Now().Add(24 * time.Hour).Format(expiredDateLayout)
+	} else if _, err := time.Parse(expiredDateLayout, expiredDate); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Invalid expired_date format")
+		return
+	}
+
+	billType := strings.ToUpper(r.PostForm.Get("type"))
+	if billType == "" {
+		billType = "SINGLE"
+	}
+	step, _ := strconv.Atoi(r.PostForm.Get("step"))
+	if step == 0 {
+		step = 1
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	s.nextID++
+	linkID := s.nextID
+	bill := &models.GetBillResponse{
+		LinkID:      linkID,
+		LinkURL:     fmt.Sprintf("%s/pay/%d", s.URL, linkID),
+		Title:       title,
+		Type:        billType,
+		Amount:      amount,
+		ExpiredDate: expiredDate,
+		RedirectURL: r.PostForm.Get("redirect_url"),
+		Step:        step,
+		CreatedFrom: "API",
+		Status:      "ACTIVE",
+		Created:     now,
+		Updated:     now,
+	}
+	s.bills[linkID] = bill
+	s.mu.Unlock()
+
+	resp := models.PaymentGatewayResponse{
+		LinkID:            linkID,
+		LinkURL:           bill.LinkURL,
+		Title:             bill.Title,
+		Type:              bill.Type,
+		Amount:            bill.Amount,
+		RedirectURL:       bill.RedirectURL,
+		ExpiredDate:       &expiredDate,
+		CreatedFrom:       bill.CreatedFrom,
+		Status:            bill.Status,
+		Step:              bill.Step,
+		InternalReference: r.PostForm.Get("reference_id"),
+		SenderBank:        r.PostForm.Get("sender_bank"),
+		SenderBankType:    r.PostForm.Get("sender_bank_type"),
+		CreatedAt:         now.Unix(),
+	}
+
+	if senderName := r.PostForm.Get("sender_name"); senderName != "" {
+		resp.Customer = &models.CustomerDetails{
+			Name:  senderName,
+			Email: r.PostForm.Get("sender_email"),
+			Phone: r.PostForm.Get("sender_phone_number"),
+		}
+	}
+
+	// Direct API bills with a sender bank get payment instructions immediately
+	if step == 3 && resp.SenderBank != "" {
+		resp.PaymentURL = bill.LinkURL
+		resp.BillPayment = &models.BillPayment{
+			ID:             fmt.Sprintf("FT%d", linkID),
+			Amount:         amount,
+			Status:         string(models.FlipStatusPending),
+			SenderBank:     resp.SenderBank,
+			SenderBankType: resp.SenderBankType,
+			ReceiverBankAccount: &models.ReceiverBankAccount{
+				AccountNumber: fmt.Sprintf("8808%010d", linkID),
+				AccountType:   resp.SenderBankType,
+				BankCode:      resp.SenderBank,
+				AccountHolder: "GSALT",
+			},
+			CreatedAt: now.Unix(),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleGetAllBills(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int, 0, len(s.bills))
+	for id := range s.bills {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	bills := make([]models.GetBillResponse, 0, len(ids))
+	for _, id := range ids {
+		bills = append(bills, *s.bills[id])
+	}
+	writeJSON(w, http.StatusOK, bills)
+}
+
+func (s *Server) handleGetBill(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Bill not found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bill, ok := s.bills[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Bill not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, bill)
+}
+
+func (s *Server) handleEditBill(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Bill not found")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid form body")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bill, ok := s.bills[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Bill not found")
+		return
+	}
+
+	if title := r.PostForm.Get("title"); title != "" {
+		bill.Title = title
+	}
+	if amount, err := strconv.ParseInt(r.PostForm.Get("amount"), 10, 64); err == nil && amount > 0 {
+		bill.Amount = amount
+	}
+	if expiredDate := r.PostForm.Get("expired_date"); expiredDate != "" {
+		bill.ExpiredDate = expiredDate
+	}
+	if status := strings.ToUpper(r.PostForm.Get("status")); status == "ACTIVE" || status == "INACTIVE" {
+		bill.Status = status
+	}
+	bill.Updated = time.Now()
+
+	writeJSON(w, http.StatusOK, bill)
+}
+
+func (s *Server) handleBankAccountInquiry(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid form body")
+		return
+	}
+
+	bankCode := r.PostForm.Get("bank_code")
+	accountNumber := r.PostForm.Get("account_number")
+	if bankCode == "" || accountNumber == "" {
+		writeError(w, http.StatusUnprocessableEntity, "bank_code and account_number are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.hasBank(bankCode) {
+		writeError(w, http.StatusUnprocessableEntity, "Bank code is not supported")
+		return
+	}
+
+	resp := models.BankAccountInquiryResponse{
+		BankCode:      bankCode,
+		AccountNumber: accountNumber,
+		Status:        "SUCCESS",
+		InquiryKey:    r.PostForm.Get("inquiry_key"),
+	}
+
+	accountName, registered := s.bankAccounts[bankAccountKey(bankCode, accountNumber)]
+	switch {
+	case !registered:
+		resp.AccountName = "SIMULATED " + strings.ToUpper(bankCode) + " " + accountNumber
+	case accountName == "":
+		resp.Status = "INVALID_ACCOUNT_NUMBER"
+	default:
+		resp.AccountName = accountName
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCreateDisbursement(w http.ResponseWriter, r *http.Request) {
+	idempotencyKey := r.Header.Get("idempotency-key")
+	if idempotencyKey == "" {
+		writeError(w, http.StatusUnprocessableEntity, "idempotency-key header is required")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid form body")
+		return
+	}
+
+	bankCode := r.PostForm.Get("bank_code")
+	accountNumber := r.PostForm.Get("account_number")
+	amount, err := strconv.ParseInt(r.PostForm.Get("amount"), 10, 64)
+	if bankCode == "" || accountNumber == "" || err != nil || amount <= 0 {
+		writeError(w, http.StatusUnprocessableEntity, "bank_code, account_number and amount are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Replays with the same idempotency key return the original disbursement
+	if id, ok := s.idempotencyKeys[idempotencyKey]; ok {
+		writeJSON(w, http.StatusOK, s.disbursements[id])
+		return
+	}
+
+	if s.maintenance {
+		writeError(w, http.StatusServiceUnavailable, "Disbursement is under maintenance")
+		return
+	}
+
+	bank, ok := s.findBank(bankCode)
+	if !ok {
+		writeError(w, http.StatusUnprocessableEntity, "Bank code is not supported")
+		return
+	}
+	if amount+bank.Fee > s.balance {
+		writeError(w, http.StatusUnprocessableEntity, "Insufficient deposit balance")
+		return
+	}
+	s.balance -= amount + bank.Fee
+
+	recipientName := s.bankAccounts[bankAccountKey(bankCode, accountNumber)]
+	if recipientName == "" {
+		recipientName = "SIMULATED " + strings.ToUpper(bankCode) + " " + accountNumber
+	}
+
+	s.nextID++
+	disbursement := &models.DisbursementResponse{
+		ID:             s.nextID,
+		UserID:         1,
+		Amount:         amount,
+		Status:         string(models.DisbursementStatusPending),
+		Timestamp:      time.Now().Format(timestampLayout),
+		BankCode:       bankCode,
+		AccountNumber:  accountNumber,
+		RecipientName:  recipientName,
+		SenderBank:     "bca",
+		Remark:         r.PostForm.Get("remark"),
+		CreatedFrom:    "API",
+		Direction:      "DOMESTIC",
+		Fee:            bank.Fee,
+		IdempotencyKey: idempotencyKey,
+	}
+	s.disbursements[disbursement.ID] = disbursement
+	s.idempotencyKeys[idempotencyKey] = disbursement.ID
+
+	writeJSON(w, http.StatusOK, disbursement)
+}
+
+func (s *Server) handleGetDisbursements(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int, 0, len(s.disbursements))
+	for id := range s.disbursements {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	if r.URL.Query().Get("sort") == "-id" {
+		sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	}
+
+	const perPage = 20
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page <= 0 {
+		page = 1
+	}
+
+	resp := models.DisbursementListResponse{
+		Data:        []models.DisbursementResponse{},
+		TotalData:   len(ids),
+		DataPerPage: perPage,
+		TotalPage:   (len(ids) + perPage - 1) / perPage,
+		Page:        page,
+	}
+	for i := (page - 1) * perPage; i < len(ids) && i < page*perPage; i++ {
+		resp.Data = append(resp.Data, *s.disbursements[ids[i]])
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleGetDisbursement(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "Disbursement not found")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	disbursement, ok := s.disbursements[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Disbursement not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, disbursement)
+}
+
+func (s *Server) handleGetDisbursementByIdempotencyKey(w http.ResponseWriter, r *http.Request) {
+	key := r.URL.Query().Get("idempotency_key")
+	if key == "" {
+		key = r.URL.Query().Get("idempotency-key")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.idempotencyKeys[key]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Disbursement not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.disbursements[id])
+}
+
+// ========== HELPERS ==========
+
+// auth enforces Flip's Basic auth scheme (secret key as username, empty password)
+func (s *Server) auth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config.SecretKey != "" {
+			secretKey := s.config.SecretKey
+			if !strings.HasSuffix(secretKey, ":") {
+				secretKey += ":"
+			}
+			expected := "Basic " + base64.StdEncoding.EncodeToString([]byte(secretKey))
+			if r.Header.Get("Authorization") != expected {
+				writeError(w, http.StatusUnauthorized, "Invalid credentials")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// postCallback delivers a callback the way Flip does: form data with a JSON "data" field and a "token" field
+func (s *Server) postCallback(callbackURL string, payload interface{}) error {
+	if callbackURL == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode callback payload: %w", err)
+	}
+
+	form := url.Values{}
+	form.Set("data", string(data))
+	form.Set("token", s.config.WebhookToken)
+
+	resp, err := s.client.PostForm(callbackURL, form)
+	if err != nil {
+		return fmt.Errorf("failed to deliver callback: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *Server) findBank(bankCode string) (models.FlipBank, bool) {
+	for _, bank := range s.banks {
+		if strings.EqualFold(bank.BankCode, bankCode) {
+			return bank, true
+		}
+	}
+	return models.FlipBank{}, false
+}
+
+func (s *Server) hasBank(bankCode string) bool {
+	_, ok := s.findBank(bankCode)
+	return ok
+}
+
+func bankAccountKey(bankCode, accountNumber string) string {
+	return strings.ToLower(bankCode) + ":" + accountNumber
+}
+
+func defaultBanks() []models.FlipBank {
+	banks := []struct{ code, name string }{
+		{"bca", "BCA"},
+		{"bni", "BNI"},
+		{"bri", "BRI"},
+		{"mandiri", "Mandiri"},
+		{"cimb", "CIMB Niaga"},
+		{"permata", "Permata"},
+		{"bsm", "Bank Syariah Indonesia"},
+	}
+
+	result := make([]models.FlipBank, 0, len(banks))
+	for _, bank := range banks {
+		result = append(result, models.FlipBank{
+			BankCode:  bank.code,
+			Name:      bank.name,
+			Fee:       2500,
+			Queue:     0,
+			Status:    "OPERATIONAL",
+			MinAmount: 10000,
+			MaxAmount: 100_000_000,
+		})
+	}
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, models.FlipErrorResponse{Code: status, Message: message})
+}
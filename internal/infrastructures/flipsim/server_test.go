@@ -0,0 +1,300 @@
+package flipsim
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/safatanc/gsalt-core/internal/app/models"
+)
+
+const testSecretKey = "test-secret"
+
+// callback is one form-encoded callback received from the simulator
+type callback struct {
+	contentType string
+	form        url.Values
+}
+
+// newTestServer starts a simulator whose callbacks are captured on the returned channel
+func newTestServer(t *testing.T) (*Server, <-chan callback) {
+	t.Helper()
+
+	callbacks := make(chan callback, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		callbacks <- callback{contentType: r.Header.Get("Content-Type"), form: r.PostForm}
+	}))
+	t.Cleanup(receiver.Close)
+
+	sim := NewServer(Config{
+		SecretKey:               testSecretKey,
+		WebhookToken:            "callback-token",
+		BillCallbackURL:         receiver.URL + "/bill",
+		DisbursementCallbackURL: receiver.URL + "/disbursement",
+	})
+	t.Cleanup(sim.Close)
+
+	return sim, callbacks
+}
+
+// do sends an authenticated request and decodes a JSON response into out
+func do(t *testing.T, sim *Server, method, path string, form url.Values, header map[string]string, out interface{}) int {
+	t.Helper()
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequest(method, sim.URL+path, body)
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(testSecretKey+":")))
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("failed to decode %s %s response: %v", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func createBill(t *testing.T, sim *Server, amount int64) models.PaymentGatewayResponse {
+	t.Helper()
+
+	var bill models.PaymentGatewayResponse
+	status := do(t, sim, http.MethodPost, "/v2/pwf/bill", url.Values{
+		"title":  {"Topup"},
+		"amount": {strconv.FormatInt(amount, 10)},
+		"type":   {"SINGLE"},
+		"step":   {"3"},
+	}, nil, &bill)
+	if status != http.StatusOK {
+		t.Fatalf("create bill status = %d, want %d", status, http.StatusOK)
+	}
+	return bill
+}
+
+func createDisbursement(t *testing.T, sim *Server, idempotencyKey string, amount int64) (int, models.DisbursementResponse) {
+	t.Helper()
+
+	var disbursement models.DisbursementResponse
+	status := do(t, sim, http.MethodPost, "/v2/disbursement", url.Values{
+		"bank_code":      {"bca"},
+		"account_number": {"1234567890"},
+		"amount":         {strconv.FormatInt(amount, 10)},
+	}, map[string]string{"idempotency-key": idempotencyKey}, &disbursement)
+	return status, disbursement
+}
+
+func TestRejectsInvalidCredentials(t *testing.T) {
+	sim, _ := newTestServer(t)
+
+	resp, err := http.Get(sim.URL + "/v2/general/balance")
+	if err != nil {
+		t.Fatalf("GET balance: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestCreateBill(t *testing.T) {
+	sim, _ := newTestServer(t)
+
+	bill := createBill(t, sim, 50000)
+	if bill.LinkID == 0 || bill.Status != "ACTIVE" || bill.Amount != 50000 {
+		t.Fatalf("bill = %+v, want an ACTIVE bill of 50000", bill)
+	}
+
+	stored, ok := sim.Bill(bill.LinkID)
+	if !ok {
+		t.Fatalf("bill %d is not stored", bill.LinkID)
+	}
+	if stored.Amount != 50000 || stored.Type != "SINGLE" {
+		t.Fatalf("stored bill = %+v", stored)
+	}
+
+	var invalid models.FlipErrorResponse
+	status := do(t, sim, http.MethodPost, "/v2/pwf/bill", url.Values{"title": {"Topup"}, "amount": {"5000"}}, nil, &invalid)
+	if status != http.StatusUnprocessableEntity {
+		t.Fatalf("bill below the minimum status = %d, want %d", status, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestPayBillSendsFormEncodedCallbackWithToken(t *testing.T) {
+	sim, callbacks := newTestServer(t)
+	bill := createBill(t, sim, 50000)
+
+	if _, err := sim.PayBill(bill.LinkID); err != nil {
+		t.Fatalf("PayBill: %v", err)
+	}
+
+	received := <-callbacks
+	if !strings.HasPrefix(received.contentType, "application/x-www-form-urlencoded") {
+		t.Errorf("callback content type = %q, want form encoded", received.contentType)
+	}
+	if token := received.form.Get("token"); token != "callback-token" {
+		t.Errorf("callback token = %q, want %q", token, "callback-token")
+	}
+
+	var payload models.FlipWebhookPayload
+	if err := json.Unmarshal([]byte(received.form.Get("data")), &payload); err != nil {
+		t.Fatalf("callback data is not JSON: %v", err)
+	}
+	if payload.BillLinkID != bill.LinkID || payload.Amount != 50000 || payload.Status != models.FlipStatusSuccessful {
+		t.Errorf("callback payload = %+v", payload)
+	}
+
+	// A single bill accepts one successful payment
+	stored, _ := sim.Bill(bill.LinkID)
+	if stored.Status != "INACTIVE" {
+		t.Errorf("paid bill status = %s, want INACTIVE", stored.Status)
+	}
+	if _, err := sim.PayBill(bill.LinkID); err == nil {
+		t.Error("second payment of a single bill was accepted")
+	}
+}
+
+func TestCallbackErrorIsReported(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	sim, _ := newTestServer(t)
+	sim.SetCallbackURLs(receiver.URL, receiver.URL)
+	bill := createBill(t, sim, 50000)
+
+	if _, err := sim.PayBill(bill.LinkID); err == nil {
+		t.Fatal("PayBill succeeded although the callback was rejected")
+	}
+}
+
+func TestCreateDisbursementIsIdempotent(t *testing.T) {
+	sim, _ := newTestServer(t)
+
+	status, first := createDisbursement(t, sim, "withdrawal-1", 100000)
+	if status != http.StatusOK {
+		t.Fatalf("create disbursement status = %d, want %d", status, http.StatusOK)
+	}
+	if first.Status != string(models.DisbursementStatusPending) || first.IdempotencyKey != "withdrawal-1" {
+		t.Fatalf("disbursement = %+v", first)
+	}
+
+	_, replay := createDisbursement(t, sim, "withdrawal-1", 100000)
+	if replay.ID != first.ID {
+		t.Fatalf("replayed disbursement ID = %d, want %d", replay.ID, first.ID)
+	}
+
+	var found models.DisbursementResponse
+	if status := do(t, sim, http.MethodGet, "/v2/get-disbursement?idempotency_key=withdrawal-1", nil, nil, &found); status != http.StatusOK || found.ID != first.ID {
+		t.Fatalf("lookup by idempotency key = %d %+v", status, found)
+	}
+	if status := do(t, sim, http.MethodGet, "/v2/get-disbursement?idempotency_key=unknown", nil, nil, nil); status != http.StatusNotFound {
+		t.Fatalf("lookup of an unknown key status = %d, want %d", status, http.StatusNotFound)
+	}
+
+	var missing models.FlipErrorResponse
+	if status := do(t, sim, http.MethodPost, "/v2/disbursement", url.Values{"bank_code": {"bca"}, "account_number": {"1"}, "amount": {"10000"}}, nil, &missing); status != http.StatusUnprocessableEntity {
+		t.Fatalf("disbursement without idempotency key status = %d, want %d", status, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestCompleteDisbursementSendsCallback(t *testing.T) {
+	sim, callbacks := newTestServer(t)
+	_, disbursement := createDisbursement(t, sim, "withdrawal-2", 100000)
+
+	if _, err := sim.CompleteDisbursement(disbursement.ID, models.DisbursementStatusDone); err != nil {
+		t.Fatalf("CompleteDisbursement: %v", err)
+	}
+
+	received := <-callbacks
+	if token := received.form.Get("token"); token != "callback-token" {
+		t.Errorf("callback token = %q, want %q", token, "callback-token")
+	}
+	var payload models.DisbursementResponse
+	if err := json.Unmarshal([]byte(received.form.Get("data")), &payload); err != nil {
+		t.Fatalf("callback data is not JSON: %v", err)
+	}
+	if payload.ID != disbursement.ID || payload.Status != string(models.DisbursementStatusDone) || payload.Receipt == "" {
+		t.Errorf("callback payload = %+v", payload)
+	}
+	if payload.IdempotencyKey != "withdrawal-2" {
+		t.Errorf("callback idempotency key = %q, want %q", payload.IdempotencyKey, "withdrawal-2")
+	}
+}
+
+func TestMaintenanceBlocksDisbursements(t *testing.T) {
+	sim, _ := newTestServer(t)
+	sim.SetMaintenance(true)
+
+	var info models.FlipMaintenanceInfo
+	do(t, sim, http.MethodGet, "/v2/general/maintenance", nil, nil, &info)
+	if !info.Maintenance {
+		t.Fatal("maintenance flag is not reported")
+	}
+
+	if status, _ := createDisbursement(t, sim, "withdrawal-3", 100000); status != http.StatusServiceUnavailable {
+		t.Fatalf("disbursement during maintenance status = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+
+	sim.SetMaintenance(false)
+	if status, _ := createDisbursement(t, sim, "withdrawal-3", 100000); status != http.StatusOK {
+		t.Fatalf("disbursement after maintenance status = %d, want %d", status, http.StatusOK)
+	}
+}
+
+func TestBankAccountInquiry(t *testing.T) {
+	sim, _ := newTestServer(t)
+	sim.SetBankAccount("bca", "111", "Jane Doe")
+	sim.SetBankAccount("bca", "222", "")
+
+	tests := []struct {
+		bankCode, accountNumber string
+		wantStatus              string
+		wantName                string
+	}{
+		{"bca", "111", "SUCCESS", "Jane Doe"},
+		{"bca", "222", "INVALID_ACCOUNT_NUMBER", ""},
+		{"bca", "333", "SUCCESS", "SIMULATED BCA 333"},
+	}
+	for _, tt := range tests {
+		var inquiry models.BankAccountInquiryResponse
+		do(t, sim, http.MethodPost, "/v2/disbursement/bank-account-inquiry", url.Values{
+			"bank_code":      {tt.bankCode},
+			"account_number": {tt.accountNumber},
+		}, nil, &inquiry)
+		if inquiry.Status != tt.wantStatus || inquiry.AccountName != tt.wantName {
+			t.Errorf("inquiry %s/%s = %s %q, want %s %q", tt.bankCode, tt.accountNumber, inquiry.Status, inquiry.AccountName, tt.wantStatus, tt.wantName)
+		}
+	}
+
+	if status := do(t, sim, http.MethodPost, "/v2/disbursement/bank-account-inquiry", url.Values{
+		"bank_code":      {"unknown"},
+		"account_number": {"1"},
+	}, nil, nil); status != http.StatusUnprocessableEntity {
+		t.Fatalf("inquiry with an unknown bank status = %d, want %d", status, http.StatusUnprocessableEntity)
+	}
+}
@@ -0,0 +1,16 @@
+-- Unlink transactions from journal entries
+DROP INDEX IF EXISTS idx_transactions_journal_entry;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS journal_entry_id;
+
+-- Drop ledger tables
+DROP TABLE IF EXISTS postings;
+
+DROP TABLE IF EXISTS journal_entries;
+
+DROP TABLE IF EXISTS ledger_accounts;
+
+-- Drop ledger enums
+DROP TYPE IF EXISTS posting_direction;
+
+DROP TYPE IF EXISTS ledger_account_type;
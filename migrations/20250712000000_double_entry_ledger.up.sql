@@ -0,0 +1,178 @@
+-- Create ledger enums
+CREATE TYPE ledger_account_type AS ENUM (
+    'ASSET',
+    'LIABILITY',
+    'EQUITY',
+    'REVENUE',
+    'EXPENSE'
+);
+
+CREATE TYPE posting_direction AS ENUM ('DEBIT', 'CREDIT');
+
+-- Create ledger_accounts table
+-- Wallet accounts (code WALLET:<connect_id>) are owned by a GSALT account,
+-- system accounts (fees, promotions, provider clearing) have no owner.
+CREATE TABLE ledger_accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    code VARCHAR(100) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    type ledger_account_type NOT NULL,
+    owner_account_id UUID REFERENCES accounts (connect_id),
+    currency VARCHAR(10) NOT NULL DEFAULT 'GSALT',
+    balance BIGINT NOT NULL DEFAULT 0,
+    allow_negative BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_ledger_balance_non_negative CHECK (
+        allow_negative
+        OR balance >= 0
+    )
+);
+
+CREATE UNIQUE INDEX idx_ledger_accounts_owner ON ledger_accounts (owner_account_id)
+WHERE
+    owner_account_id IS NOT NULL;
+
+-- Create journal_entries table
+CREATE TABLE journal_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    reference VARCHAR(255) NOT NULL,
+    description TEXT,
+    posted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_journal_entries_reference ON journal_entries (reference);
+
+-- Create postings table
+CREATE TABLE postings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    journal_entry_id UUID NOT NULL REFERENCES journal_entries (id),
+    ledger_account_id UUID NOT NULL REFERENCES ledger_accounts (id),
+    direction posting_direction NOT NULL,
+    amount BIGINT NOT NULL,
+    balance_after BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_posting_amount_positive CHECK (amount > 0)
+);
+
+CREATE INDEX idx_postings_journal_entry ON postings (journal_entry_id);
+
+CREATE INDEX idx_postings_ledger_account_created ON postings (ledger_account_id, created_at DESC);
+
+-- Link transactions to their journal entry
+ALTER TABLE transactions
+ADD COLUMN journal_entry_id UUID REFERENCES journal_entries (id);
+
+CREATE INDEX idx_transactions_journal_entry ON transactions (journal_entry_id);
+
+-- Seed system ledger accounts
+INSERT INTO
+    ledger_accounts (code, name, type, allow_negative)
+VALUES (
+        'FEE_REVENUE',
+        'Fee Revenue',
+        'REVENUE',
+        FALSE
+    ),
+    (
+        'PROMO_EXPENSE',
+        'Promotions Expense',
+        'EXPENSE',
+        TRUE
+    ),
+    (
+        'OPENING_BALANCE',
+        'Opening Balance',
+        'EQUITY',
+        TRUE
+    ),
+    (
+        'PROVIDER_CLEARING:FLIP',
+        'FLIP Clearing',
+        'ASSET',
+        TRUE
+    );
+
+-- Backfill a wallet ledger account for every existing account
+INSERT INTO
+    ledger_accounts (
+        code,
+        name,
+        type,
+        owner_account_id,
+        balance
+    )
+SELECT 'WALLET:' || a.connect_id::text, 'Wallet ' || a.connect_id::text, 'LIABILITY', a.connect_id, a.balance
+FROM accounts a;
+
+-- Post opening balances so every wallet balance is backed by postings
+WITH
+    opening AS (
+        INSERT INTO
+            journal_entries (reference, description)
+        SELECT 'OPENING:' || la.owner_account_id::text, 'Opening balance migrated from accounts.balance'
+        FROM ledger_accounts la
+        WHERE
+            la.owner_account_id IS NOT NULL
+            AND la.balance > 0
+        RETURNING
+            id,
+            reference
+    )
+INSERT INTO
+    postings (
+        journal_entry_id,
+        ledger_account_id,
+        direction,
+        amount,
+        balance_after
+    )
+SELECT o.id, la.id, 'CREDIT', la.balance, la.balance
+FROM opening o
+    JOIN ledger_accounts la ON la.code = 'WALLET:' || substring(
+        o.reference
+        FROM 9
+    )
+UNION ALL
+SELECT o.id, ob.id, 'DEBIT', la.balance, 0
+FROM
+    opening o
+    JOIN ledger_accounts la ON la.code = 'WALLET:' || substring(
+        o.reference
+        FROM 9
+    )
+    CROSS JOIN ledger_accounts ob
+WHERE
+    ob.code = 'OPENING_BALANCE';
+
+UPDATE ledger_accounts
+SET
+    balance = (
+        SELECT -COALESCE(SUM(amount), 0)
+        FROM postings p
+        WHERE
+            p.ledger_account_id = ledger_accounts.id
+    )
+WHERE
+    code = 'OPENING_BALANCE';
+
+-- Opening balance is credit-normal, so each debit lowers its running balance
+UPDATE postings p
+SET
+    balance_after = r.running_balance
+FROM (
+        SELECT id, - SUM(amount) OVER (
+                ORDER BY created_at, id
+            ) AS running_balance
+        FROM postings
+        WHERE
+            ledger_account_id = (
+                SELECT id
+                FROM ledger_accounts
+                WHERE
+                    code = 'OPENING_BALANCE'
+            )
+    ) r
+WHERE
+    p.id = r.id;
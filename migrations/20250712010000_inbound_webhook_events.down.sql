@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_payment_details_provider_payment;
+
+DROP TABLE IF EXISTS inbound_webhook_events;
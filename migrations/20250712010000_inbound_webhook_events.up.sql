@@ -0,0 +1,42 @@
+-- Create inbound_webhook_events table
+-- Every verified provider callback is recorded once per provider payment ID
+CREATE TABLE inbound_webhook_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    provider VARCHAR(50) NOT NULL,
+    event_type VARCHAR(20) NOT NULL,
+    external_id VARCHAR(255) NOT NULL,
+    provider_payment_id VARCHAR(255),
+    transaction_id UUID REFERENCES transactions (id),
+    payment_status VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'RECEIVED',
+    error_message TEXT,
+    payload JSONB,
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    processed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_inbound_webhook_events_external UNIQUE (
+        provider,
+        event_type,
+        external_id
+    ),
+    CONSTRAINT chk_inbound_webhook_event_type CHECK (
+        event_type IN ('PAYMENT', 'DISBURSEMENT')
+    ),
+    CONSTRAINT chk_inbound_webhook_event_status CHECK (
+        status IN (
+            'RECEIVED',
+            'PROCESSED',
+            'REJECTED',
+            'FAILED'
+        )
+    )
+);
+
+CREATE INDEX idx_inbound_webhook_events_transaction ON inbound_webhook_events (transaction_id);
+
+CREATE INDEX idx_inbound_webhook_events_status ON inbound_webhook_events (status, received_at);
+
+-- Webhooks resolve transactions by provider payment ID
+CREATE INDEX IF NOT EXISTS idx_payment_details_provider_payment ON payment_details (provider, provider_payment_id);
@@ -0,0 +1,30 @@
+CREATE OR REPLACE FUNCTION log_transaction_status_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'UPDATE' AND OLD.status IS DISTINCT FROM NEW.status) THEN
+        INSERT INTO transaction_status_history (
+            transaction_id,
+            from_status,
+            to_status,
+            reason,
+            metadata
+        ) VALUES (
+            NEW.id,
+            OLD.status,
+            NEW.status,
+            CASE 
+                WHEN NEW.status = 'COMPLETED' THEN 'Transaction completed successfully'
+                WHEN NEW.status = 'FAILED' THEN 'Transaction failed'
+                WHEN NEW.status = 'CANCELLED' THEN 'Transaction cancelled'
+                ELSE 'Status changed'
+            END,
+            jsonb_build_object(
+                'payment_method', NEW.payment_method,
+                'external_payment_id', NEW.external_payment_id,
+                'processing_status', NEW.processing_status
+            )
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
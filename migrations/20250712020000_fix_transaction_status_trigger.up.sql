@@ -0,0 +1,36 @@
+-- external_payment_id and processing_status were dropped in 20250711014648, which made every
+-- status change on transactions fail inside the history trigger. Use the current payment columns.
+CREATE OR REPLACE FUNCTION log_transaction_status_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'UPDATE' AND OLD.status IS DISTINCT FROM NEW.status) THEN
+        INSERT INTO transaction_status_history (
+            transaction_id,
+            from_status,
+            to_status,
+            reason,
+            metadata
+        ) VALUES (
+            NEW.id,
+            OLD.status,
+            NEW.status,
+            COALESCE(
+                NEW.payment_status_description,
+                CASE 
+                    WHEN NEW.status = 'COMPLETED' THEN 'Transaction completed successfully'
+                    WHEN NEW.status = 'FAILED' THEN 'Transaction failed'
+                    WHEN NEW.status = 'CANCELLED' THEN 'Transaction cancelled'
+                    ELSE 'Status changed'
+                END
+            ),
+            jsonb_build_object(
+                'payment_method', NEW.payment_method,
+                'payment_status', NEW.payment_status,
+                'external_reference_id', NEW.external_reference_id,
+                'journal_entry_id', NEW.journal_entry_id
+            )
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
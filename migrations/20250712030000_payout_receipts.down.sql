@@ -0,0 +1 @@
+ALTER TABLE payment_details DROP COLUMN IF EXISTS receipt_url;
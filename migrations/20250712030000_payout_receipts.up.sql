@@ -0,0 +1,2 @@
+-- Receipt issued by the provider once a payout has been transferred
+ALTER TABLE payment_details ADD COLUMN receipt_url TEXT;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_scheduled_job_runs_job_started;
+
+DROP TABLE IF EXISTS scheduled_job_runs;
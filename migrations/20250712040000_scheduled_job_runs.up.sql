@@ -0,0 +1,27 @@
+-- Create scheduled_job_runs table
+-- One row per execution of a background job, written by the in-process scheduler
+CREATE TABLE scheduled_job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    job_name VARCHAR(100) NOT NULL,
+    trigger VARCHAR(20) NOT NULL,
+    triggered_by UUID,
+    instance_id VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error_message TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    duration_ms BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_scheduled_job_run_trigger CHECK (
+        trigger IN ('SCHEDULE', 'MANUAL')
+    ),
+    CONSTRAINT chk_scheduled_job_run_status CHECK (
+        status IN (
+            'RUNNING',
+            'SUCCEEDED',
+            'FAILED'
+        )
+    )
+);
+
+CREATE INDEX idx_scheduled_job_runs_job_started ON scheduled_job_runs (job_name, started_at DESC);
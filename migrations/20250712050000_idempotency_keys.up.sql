@@ -0,0 +1,23 @@
+-- Create idempotency_keys table
+-- Stores the first response for each Idempotency-Key so client retries can be replayed
+CREATE TABLE idempotency_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    scope VARCHAR(100) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_method VARCHAR(10) NOT NULL,
+    request_path TEXT NOT NULL,
+    request_hash VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    response_status INTEGER,
+    response_content_type VARCHAR(100),
+    response_body BYTEA,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT uq_idempotency_keys_scope_key UNIQUE (scope, key),
+    CONSTRAINT chk_idempotency_key_status CHECK (
+        status IN ('PROCESSING', 'COMPLETED')
+    )
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
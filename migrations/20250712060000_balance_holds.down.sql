@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_balance_holds_active;
+
+DROP INDEX IF EXISTS idx_balance_holds_transaction;
+
+DROP INDEX IF EXISTS idx_balance_holds_account_created;
+
+DROP TABLE IF EXISTS balance_holds;
+
+ALTER TABLE accounts
+DROP CONSTRAINT IF EXISTS chk_held_balance_range,
+DROP COLUMN IF EXISTS available_balance,
+DROP COLUMN IF EXISTS held_balance;
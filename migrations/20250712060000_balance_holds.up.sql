@@ -0,0 +1,45 @@
+-- Reserved funds on accounts
+-- held_balance is the sum of ACTIVE balance_holds; available_balance is what can be spent
+ALTER TABLE accounts
+ADD COLUMN held_balance BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN available_balance BIGINT GENERATED ALWAYS AS (balance - held_balance) STORED,
+ADD CONSTRAINT chk_held_balance_range CHECK (
+    held_balance >= 0
+    AND held_balance <= balance
+);
+
+-- Create balance_holds table
+CREATE TABLE balance_holds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    account_id UUID NOT NULL REFERENCES accounts (connect_id),
+    transaction_id UUID REFERENCES transactions (id),
+    reference VARCHAR(255) NOT NULL,
+    description TEXT,
+    amount BIGINT NOT NULL,
+    captured_amount BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL,
+    captured_at TIMESTAMP WITH TIME ZONE,
+    released_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_balance_hold_amount CHECK (
+        amount > 0
+        AND captured_amount >= 0
+        AND captured_amount <= amount
+    ),
+    CONSTRAINT chk_balance_hold_status CHECK (
+        status IN (
+            'ACTIVE',
+            'CAPTURED',
+            'RELEASED'
+        )
+    )
+);
+
+CREATE INDEX idx_balance_holds_account_created ON balance_holds (account_id, created_at DESC);
+
+CREATE INDEX idx_balance_holds_transaction ON balance_holds (transaction_id);
+
+CREATE INDEX idx_balance_holds_active ON balance_holds (account_id)
+WHERE
+    status = 'ACTIVE';
@@ -0,0 +1,63 @@
+-- Enum values cannot be dropped; only the constraints and trigger are restored
+CREATE OR REPLACE FUNCTION log_transaction_status_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'UPDATE' AND OLD.status IS DISTINCT FROM NEW.status) THEN
+        INSERT INTO transaction_status_history (
+            transaction_id,
+            from_status,
+            to_status,
+            reason,
+            metadata
+        ) VALUES (
+            NEW.id,
+            OLD.status,
+            NEW.status,
+            COALESCE(
+                NEW.payment_status_description,
+                CASE
+                    WHEN NEW.status = 'COMPLETED' THEN 'Transaction completed successfully'
+                    WHEN NEW.status = 'FAILED' THEN 'Transaction failed'
+                    WHEN NEW.status = 'CANCELLED' THEN 'Transaction cancelled'
+                    ELSE 'Status changed'
+                END
+            ),
+            jsonb_build_object(
+                'payment_method', NEW.payment_method,
+                'payment_status', NEW.payment_status,
+                'external_reference_id', NEW.external_reference_id,
+                'journal_entry_id', NEW.journal_entry_id
+            )
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_transactions_related_transaction;
+
+ALTER TABLE transactions
+DROP CONSTRAINT IF EXISTS chk_transaction_status_valid,
+DROP CONSTRAINT IF EXISTS chk_transaction_type_valid;
+
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_status_valid CHECK (
+    status IN (
+        'PENDING',
+        'PROCESSING',
+        'COMPLETED',
+        'FAILED',
+        'CANCELLED'
+    )
+),
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type IN (
+        'TOPUP',
+        'TRANSFER_IN',
+        'TRANSFER_OUT',
+        'PAYMENT',
+        'WITHDRAWAL',
+        'GIFT_IN',
+        'GIFT_OUT'
+    )
+);
@@ -0,0 +1,87 @@
+-- Refund and reversal transaction types and the statuses they leave the original in
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'REFUND';
+
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'REVERSAL';
+
+ALTER TYPE transaction_status ADD VALUE IF NOT EXISTS 'PARTIALLY_REFUNDED';
+
+ALTER TYPE transaction_status ADD VALUE IF NOT EXISTS 'REFUNDED';
+
+ALTER TYPE transaction_status ADD VALUE IF NOT EXISTS 'REVERSED';
+
+-- New enum values cannot be used in the transaction that adds them, so compare as text.
+-- VOUCHER_REDEMPTION was missing from the previous type check.
+ALTER TABLE transactions
+DROP CONSTRAINT IF EXISTS chk_transaction_status_valid,
+DROP CONSTRAINT IF EXISTS chk_transaction_type_valid;
+
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_status_valid CHECK (
+    status::text IN (
+        'PENDING',
+        'PROCESSING',
+        'COMPLETED',
+        'FAILED',
+        'CANCELLED',
+        'PARTIALLY_REFUNDED',
+        'REFUNDED',
+        'REVERSED'
+    )
+),
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type::text IN (
+        'TOPUP',
+        'TRANSFER_IN',
+        'TRANSFER_OUT',
+        'PAYMENT',
+        'WITHDRAWAL',
+        'GIFT_IN',
+        'GIFT_OUT',
+        'VOUCHER_REDEMPTION',
+        'REFUND',
+        'REVERSAL'
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_related_transaction ON transactions (related_transaction_id);
+
+-- Refund and reversal status changes keep the original payment description, so give them their own reason
+CREATE OR REPLACE FUNCTION log_transaction_status_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (TG_OP = 'UPDATE' AND OLD.status IS DISTINCT FROM NEW.status) THEN
+        INSERT INTO transaction_status_history (
+            transaction_id,
+            from_status,
+            to_status,
+            reason,
+            metadata
+        ) VALUES (
+            NEW.id,
+            OLD.status,
+            NEW.status,
+            CASE
+                WHEN NEW.status::text = 'PARTIALLY_REFUNDED' THEN 'Transaction partially refunded'
+                WHEN NEW.status::text = 'REFUNDED' THEN 'Transaction refunded'
+                WHEN NEW.status::text = 'REVERSED' THEN 'Transaction reversed'
+                ELSE COALESCE(
+                    NEW.payment_status_description,
+                    CASE
+                        WHEN NEW.status = 'COMPLETED' THEN 'Transaction completed successfully'
+                        WHEN NEW.status = 'FAILED' THEN 'Transaction failed'
+                        WHEN NEW.status = 'CANCELLED' THEN 'Transaction cancelled'
+                        ELSE 'Status changed'
+                    END
+                )
+            END,
+            jsonb_build_object(
+                'payment_method', NEW.payment_method,
+                'payment_status', NEW.payment_status,
+                'external_reference_id', NEW.external_reference_id,
+                'journal_entry_id', NEW.journal_entry_id
+            )
+        );
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
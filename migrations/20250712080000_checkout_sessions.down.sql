@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_checkout_sessions_open_expiry;
+
+DROP INDEX IF EXISTS idx_checkout_sessions_transaction;
+
+DROP INDEX IF EXISTS idx_checkout_sessions_merchant_created;
+
+DROP INDEX IF EXISTS idx_checkout_sessions_merchant_reference;
+
+DROP TABLE IF EXISTS checkout_sessions;
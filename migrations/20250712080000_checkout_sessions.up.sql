@@ -0,0 +1,53 @@
+-- Create checkout_sessions table
+-- A merchant opens a session with an API key; the payer approves it with their Connect token
+CREATE TABLE checkout_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    merchant_id UUID NOT NULL REFERENCES accounts (connect_id),
+    api_key_id UUID NOT NULL REFERENCES merchant_api_keys (id),
+    reference_id VARCHAR(255),
+    amount_gsalt_units BIGINT NOT NULL,
+    fee_gsalt_units BIGINT NOT NULL DEFAULT 0,
+    net_amount_gsalt_units BIGINT NOT NULL,
+    currency VARCHAR(10) NOT NULL DEFAULT 'GSALT',
+    description TEXT,
+    items JSONB NOT NULL DEFAULT '[]',
+    redirect_url TEXT NOT NULL,
+    signing_secret VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    payer_account_id UUID REFERENCES accounts (connect_id),
+    transaction_id UUID REFERENCES transactions (id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_checkout_session_amount CHECK (
+        amount_gsalt_units > 0
+        AND fee_gsalt_units >= 0
+        AND net_amount_gsalt_units = amount_gsalt_units - fee_gsalt_units
+    ),
+    CONSTRAINT chk_checkout_session_status CHECK (
+        status IN (
+            'OPEN',
+            'COMPLETED',
+            'CANCELLED',
+            'EXPIRED'
+        )
+    ),
+    CONSTRAINT chk_checkout_session_completed CHECK (
+        status <> 'COMPLETED'
+        OR transaction_id IS NOT NULL
+    )
+);
+
+CREATE UNIQUE INDEX idx_checkout_sessions_merchant_reference ON checkout_sessions (merchant_id, reference_id)
+WHERE
+    reference_id IS NOT NULL;
+
+CREATE INDEX idx_checkout_sessions_merchant_created ON checkout_sessions (merchant_id, created_at DESC);
+
+CREATE INDEX idx_checkout_sessions_transaction ON checkout_sessions (transaction_id);
+
+CREATE INDEX idx_checkout_sessions_open_expiry ON checkout_sessions (expires_at)
+WHERE
+    status = 'OPEN';
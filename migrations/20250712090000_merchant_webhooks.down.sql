@@ -0,0 +1,21 @@
+DROP INDEX IF EXISTS idx_webhook_delivery_attempts_delivery;
+
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_endpoint;
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_merchant_created;
+
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP INDEX IF EXISTS idx_webhook_events_merchant_created;
+
+DROP TABLE IF EXISTS webhook_events;
+
+DROP INDEX IF EXISTS idx_webhook_endpoints_deleted_at;
+
+DROP INDEX IF EXISTS idx_webhook_endpoints_merchant;
+
+DROP TABLE IF EXISTS webhook_endpoints;
@@ -0,0 +1,79 @@
+-- Merchant webhook endpoints
+CREATE TABLE webhook_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    merchant_id UUID NOT NULL REFERENCES accounts (connect_id),
+    url TEXT NOT NULL,
+    description VARCHAR(255),
+    secret VARCHAR(100) NOT NULL,
+    event_types JSONB NOT NULL DEFAULT '[]',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_webhook_endpoints_merchant ON webhook_endpoints (merchant_id)
+WHERE
+    deleted_at IS NULL;
+
+CREATE INDEX idx_webhook_endpoints_deleted_at ON webhook_endpoints (deleted_at);
+
+-- Event outbox, written in the same transaction as the change it describes
+CREATE TABLE webhook_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    merchant_id UUID NOT NULL REFERENCES accounts (connect_id),
+    type VARCHAR(50) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_events_merchant_created ON webhook_events (merchant_id, created_at DESC);
+
+-- One delivery per event and endpoint
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    event_id UUID NOT NULL REFERENCES webhook_events (id),
+    endpoint_id UUID NOT NULL REFERENCES webhook_endpoints (id),
+    merchant_id UUID NOT NULL REFERENCES accounts (connect_id),
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_attempt_at TIMESTAMP WITH TIME ZONE,
+    last_status_code INTEGER,
+    last_error TEXT,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_webhook_deliveries_event_endpoint UNIQUE (event_id, endpoint_id),
+    CONSTRAINT chk_webhook_delivery_status CHECK (
+        status IN (
+            'PENDING',
+            'SUCCEEDED',
+            'FAILED'
+        )
+    )
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at)
+WHERE
+    status = 'PENDING';
+
+CREATE INDEX idx_webhook_deliveries_merchant_created ON webhook_deliveries (merchant_id, created_at DESC);
+
+CREATE INDEX idx_webhook_deliveries_endpoint ON webhook_deliveries (endpoint_id);
+
+-- Delivery log: one row per HTTP request
+CREATE TABLE webhook_delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries (id),
+    attempt_number INTEGER NOT NULL,
+    url TEXT NOT NULL,
+    status_code INTEGER,
+    response_body TEXT,
+    error TEXT,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    is_manual BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_webhook_delivery_attempts_delivery ON webhook_delivery_attempts (delivery_id, attempt_number);
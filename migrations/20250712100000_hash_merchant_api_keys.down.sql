@@ -0,0 +1,19 @@
+-- Add down migration script here
+
+-- Hashed keys cannot be turned back into plaintext; revoke them so api_key can be required again
+UPDATE merchant_api_keys
+SET
+    api_key = 'revoked_' || id::text,
+    deleted_at = COALESCE(deleted_at, NOW())
+WHERE
+    api_key IS NULL;
+
+ALTER TABLE merchant_api_keys
+DROP CONSTRAINT IF EXISTS chk_merchant_api_keys_secret;
+
+DROP INDEX IF EXISTS idx_merchant_api_keys_key_id;
+
+ALTER TABLE merchant_api_keys
+ALTER COLUMN api_key SET NOT NULL,
+DROP COLUMN key_hash,
+DROP COLUMN key_id;
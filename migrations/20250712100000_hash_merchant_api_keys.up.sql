@@ -0,0 +1,29 @@
+-- Add up migration script here
+
+-- Merchant API keys are stored as an HMAC-SHA256 keyed with the server pepper (API_KEY_PEPPER)
+-- and looked up by a public key ID: the first 12 characters after the key prefix.
+ALTER TABLE merchant_api_keys
+ADD COLUMN key_id VARCHAR(16),
+ADD COLUMN key_hash VARCHAR(64);
+
+-- Existing keys keep working unchanged; their key ID is derived from the stored value
+UPDATE merchant_api_keys
+SET
+    key_id = SUBSTRING(
+        api_key
+        FROM LENGTH(prefix) + 2 FOR 12
+    );
+
+ALTER TABLE merchant_api_keys
+ALTER COLUMN key_id SET NOT NULL,
+ALTER COLUMN api_key DROP NOT NULL;
+
+CREATE UNIQUE INDEX idx_merchant_api_keys_key_id ON merchant_api_keys (key_id);
+
+-- The plaintext api_key of existing keys is hashed into key_hash and cleared by the
+-- application on startup, since the pepper is not available to the database.
+ALTER TABLE merchant_api_keys
+ADD CONSTRAINT chk_merchant_api_keys_secret CHECK (
+    key_hash IS NOT NULL
+    OR api_key IS NOT NULL
+);
@@ -0,0 +1,6 @@
+ALTER TABLE merchant_api_keys
+DROP CONSTRAINT IF EXISTS chk_merchant_api_keys_require_signature;
+
+ALTER TABLE merchant_api_keys
+DROP COLUMN require_signature,
+DROP COLUMN signing_secret;
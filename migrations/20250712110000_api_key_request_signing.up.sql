@@ -0,0 +1,11 @@
+-- Per-key secret for optional HMAC request signing. Keys created before this migration have
+-- no secret until they are rotated.
+ALTER TABLE merchant_api_keys
+ADD COLUMN signing_secret VARCHAR(100),
+ADD COLUMN require_signature BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE merchant_api_keys
+ADD CONSTRAINT chk_merchant_api_keys_require_signature CHECK (
+    NOT require_signature
+    OR signing_secret IS NOT NULL
+);
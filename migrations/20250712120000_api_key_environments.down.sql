@@ -0,0 +1,34 @@
+-- Test mode sessions only exist on the sandbox ledger; drop them with the environment column
+DELETE FROM checkout_sessions WHERE environment = 'TEST';
+
+DROP INDEX IF EXISTS idx_checkout_sessions_merchant_reference;
+
+CREATE UNIQUE INDEX idx_checkout_sessions_merchant_reference ON checkout_sessions (merchant_id, reference_id)
+WHERE
+    reference_id IS NOT NULL;
+
+ALTER TABLE checkout_sessions
+DROP CONSTRAINT chk_checkout_session_completed;
+
+ALTER TABLE checkout_sessions
+ADD CONSTRAINT chk_checkout_session_completed CHECK (
+    status <> 'COMPLETED'
+    OR transaction_id IS NOT NULL
+);
+
+ALTER TABLE checkout_sessions
+DROP CONSTRAINT IF EXISTS chk_checkout_session_environment,
+DROP COLUMN journal_entry_id,
+DROP COLUMN environment;
+
+-- Revoke test keys so they cannot act as live keys once the environment is gone
+UPDATE merchant_api_keys
+SET
+    deleted_at = COALESCE(deleted_at, NOW())
+WHERE
+    environment = 'TEST';
+
+ALTER TABLE merchant_api_keys
+DROP CONSTRAINT IF EXISTS chk_merchant_api_keys_environment,
+DROP COLUMN allowed_cidrs,
+DROP COLUMN environment;
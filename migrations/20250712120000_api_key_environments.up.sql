@@ -0,0 +1,40 @@
+-- Live and test mode API keys, and per-key IP allowlists
+ALTER TABLE merchant_api_keys
+ADD COLUMN environment VARCHAR(10) NOT NULL DEFAULT 'LIVE',
+ADD COLUMN allowed_cidrs JSONB NOT NULL DEFAULT '[]',
+ADD CONSTRAINT chk_merchant_api_keys_environment CHECK (environment IN ('LIVE', 'TEST'));
+
+-- Checkout sessions inherit the environment of the key that created them. Test mode sessions
+-- are paid on the sandbox ledger and complete without a transaction.
+ALTER TABLE checkout_sessions
+ADD COLUMN environment VARCHAR(10) NOT NULL DEFAULT 'LIVE',
+ADD COLUMN journal_entry_id UUID REFERENCES journal_entries (id),
+ADD CONSTRAINT chk_checkout_session_environment CHECK (environment IN ('LIVE', 'TEST'));
+
+ALTER TABLE checkout_sessions
+DROP CONSTRAINT chk_checkout_session_completed;
+
+ALTER TABLE checkout_sessions
+ADD CONSTRAINT chk_checkout_session_completed CHECK (
+    status <> 'COMPLETED'
+    OR (
+        environment = 'LIVE'
+        AND transaction_id IS NOT NULL
+    )
+    OR (
+        environment = 'TEST'
+        AND transaction_id IS NULL
+        AND journal_entry_id IS NOT NULL
+    )
+);
+
+-- Live and test sessions may reuse the same reference
+DROP INDEX idx_checkout_sessions_merchant_reference;
+
+CREATE UNIQUE INDEX idx_checkout_sessions_merchant_reference ON checkout_sessions (
+    merchant_id,
+    environment,
+    reference_id
+)
+WHERE
+    reference_id IS NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE merchant_api_key_usage
+DROP COLUMN latency_ms;
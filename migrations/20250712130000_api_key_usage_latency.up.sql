@@ -0,0 +1,2 @@
+ALTER TABLE merchant_api_key_usage
+ADD COLUMN latency_ms BIGINT NOT NULL DEFAULT 0;
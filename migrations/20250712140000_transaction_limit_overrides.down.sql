@@ -0,0 +1,14 @@
+DELETE FROM system_configurations
+WHERE
+    category = 'transaction_limits'
+    AND key IN (
+        'account_type_overrides',
+        'kyc_overrides'
+    );
+
+UPDATE system_configurations
+SET
+    value = value - 'monthly_transfer_limit' - 'monthly_payment_limit'
+WHERE
+    category = 'transaction_limits'
+    AND key = 'default_limits';
@@ -0,0 +1,35 @@
+-- Monthly limits are enforced alongside the daily limits
+UPDATE system_configurations
+SET
+    value = jsonb_build_object(
+        'monthly_transfer_limit',
+        100000000,
+        'monthly_payment_limit',
+        50000000
+    ) || value,
+    updated_at = CURRENT_TIMESTAMP
+WHERE
+    category = 'transaction_limits'
+    AND key = 'default_limits';
+
+-- Limit overrides per account type and per KYC status, keyed by the enum value
+INSERT INTO
+    system_configurations (
+        category,
+        key,
+        value,
+        description
+    )
+VALUES (
+        'transaction_limits',
+        'account_type_overrides',
+        '{}'::jsonb,
+        'Transaction limits replacing the defaults per account type'
+    ),
+    (
+        'transaction_limits',
+        'kyc_overrides',
+        '{}'::jsonb,
+        'Transaction limits replacing the defaults and account type limits per KYC status'
+    )
+ON CONFLICT (category, key) DO NOTHING;
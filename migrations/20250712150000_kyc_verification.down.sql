@@ -0,0 +1,20 @@
+DELETE FROM system_configurations
+WHERE
+    category = 'transaction_limits'
+    AND key = 'kyc_level_overrides';
+
+DROP INDEX IF EXISTS idx_kyc_status_history_account_created;
+
+DROP TABLE IF EXISTS kyc_status_history;
+
+DROP INDEX IF EXISTS idx_kyc_submissions_status_created;
+
+DROP INDEX IF EXISTS idx_kyc_submissions_account_created;
+
+DROP INDEX IF EXISTS idx_kyc_submissions_account_pending;
+
+DROP TABLE IF EXISTS kyc_submissions;
+
+ALTER TABLE accounts
+DROP CONSTRAINT IF EXISTS chk_accounts_kyc_level,
+DROP COLUMN IF EXISTS kyc_level;
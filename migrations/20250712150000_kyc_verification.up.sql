@@ -0,0 +1,94 @@
+-- KYC level of an account; accounts verified before levels existed count as fully verified
+ALTER TABLE accounts
+ADD COLUMN kyc_level VARCHAR(10) NOT NULL DEFAULT 'NONE',
+ADD CONSTRAINT chk_accounts_kyc_level CHECK (
+    kyc_level IN ('NONE', 'BASIC', 'FULL')
+);
+
+UPDATE accounts SET kyc_level = 'FULL' WHERE kyc_status = 'VERIFIED';
+
+-- Create kyc_submissions table
+-- Identity data an account submits for review; documents are referenced, not stored
+CREATE TABLE kyc_submissions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    account_id UUID NOT NULL REFERENCES accounts (connect_id),
+    level VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    full_name VARCHAR(255) NOT NULL,
+    date_of_birth DATE NOT NULL,
+    nationality VARCHAR(2) NOT NULL,
+    id_type VARCHAR(20) NOT NULL,
+    id_number VARCHAR(50) NOT NULL,
+    address TEXT,
+    id_document_reference VARCHAR(500) NOT NULL,
+    selfie_reference VARCHAR(500),
+    proof_of_address_reference VARCHAR(500),
+    reviewed_by UUID,
+    review_reason TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_kyc_submission_level CHECK (level IN ('BASIC', 'FULL')),
+    CONSTRAINT chk_kyc_submission_status CHECK (
+        status IN (
+            'PENDING',
+            'APPROVED',
+            'REJECTED'
+        )
+    ),
+    CONSTRAINT chk_kyc_submission_id_type CHECK (
+        id_type IN (
+            'NATIONAL_ID',
+            'PASSPORT',
+            'DRIVER_LICENSE'
+        )
+    ),
+    CONSTRAINT chk_kyc_submission_reviewed CHECK (
+        status = 'PENDING'
+        OR (
+            reviewed_by IS NOT NULL
+            AND reviewed_at IS NOT NULL
+        )
+    )
+);
+
+-- One submission per account can wait for review
+CREATE UNIQUE INDEX idx_kyc_submissions_account_pending ON kyc_submissions (account_id)
+WHERE
+    status = 'PENDING';
+
+CREATE INDEX idx_kyc_submissions_account_created ON kyc_submissions (account_id, created_at DESC);
+
+CREATE INDEX idx_kyc_submissions_status_created ON kyc_submissions (status, created_at);
+
+-- Create kyc_status_history table
+CREATE TABLE kyc_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    account_id UUID NOT NULL REFERENCES accounts (connect_id),
+    submission_id UUID REFERENCES kyc_submissions (id),
+    from_status kyc_status NOT NULL,
+    to_status kyc_status NOT NULL,
+    from_level VARCHAR(10) NOT NULL,
+    to_level VARCHAR(10) NOT NULL,
+    reason TEXT,
+    changed_by UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_kyc_status_history_account_created ON kyc_status_history (account_id, created_at DESC);
+
+-- Limit overrides per KYC level, applied after the KYC status overrides
+INSERT INTO
+    system_configurations (
+        category,
+        key,
+        value,
+        description
+    )
+VALUES (
+        'transaction_limits',
+        'kyc_level_overrides',
+        '{}'::jsonb,
+        'Transaction limits replacing the defaults, account type and KYC status limits per KYC level'
+    )
+ON CONFLICT (category, key) DO NOTHING;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS merchant_profiles;
+
+DROP INDEX IF EXISTS idx_merchant_applications_status_created;
+
+DROP INDEX IF EXISTS idx_merchant_applications_account_created;
+
+DROP INDEX IF EXISTS idx_merchant_applications_account_pending;
+
+DROP TABLE IF EXISTS merchant_applications;
@@ -0,0 +1,101 @@
+-- Create merchant_applications table
+-- A KYC-verified account's request to become a merchant, or a merchant's request to change its
+-- reviewed business details
+CREATE TABLE merchant_applications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid (),
+    account_id UUID NOT NULL REFERENCES accounts (connect_id),
+    business_name VARCHAR(255) NOT NULL,
+    business_type VARCHAR(20) NOT NULL,
+    category VARCHAR(30) NOT NULL,
+    description TEXT,
+    tax_id VARCHAR(50),
+    website_url TEXT,
+    logo_url TEXT,
+    contact_name VARCHAR(255) NOT NULL,
+    contact_email VARCHAR(255) NOT NULL,
+    contact_phone VARCHAR(20) NOT NULL,
+    settlement_bank_code VARCHAR(10) NOT NULL,
+    settlement_account_number VARCHAR(50) NOT NULL,
+    settlement_account_holder VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reviewed_by UUID,
+    review_reason TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_merchant_application_business_type CHECK (
+        business_type IN ('INDIVIDUAL', 'COMPANY')
+    ),
+    CONSTRAINT chk_merchant_application_category CHECK (
+        category IN (
+            'RETAIL',
+            'FOOD_BEVERAGE',
+            'DIGITAL_GOODS',
+            'SERVICES',
+            'EDUCATION',
+            'ENTERTAINMENT',
+            'TRAVEL',
+            'OTHER'
+        )
+    ),
+    CONSTRAINT chk_merchant_application_status CHECK (
+        status IN (
+            'PENDING',
+            'APPROVED',
+            'REJECTED'
+        )
+    ),
+    CONSTRAINT chk_merchant_application_reviewed CHECK (
+        status = 'PENDING'
+        OR (
+            reviewed_by IS NOT NULL
+            AND reviewed_at IS NOT NULL
+        )
+    )
+);
+
+-- One application per account can wait for review
+CREATE UNIQUE INDEX idx_merchant_applications_account_pending ON merchant_applications (account_id)
+WHERE
+    status = 'PENDING';
+
+CREATE INDEX idx_merchant_applications_account_created ON merchant_applications (account_id, created_at DESC);
+
+CREATE INDEX idx_merchant_applications_status_created ON merchant_applications (status, created_at);
+
+-- Create merchant_profiles table
+-- Business profile of a merchant account, copied from its last approved application
+CREATE TABLE merchant_profiles (
+    account_id UUID PRIMARY KEY REFERENCES accounts (connect_id),
+    application_id UUID NOT NULL REFERENCES merchant_applications (id),
+    business_name VARCHAR(255) NOT NULL,
+    business_type VARCHAR(20) NOT NULL,
+    category VARCHAR(30) NOT NULL,
+    description TEXT,
+    tax_id VARCHAR(50),
+    website_url TEXT,
+    logo_url TEXT,
+    contact_name VARCHAR(255) NOT NULL,
+    contact_email VARCHAR(255) NOT NULL,
+    contact_phone VARCHAR(20) NOT NULL,
+    settlement_bank_code VARCHAR(10) NOT NULL,
+    settlement_account_number VARCHAR(50) NOT NULL,
+    settlement_account_holder VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_merchant_profile_business_type CHECK (
+        business_type IN ('INDIVIDUAL', 'COMPANY')
+    ),
+    CONSTRAINT chk_merchant_profile_category CHECK (
+        category IN (
+            'RETAIL',
+            'FOOD_BEVERAGE',
+            'DIGITAL_GOODS',
+            'SERVICES',
+            'EDUCATION',
+            'ENTERTAINMENT',
+            'TRAVEL',
+            'OTHER'
+        )
+    )
+);
@@ -0,0 +1,35 @@
+-- Enum values cannot be dropped; only the constraint is restored
+DROP INDEX IF EXISTS idx_audit_logs_record_changed_at;
+
+ALTER TABLE accounts
+DROP COLUMN IF EXISTS status_changed_at,
+DROP COLUMN IF EXISTS status_reason;
+
+DELETE FROM ledger_accounts
+WHERE
+    code = 'MANUAL_ADJUSTMENT'
+    AND NOT EXISTS (
+        SELECT 1
+        FROM postings
+        WHERE
+            postings.ledger_account_id = ledger_accounts.id
+    );
+
+ALTER TABLE transactions
+DROP CONSTRAINT IF EXISTS chk_transaction_type_valid;
+
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type::text IN (
+        'TOPUP',
+        'TRANSFER_IN',
+        'TRANSFER_OUT',
+        'PAYMENT',
+        'WITHDRAWAL',
+        'GIFT_IN',
+        'GIFT_OUT',
+        'VOUCHER_REDEMPTION',
+        'REFUND',
+        'REVERSAL'
+    )
+);
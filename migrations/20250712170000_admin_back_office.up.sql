@@ -0,0 +1,42 @@
+-- Manual balance adjustments made from the back office
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'ADJUSTMENT';
+
+-- New enum values cannot be used in the transaction that adds them, so compare as text
+ALTER TABLE transactions
+DROP CONSTRAINT IF EXISTS chk_transaction_type_valid;
+
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type::text IN (
+        'TOPUP',
+        'TRANSFER_IN',
+        'TRANSFER_OUT',
+        'PAYMENT',
+        'WITHDRAWAL',
+        'GIFT_IN',
+        'GIFT_OUT',
+        'VOUCHER_REDEMPTION',
+        'REFUND',
+        'REVERSAL',
+        'ADJUSTMENT'
+    )
+);
+
+-- Counterpart of adjustments; credits to wallets drive it negative
+INSERT INTO
+    ledger_accounts (code, name, type, allow_negative)
+VALUES (
+        'MANUAL_ADJUSTMENT',
+        'Manual Adjustments',
+        'EQUITY',
+        TRUE
+    )
+ON CONFLICT (code) DO NOTHING;
+
+-- Why and when an admin last changed the account status
+ALTER TABLE accounts
+ADD COLUMN status_reason TEXT,
+ADD COLUMN status_changed_at TIMESTAMP WITH TIME ZONE;
+
+-- Audit trail of one account across the tables keyed by its ID
+CREATE INDEX idx_audit_logs_record_changed_at ON audit_logs (record_id, changed_at DESC);
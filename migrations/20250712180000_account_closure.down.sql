@@ -0,0 +1,45 @@
+-- Enum values cannot be dropped; only the constraints are restored
+DROP INDEX IF EXISTS idx_accounts_closure_scheduled_at;
+
+ALTER TABLE accounts
+DROP COLUMN IF EXISTS closed_at,
+DROP COLUMN IF EXISTS closure_scheduled_at,
+DROP COLUMN IF EXISTS closure_requested_at;
+
+DELETE FROM ledger_accounts
+WHERE
+    code = 'DONATIONS_PAYABLE'
+    AND NOT EXISTS (
+        SELECT 1
+        FROM postings
+        WHERE
+            postings.ledger_account_id = ledger_accounts.id
+    );
+
+ALTER TABLE transactions
+DROP CONSTRAINT IF EXISTS chk_transaction_type_valid;
+
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type::text IN (
+        'TOPUP',
+        'TRANSFER_IN',
+        'TRANSFER_OUT',
+        'PAYMENT',
+        'WITHDRAWAL',
+        'GIFT_IN',
+        'GIFT_OUT',
+        'VOUCHER_REDEMPTION',
+        'REFUND',
+        'REVERSAL',
+        'ADJUSTMENT'
+    )
+);
+
+ALTER TABLE accounts
+DROP CONSTRAINT IF EXISTS chk_account_status_valid;
+
+ALTER TABLE accounts
+ADD CONSTRAINT chk_account_status_valid CHECK (
+    status::text IN ('ACTIVE', 'SUSPENDED', 'BLOCKED')
+);
@@ -0,0 +1,61 @@
+-- Accounts in their closure cooling-off period, and closed accounts
+ALTER TYPE account_status ADD VALUE IF NOT EXISTS 'CLOSING';
+ALTER TYPE account_status ADD VALUE IF NOT EXISTS 'CLOSED';
+
+-- New enum values cannot be used in the transaction that adds them, so compare as text
+ALTER TABLE accounts
+DROP CONSTRAINT IF EXISTS chk_account_status_valid;
+
+ALTER TABLE accounts
+ADD CONSTRAINT chk_account_status_valid CHECK (
+    status::text IN (
+        'ACTIVE',
+        'SUSPENDED',
+        'BLOCKED',
+        'CLOSING',
+        'CLOSED'
+    )
+);
+
+-- Remaining balances donated on account closure
+ALTER TYPE transaction_type ADD VALUE IF NOT EXISTS 'DONATION';
+
+ALTER TABLE transactions
+DROP CONSTRAINT IF EXISTS chk_transaction_type_valid;
+
+ALTER TABLE transactions
+ADD CONSTRAINT chk_transaction_type_valid CHECK (
+    type::text IN (
+        'TOPUP',
+        'TRANSFER_IN',
+        'TRANSFER_OUT',
+        'PAYMENT',
+        'WITHDRAWAL',
+        'GIFT_IN',
+        'GIFT_OUT',
+        'VOUCHER_REDEMPTION',
+        'REFUND',
+        'REVERSAL',
+        'ADJUSTMENT',
+        'DONATION'
+    )
+);
+
+-- Donated balances owed to the platform's donation programme
+INSERT INTO
+    ledger_accounts (code, name, type, allow_negative)
+VALUES (
+        'DONATIONS_PAYABLE',
+        'Donations Payable',
+        'LIABILITY',
+        FALSE
+    )
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE accounts
+ADD COLUMN closure_requested_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN closure_scheduled_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN closed_at TIMESTAMP WITH TIME ZONE;
+
+-- Due closures picked up by the finalize_account_closures job
+CREATE INDEX idx_accounts_closure_scheduled_at ON accounts (status, closure_scheduled_at);
@@ -0,0 +1,25 @@
+DROP INDEX IF EXISTS idx_checkout_sessions_authorization_expiry;
+
+ALTER TABLE checkout_sessions
+DROP CONSTRAINT IF EXISTS chk_checkout_session_authorized;
+
+ALTER TABLE checkout_sessions
+DROP CONSTRAINT IF EXISTS chk_checkout_session_status;
+
+ALTER TABLE checkout_sessions
+ADD CONSTRAINT chk_checkout_session_status CHECK (
+    status IN (
+        'OPEN',
+        'COMPLETED',
+        'CANCELLED',
+        'EXPIRED'
+    )
+);
+
+ALTER TABLE checkout_sessions
+DROP CONSTRAINT IF EXISTS chk_checkout_session_capture_method,
+DROP COLUMN IF EXISTS authorization_expires_at,
+DROP COLUMN IF EXISTS authorized_at,
+DROP COLUMN IF EXISTS authorized_amount_gsalt_units,
+DROP COLUMN IF EXISTS hold_id,
+DROP COLUMN IF EXISTS capture_method;
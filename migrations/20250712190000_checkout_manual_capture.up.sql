@@ -0,0 +1,37 @@
+-- Manual capture checkout sessions: approving holds the payer's balance and the merchant
+-- captures all or part of it later, or voids it
+ALTER TABLE checkout_sessions
+ADD COLUMN capture_method VARCHAR(20) NOT NULL DEFAULT 'AUTOMATIC',
+ADD COLUMN hold_id UUID REFERENCES balance_holds (id),
+ADD COLUMN authorized_amount_gsalt_units BIGINT,
+ADD COLUMN authorized_at TIMESTAMP WITH TIME ZONE,
+ADD COLUMN authorization_expires_at TIMESTAMP WITH TIME ZONE,
+ADD CONSTRAINT chk_checkout_session_capture_method CHECK (capture_method IN ('AUTOMATIC', 'MANUAL'));
+
+ALTER TABLE checkout_sessions
+DROP CONSTRAINT chk_checkout_session_status;
+
+ALTER TABLE checkout_sessions
+ADD CONSTRAINT chk_checkout_session_status CHECK (
+    status IN (
+        'OPEN',
+        'AUTHORIZED',
+        'COMPLETED',
+        'CANCELLED',
+        'EXPIRED'
+    )
+);
+
+ALTER TABLE checkout_sessions
+ADD CONSTRAINT chk_checkout_session_authorized CHECK (
+    status <> 'AUTHORIZED'
+    OR (
+        capture_method = 'MANUAL'
+        AND authorized_amount_gsalt_units IS NOT NULL
+        AND authorization_expires_at IS NOT NULL
+    )
+);
+
+CREATE INDEX idx_checkout_sessions_authorization_expiry ON checkout_sessions (authorization_expires_at)
+WHERE
+    status = 'AUTHORIZED';
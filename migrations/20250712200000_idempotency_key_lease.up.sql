@@ -0,0 +1,9 @@
+-- PROCESSING keys are leased; a retry may claim a key whose lease ran out
+ALTER TABLE idempotency_keys
+ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+UPDATE idempotency_keys
+SET
+    locked_until = created_at + INTERVAL '2 minutes'
+WHERE
+    status = 'PROCESSING';